@@ -3,7 +3,6 @@ package cmd
 import (
 	"errors"
 	"fmt"
-	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -22,8 +21,11 @@ import (
 	"github.com/redhat-developer/kam/pkg/pipelines"
 	"github.com/redhat-developer/kam/pkg/pipelines/accesstoken"
 	"github.com/redhat-developer/kam/pkg/pipelines/argocd"
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/giturl"
 	"github.com/redhat-developer/kam/pkg/pipelines/imagerepo"
 	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/pkg/pipelines/scm"
 )
 
 const (
@@ -45,6 +47,9 @@ var (
 	supportedDrivers = drivers{
 		"github",
 		"gitlab",
+		"bitbucket",
+		"stash",
+		"gitea",
 	}
 )
 
@@ -96,7 +101,7 @@ func (io *BootstrapParameters) Complete(name string, cmd *cobra.Command, args []
 		if err != nil {
 			return err
 		}
-		identifier := factory.NewDriverIdentifier(factory.Mapping(host, io.PrivateRepoDriver))
+		identifier := scm.NewDriverIdentifier(factory.Mapping(host, io.PrivateRepoDriver))
 		factory.DefaultIdentifier = identifier
 	}
 	if err := checkBootstrapDependencies(io, client, log.NewStatus(os.Stdout)); err != nil {
@@ -162,7 +167,7 @@ func initiateInteractiveMode(io *BootstrapParameters, client *utility.Client, cm
 		if err != nil {
 			return fmt.Errorf("failed to parse the gitops url: %w", err)
 		}
-		identifier := factory.NewDriverIdentifier(factory.Mapping(host, io.PrivateRepoDriver))
+		identifier := scm.NewDriverIdentifier(factory.Mapping(host, io.PrivateRepoDriver))
 		factory.DefaultIdentifier = identifier
 	}
 	if io.ImageRepo != "" {
@@ -238,12 +243,11 @@ func initiateInteractiveMode(io *BootstrapParameters, client *utility.Client, cm
 }
 
 func repoFromURL(raw string) (string, error) {
-	u, err := url.Parse(raw)
+	u, err := giturl.Parse(raw)
 	if err != nil {
 		return "", err
 	}
-	parts := strings.Split(u.Path, "/")
-	return strings.TrimSuffix(parts[len(parts)-1], ".git"), nil
+	return u.RepoName(), nil
 }
 
 func setAccessToken(io *BootstrapParameters) error {
@@ -306,13 +310,23 @@ func warnIfNotFound(spinner utility.Status, warningMsg string, err error) {
 
 // Validate validates the parameters of the BootstrapParameters.
 func (io *BootstrapParameters) Validate() error {
-	gr, err := url.Parse(io.GitOpsRepoURL)
+	gr, err := giturl.Parse(io.GitOpsRepoURL)
 	if err != nil {
 		return fmt.Errorf("failed to parse url %s: %w", io.GitOpsRepoURL, err)
 	}
 
-	// TODO: this may not work with GitLab as the repo can have more path elements.
-	if len(utility.RemoveEmptyStrings(strings.Split(gr.Path, "/"))) != 2 {
+	components := utility.RemoveEmptyStrings(strings.Split(gr.Path, "/"))
+	driver := io.PrivateRepoDriver
+	if driver == "" {
+		// Unknown hosts are rejected when the repository is created.
+		driver, _ = scm.GetDriverName(io.GitOpsRepoURL)
+	}
+	switch {
+	// GitLab projects can be in nested groups, e.g. group/subgroup/repo.
+	case driver == "gitlab" && len(components) >= 2:
+	// Bitbucket Server repositories are of the form scm/<project>/<repo>.
+	case driver == "stash" && len(components) == 3 && components[0] == "scm":
+	case len(components) != 2:
 		return fmt.Errorf("repo must be org/repo: %s", strings.Trim(gr.Path, ".git"))
 	}
 
@@ -321,6 +335,9 @@ func (io *BootstrapParameters) Validate() error {
 			return fmt.Errorf("invalid driver type: %q", io.PrivateRepoDriver)
 		}
 	}
+	if v := io.TektonAPIVersion; v != "" && v != config.TektonV1Beta1 && v != config.TektonV1 {
+		return fmt.Errorf("invalid Tekton API version: %q, must be one of %s or %s", v, config.TektonV1Beta1, config.TektonV1)
+	}
 	if io.SaveTokenKeyRing && io.GitHostAccessToken == "" {
 		return errors.New("--git-host-access-token is required if --save-token-keyring is enabled")
 	}
@@ -371,8 +388,10 @@ func NewCmdBootstrap(name, fullName string) *cobra.Command {
 	bootstrapCmd.Flags().StringVar(&o.ServiceRepoURL, "service-repo-url", "", "Provide the URL for your Service repository e.g. https://github.com/organisation/service.git")
 	bootstrapCmd.Flags().StringVar(&o.ServiceWebhookSecret, "service-webhook-secret", "", "Provide a secret that we can use to authenticate incoming hooks from your Git hosting service for the Service repository. (if not provided, it will be auto-generated)")
 	bootstrapCmd.Flags().BoolVar(&o.SaveTokenKeyRing, "save-token-keyring", false, "Explicitly pass this flag to update the git-host-access-token in the keyring on your local machine")
-	bootstrapCmd.Flags().StringVar(&o.PrivateRepoDriver, "private-repo-driver", "", "If your Git repositories are on a custom domain, please indicate which driver to use github or gitlab")
+	bootstrapCmd.Flags().StringVar(&o.PrivateRepoDriver, "private-repo-driver", "", "If your Git repositories are on a custom domain, please indicate which driver to use github, gitlab, bitbucket, stash (Bitbucket Server) or gitea")
 	bootstrapCmd.Flags().BoolVar(&o.PushToGit, "push-to-git", false, "If true, automatically creates and populates the gitops-repo-url with the generated resources")
+	bootstrapCmd.Flags().BoolVar(&o.ImageUpdatePullRequest, "image-update-pull-request", false, "If true, the GitOps repository is updated with newly built images through pull requests, rather than by pushing directly")
+	bootstrapCmd.Flags().StringVar(&o.TektonAPIVersion, "tekton-api-version", "", "The version of the Tekton APIs to generate resources for, v1beta1 generates tekton.dev/v1beta1 and triggers.tekton.dev/v1alpha1 resources, v1 generates tekton.dev/v1 and triggers.tekton.dev/v1beta1 resources (default v1beta1)")
 	bootstrapCmd.Flags().BoolVar(&o.Interactive, "interactive", false, "If true, enable prompting for most options if not already specified on the command line")
 	return bootstrapCmd
 }
@@ -99,15 +99,29 @@ func TestAddSuffixWithBootstrap(t *testing.T) {
 
 func TestValidateBootstrapParameter(t *testing.T) {
 	optionTests := []struct {
-		name    string
-		gitRepo string
-		driver  string
-		errMsg  string
+		name          string
+		gitRepo       string
+		driver        string
+		tektonVersion string
+		errMsg        string
 	}{
-		{"invalid repo", "test", "", "repo must be org/repo"},
-		{"valid repo", "test/repo", "", ""},
-		{"invalid driver", "test/repo", "unknown", "invalid"},
-		{"valid driver gitlab", "test/repo", "gitlab", ""},
+		{"invalid repo", "test", "", "", "repo must be org/repo"},
+		{"valid repo", "test/repo", "", "", ""},
+		{"invalid driver", "test/repo", "unknown", "", "invalid"},
+		{"valid driver gitlab", "test/repo", "gitlab", "", ""},
+		{"valid gitlab subgroup", "https://gitlab.com/group/subgroup/team/repo", "", "", ""},
+		{"valid gitlab subgroup with driver", "https://gitlab.example.com/group/subgroup/repo", "gitlab", "", ""},
+		{"invalid github repo", "https://github.com/org/team/repo", "", "", "repo must be org/repo"},
+		{"valid driver stash", "https://bitbucket.example.com/scm/proj/repo", "stash", "", ""},
+		{"invalid repo for driver stash", "https://bitbucket.example.com/proj/repo/test", "stash", "", "repo must be org/repo"},
+		{"valid driver gitea", "test/repo", "gitea", "", ""},
+		{"valid scp-like SSH repo", "git@github.com:org/repo.git", "", "", ""},
+		{"valid SSH repo", "ssh://git@github.com/org/repo.git", "", "", ""},
+		{"valid scp-like SSH gitlab subgroup", "git@gitlab.com:group/subgroup/repo.git", "", "", ""},
+		{"invalid scp-like SSH github repo", "git@github.com:org/team/repo.git", "", "", "repo must be org/repo"},
+		{"valid SSH repo for driver stash", "ssh://git@bitbucket.example.com:7999/proj/repo.git", "stash", "", ""},
+		{"valid Tekton API version", "test/repo", "", "v1", ""},
+		{"invalid Tekton API version", "test/repo", "", "v1alpha1", "invalid Tekton API version"},
 	}
 
 	for _, tt := range optionTests {
@@ -115,6 +129,7 @@ func TestValidateBootstrapParameter(t *testing.T) {
 			BootstrapOptions: &pipelines.BootstrapOptions{
 				GitOpsRepoURL:     tt.gitRepo,
 				PrivateRepoDriver: tt.driver,
+				TektonAPIVersion:  tt.tektonVersion,
 				Prefix:            "test",
 			},
 		}
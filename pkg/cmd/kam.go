@@ -4,7 +4,12 @@ import (
 	"log"
 
 	"github.com/redhat-developer/kam/pkg/cmd/environment"
+	"github.com/redhat-developer/kam/pkg/cmd/pipeline"
 	"github.com/redhat-developer/kam/pkg/cmd/service"
+	"github.com/redhat-developer/kam/pkg/cmd/status"
+	"github.com/redhat-developer/kam/pkg/cmd/sync"
+	"github.com/redhat-developer/kam/pkg/cmd/trigger"
+	"github.com/redhat-developer/kam/pkg/cmd/triggertemplate"
 	"github.com/redhat-developer/kam/pkg/cmd/utility"
 	"github.com/redhat-developer/kam/pkg/cmd/version"
 	"github.com/redhat-developer/kam/pkg/cmd/webhook"
@@ -30,6 +35,11 @@ func MakeRootCmd() *cobra.Command {
 		NewCmdBootstrap(BootstrapRecommendedCommandName, utility.GetFullName(fullName, BootstrapRecommendedCommandName)),
 		environment.NewCmdEnv(environment.EnvRecommendedCommandName, utility.GetFullName(fullName, environment.EnvRecommendedCommandName)),
 		service.NewCmd(service.RecommendedCommandName, utility.GetFullName(fullName, service.RecommendedCommandName)),
+		pipeline.NewCmd(pipeline.RecommendedCommandName, utility.GetFullName(fullName, pipeline.RecommendedCommandName)),
+		trigger.NewCmd(trigger.RecommendedCommandName, utility.GetFullName(fullName, trigger.RecommendedCommandName)),
+		triggertemplate.NewCmd(triggertemplate.RecommendedCommandName, utility.GetFullName(fullName, triggertemplate.RecommendedCommandName)),
+		status.NewCmd(status.RecommendedCommandName, utility.GetFullName(fullName, status.RecommendedCommandName)),
+		sync.NewCmd(sync.RecommendedCommandName, utility.GetFullName(fullName, sync.RecommendedCommandName)),
 		version.NewCmd(version.RecommendedCommandName, utility.GetFullName(fullName, version.RecommendedCommandName)),
 		webhook.NewCmdWebhook(webhook.RecommendedCommandName, utility.GetFullName(fullName, webhook.RecommendedCommandName)),
 		NewCmdBuild(BuildRecommendedCommandName, utility.GetFullName(fullName, BuildRecommendedCommandName)),
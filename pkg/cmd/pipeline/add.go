@@ -0,0 +1,77 @@
+package pipeline
+
+import (
+	"fmt"
+
+	"github.com/openshift/odo/pkg/log"
+	"github.com/spf13/cobra"
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
+	"github.com/redhat-developer/kam/pkg/pipelines"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+)
+
+const (
+	addRecommendedCommandName = "add"
+)
+
+var (
+	addExample = ktemplates.Examples(`
+	# Add the Pipelines and Tasks in a directory to the CI/CD environment in GitOps
+	# Example: kam pipeline add --from-dir ./tekton --pipelines-folder <path to GitOps folder>
+
+	%[1]s`)
+
+	addLongDesc = ktemplates.LongDesc(`Add custom Pipelines and Tasks to the CI/CD environment in GitOps.
+
+	The Pipelines and Tasks in the YAML files in the directory are validated, and written to the CI/CD environment,
+	the Tasks referenced by the Pipelines must be in the directory, or already exist in the CI/CD environment.
+	Pipelines and Tasks with the names of the resources that kam generates are rejected.`)
+	addShortDesc = `Add custom Pipelines and Tasks`
+)
+
+// AddOptions encapsulates the parameters for the pipeline add command.
+type AddOptions struct {
+	*pipelines.AddPipelineOptions
+}
+
+// Complete is called when the command is completed
+func (o *AddOptions) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the parameters of the AddOptions.
+func (o *AddOptions) Validate() error {
+	return nil
+}
+
+// Run runs the pipeline add command.
+func (o *AddOptions) Run() error {
+	if err := pipelines.AddPipelines(o.AddPipelineOptions, ioutils.NewFilesystem()); err != nil {
+		return err
+	}
+	log.Successf("Added the Pipelines and Tasks from %s successfully.", o.FromDir)
+	return nil
+}
+
+func newCmdAdd(name, fullName string) *cobra.Command {
+	o := &AddOptions{AddPipelineOptions: &pipelines.AddPipelineOptions{}}
+
+	cmd := &cobra.Command{
+		Use:     name,
+		Short:   addShortDesc,
+		Long:    addLongDesc,
+		Example: fmt.Sprintf(addExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	cmd.Flags().StringVar(&o.FromDir, "from-dir", "", "Directory with the YAML files for the Pipelines and Tasks to add")
+	cmd.Flags().StringVar(&o.PipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+
+	// required flags
+	_ = cmd.MarkFlagRequired("from-dir")
+	return cmd
+}
@@ -0,0 +1,52 @@
+package pipeline
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+type keyValuePair struct {
+	key   string
+	value string
+}
+
+func TestAddCommandWithMissingParams(t *testing.T) {
+	cmdTests := []struct {
+		desc    string
+		flags   []keyValuePair
+		wantErr string
+	}{
+		{"Missing from-dir flag",
+			[]keyValuePair{flag("pipelines-folder", "/tmp/gitops")},
+			`required flag(s) "from-dir" not set`},
+	}
+	for _, tt := range cmdTests {
+		t.Run(tt.desc, func(t *testing.T) {
+			_, _, err := executeCommand(newCmdAdd("add", "kam pipeline"), tt.flags...)
+			if err.Error() != tt.wantErr {
+				t.Errorf("got %s, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func executeCommand(cmd *cobra.Command, flags ...keyValuePair) (c *cobra.Command, output string, err error) {
+	buf := new(bytes.Buffer)
+	cmd.SetOutput(buf)
+	for _, flag := range flags {
+		if err := cmd.Flags().Set(flag.key, flag.value); err != nil {
+			return nil, "", err
+		}
+	}
+	c, err = cmd.ExecuteC()
+	return c, buf.String(), err
+}
+
+func flag(k, v string) keyValuePair {
+	return keyValuePair{
+		key:   k,
+		value: v,
+	}
+}
@@ -0,0 +1,130 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/pkg/pipelines/pipelineruns"
+)
+
+const (
+	listRecommendedCommandName = "list"
+	jsonOutput                 = "json"
+)
+
+var (
+	listExample = ktemplates.Examples(`
+	# List the recent PipelineRuns of a service
+	# Example: kam pipeline list --service-name taxi
+
+	%[1]s`)
+
+	listLongDesc = ktemplates.LongDesc(`List the recent PipelineRuns in the CI/CD environment.
+
+	The commit, author, status and duration of each PipelineRun are shown, newest first.
+	If a service is provided, only the PipelineRuns started by the service's push and pull request triggers are listed.`)
+	listShortDesc = `List the recent PipelineRuns`
+)
+
+// ListOptions encapsulates the parameters for the pipeline list command.
+type ListOptions struct {
+	serviceName         string
+	pipelinesFolderPath string
+	limit               int
+	output              string
+	out                 io.Writer
+}
+
+// Complete is called when the command is completed
+func (o *ListOptions) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the parameters of the ListOptions.
+func (o *ListOptions) Validate() error {
+	if o.output != "" && o.output != jsonOutput {
+		return fmt.Errorf("invalid output format %q, only %q is supported", o.output, jsonOutput)
+	}
+	return nil
+}
+
+// Run runs the pipeline list command.
+func (o *ListOptions) Run() error {
+	cfg, err := loadPipelinesConfig(o.pipelinesFolderPath)
+	if err != nil {
+		return err
+	}
+	_, client, err := pipelineruns.NewClients()
+	if err != nil {
+		return err
+	}
+	runs, err := pipelineruns.List(client, cfg.GetTektonAPIVersion(), cfg.Name, o.serviceName, o.limit)
+	if err != nil {
+		return err
+	}
+	if o.output == jsonOutput {
+		b, err := json.MarshalIndent(runs, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal PipelineRuns: %w", err)
+		}
+		_, err = fmt.Fprintf(o.out, "%s\n", b)
+		return err
+	}
+	return printRuns(o.out, runs, time.Now())
+}
+
+func printRuns(out io.Writer, runs []*pipelineruns.Summary, now time.Time) error {
+	w := tabwriter.NewWriter(out, 5, 2, 3, ' ', 0)
+	fmt.Fprintln(w, "NAME\tREF\tCOMMIT\tAUTHOR\tSTATUS\tSTARTED\tDURATION")
+	for _, run := range runs {
+		started := "---"
+		if run.Started != nil {
+			started = now.Sub(run.Started.Time).Round(time.Second).String() + " ago"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", run.Name, run.Ref, run.Commit, run.Author, run.Status, started, run.Duration)
+	}
+	return w.Flush()
+}
+
+func loadPipelinesConfig(path string) (*config.PipelinesConfig, error) {
+	m, err := config.LoadManifest(ioutils.NewFilesystem(), path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := m.GetPipelinesConfig()
+	if cfg == nil {
+		return nil, errors.New("failed to find the pipelines configuration in the manifest")
+	}
+	return cfg, nil
+}
+
+func newCmdList(name, fullName string) *cobra.Command {
+	o := &ListOptions{out: os.Stdout}
+
+	cmd := &cobra.Command{
+		Use:     name,
+		Short:   listShortDesc,
+		Long:    listLongDesc,
+		Example: fmt.Sprintf(listExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	cmd.Flags().StringVar(&o.serviceName, "service-name", "", "Name of the service whose PipelineRuns are listed")
+	cmd.Flags().IntVar(&o.limit, "limit", 10, "Maximum number of PipelineRuns to list, 0 lists all of them")
+	cmd.Flags().StringVarP(&o.output, "output", "o", "", "Output format, the only supported format is json")
+	cmd.Flags().StringVar(&o.pipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+	return cmd
+}
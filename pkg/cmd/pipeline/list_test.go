@@ -0,0 +1,56 @@
+package pipeline
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/pipelineruns"
+)
+
+func TestListValidate(t *testing.T) {
+	validateTests := []struct {
+		desc    string
+		options *ListOptions
+		wantErr string
+	}{
+		{"default output", &ListOptions{}, ""},
+		{"json output", &ListOptions{output: "json"}, ""},
+		{"invalid output", &ListOptions{output: "yaml"}, `invalid output format "yaml", only "json" is supported`},
+	}
+
+	for _, tt := range validateTests {
+		t.Run(tt.desc, func(rt *testing.T) {
+			err := tt.options.Validate()
+			if tt.wantErr == "" && err != nil {
+				rt.Fatalf("Validate() got an unexpected error: %s", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				rt.Fatalf("Validate() got %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPrintRuns(t *testing.T) {
+	now := time.Date(2021, time.January, 1, 10, 0, 0, 0, time.UTC)
+	started := metav1.NewTime(now.Add(-5 * time.Minute))
+	runs := []*pipelineruns.Summary{
+		{Name: "app-ci-abcde", Ref: "main", Commit: "abc1234", Author: "Test User", Status: "Succeeded", Started: &started, Duration: 90 * time.Second},
+		{Name: "app-ci-fghij", Ref: "main", Commit: "def5678", Author: "Test User", Status: "Pending"},
+	}
+	var buf bytes.Buffer
+	if err := printRuns(&buf, runs, now); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "NAME           REF    COMMIT    AUTHOR      STATUS      STARTED    DURATION\n" +
+		"app-ci-abcde   main   abc1234   Test User   Succeeded   5m0s ago   1m30s\n" +
+		"app-ci-fghij   main   def5678   Test User   Pending     ---        0s\n"
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Fatalf("printRuns() failed:\n%s", diff)
+	}
+}
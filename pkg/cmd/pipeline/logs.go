@@ -0,0 +1,88 @@
+package pipeline
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
+	"github.com/redhat-developer/kam/pkg/pipelines/pipelineruns"
+)
+
+const (
+	logsRecommendedCommandName = "logs"
+)
+
+var (
+	logsExample = ktemplates.Examples(`
+	# Show the logs of a PipelineRun
+	# Example: kam pipeline logs app-ci-abcde
+
+	%[1]s <PipelineRun name>
+
+	# Stream the logs of a running PipelineRun until it completes
+	# Example: kam pipeline logs app-ci-abcde --follow
+
+	%[1]s <PipelineRun name> --follow`)
+
+	logsLongDesc = ktemplates.LongDesc(`Show the logs of a PipelineRun in the CI/CD environment.
+
+	The logs of the steps of each task are shown in the order that the tasks started,
+	with each line prefixed by the names of the task and the step.
+	With --follow, the logs are streamed until the PipelineRun completes.`)
+	logsShortDesc = `Show the logs of a PipelineRun`
+)
+
+// LogsOptions encapsulates the parameters for the pipeline logs command.
+type LogsOptions struct {
+	name                string
+	pipelinesFolderPath string
+	follow              bool
+	out                 io.Writer
+}
+
+// Complete is called when the command is completed
+func (o *LogsOptions) Complete(name string, cmd *cobra.Command, args []string) error {
+	o.name = args[0]
+	return nil
+}
+
+// Validate validates the parameters of the LogsOptions.
+func (o *LogsOptions) Validate() error {
+	return nil
+}
+
+// Run runs the pipeline logs command.
+func (o *LogsOptions) Run() error {
+	cfg, err := loadPipelinesConfig(o.pipelinesFolderPath)
+	if err != nil {
+		return err
+	}
+	kubeClient, client, err := pipelineruns.NewClients()
+	if err != nil {
+		return err
+	}
+	return pipelineruns.Logs(kubeClient, client, cfg.GetTektonAPIVersion(), cfg.Name, o.name, o.follow, o.out)
+}
+
+func newCmdLogs(name, fullName string) *cobra.Command {
+	o := &LogsOptions{out: os.Stdout}
+
+	cmd := &cobra.Command{
+		Use:     name + " <PipelineRun name>",
+		Short:   logsShortDesc,
+		Long:    logsLongDesc,
+		Example: fmt.Sprintf(logsExample, fullName),
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.follow, "follow", false, "Stream the logs, and wait for the tasks that start later, until the PipelineRun completes")
+	cmd.Flags().StringVar(&o.pipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+	return cmd
+}
@@ -0,0 +1,13 @@
+package pipeline
+
+import (
+	"testing"
+)
+
+func TestLogsCommandWithMissingArgs(t *testing.T) {
+	_, _, err := executeCommand(newCmdLogs("logs", "kam pipeline"))
+	want := "accepts 1 arg(s), received 0"
+	if err == nil || err.Error() != want {
+		t.Errorf("got %v, want %s", err, want)
+	}
+}
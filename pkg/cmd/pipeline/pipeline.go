@@ -0,0 +1,39 @@
+package pipeline
+
+import (
+	"fmt"
+
+	"github.com/redhat-developer/kam/pkg/cmd/utility"
+	"github.com/spf13/cobra"
+)
+
+// RecommendedCommandName is the recommended pipeline command name.
+const RecommendedCommandName = "pipeline"
+
+// NewCmd creates a new pipeline command
+func NewCmd(name, fullName string) *cobra.Command {
+
+	addCmd := newCmdAdd(addRecommendedCommandName, utility.GetFullName(fullName, addRecommendedCommandName))
+	runCmd := newCmdRun(runRecommendedCommandName, utility.GetFullName(fullName, runRecommendedCommandName))
+	listCmd := newCmdList(listRecommendedCommandName, utility.GetFullName(fullName, listRecommendedCommandName))
+	logsCmd := newCmdLogs(logsRecommendedCommandName, utility.GetFullName(fullName, logsRecommendedCommandName))
+
+	var cmd = &cobra.Command{
+		Use:   name,
+		Short: "Manage pipelines in the CI/CD environment",
+		Long:  "Manage the Tekton Pipelines and Tasks in the CI/CD environment of the GitOps repository, and start and inspect their runs",
+		Example: fmt.Sprintf("%s\n%s\n%s\n%s\n%s\n\n  See sub-commands individually for more examples",
+			fullName, addRecommendedCommandName, runRecommendedCommandName, listRecommendedCommandName, logsRecommendedCommandName),
+		Run: func(cmd *cobra.Command, args []string) {
+		},
+	}
+
+	cmd.Flags().AddFlagSet(addCmd.Flags())
+	cmd.AddCommand(addCmd)
+	cmd.AddCommand(runCmd)
+	cmd.AddCommand(listCmd)
+	cmd.AddCommand(logsCmd)
+
+	cmd.Annotations = map[string]string{"command": "main"}
+	return cmd
+}
@@ -0,0 +1,97 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/openshift/odo/pkg/log"
+	"github.com/spf13/cobra"
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
+	"github.com/redhat-developer/kam/pkg/pipelines/webhook"
+)
+
+const (
+	runRecommendedCommandName = "run"
+)
+
+var (
+	runExample = ktemplates.Examples(`
+	# Start the integration pipeline of a service for the head of its default branch
+	# Example: kam pipeline run --env-name dev --service-name taxi
+
+	%[1]s
+
+	# Start the integration pipeline of a service for a tag
+	# Example: kam pipeline run --env-name dev --service-name taxi --ref refs/tags/v1.0.0`)
+
+	runLongDesc = ktemplates.LongDesc(`Start the integration pipeline of a service without pushing a commit.
+
+	A push event for the ref of the service's source repository is signed with the webhook secret of the service,
+	and sent to the EventListener, as if the ref had been pushed. The PipelineRun that is created for the event is reported.`)
+	runShortDesc = `Start the integration pipeline of a service`
+)
+
+// RunOptions encapsulates the parameters for the pipeline run command.
+type RunOptions struct {
+	accessToken         string
+	envName             string
+	serviceName         string
+	ref                 string
+	pipelinesFolderPath string
+}
+
+// Complete is called when the command is completed
+func (o *RunOptions) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the parameters of the RunOptions.
+func (o *RunOptions) Validate() error {
+	return nil
+}
+
+// Run runs the pipeline run command.
+func (o *RunOptions) Run() error {
+	serviceName := &webhook.QualifiedServiceName{EnvironmentName: o.envName, ServiceName: o.serviceName}
+	run, err := webhook.Run(o.accessToken, o.pipelinesFolderPath, serviceName, o.ref)
+	if err != nil {
+		return fmt.Errorf("unable to start the pipeline: %v", err)
+	}
+	if log.IsJSON() {
+		out, err := json.MarshalIndent(run, "", "	")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(log.GetStdout(), "%s\n", out)
+		return nil
+	}
+	log.Successf("Started PipelineRun %s for %s (%s)", run.Name, run.Ref, run.SHA)
+	return nil
+}
+
+func newCmdRun(name, fullName string) *cobra.Command {
+	o := &RunOptions{}
+
+	cmd := &cobra.Command{
+		Use:     name,
+		Short:   runShortDesc,
+		Long:    runLongDesc,
+		Example: fmt.Sprintf(runExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	cmd.Flags().StringVar(&o.envName, "env-name", "", "Name of the environment of the service")
+	cmd.Flags().StringVar(&o.serviceName, "service-name", "", "Name of the service whose pipeline is started")
+	cmd.Flags().StringVar(&o.ref, "ref", "", "Branch, or full tag ref, e.g. refs/tags/v1.0.0, to build, defaults to the default branch of the repository")
+	cmd.Flags().StringVar(&o.accessToken, "git-host-access-token", "", "Access token to be used to find the commit in the Git repository, defaults to the token from the keyring or environment")
+	cmd.Flags().StringVar(&o.pipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+
+	// required flags
+	_ = cmd.MarkFlagRequired("env-name")
+	_ = cmd.MarkFlagRequired("service-name")
+	return cmd
+}
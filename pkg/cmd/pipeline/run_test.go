@@ -0,0 +1,28 @@
+package pipeline
+
+import (
+	"testing"
+)
+
+func TestRunCommandWithMissingParams(t *testing.T) {
+	cmdTests := []struct {
+		desc    string
+		flags   []keyValuePair
+		wantErr string
+	}{
+		{"Missing env-name flag",
+			[]keyValuePair{flag("service-name", "taxi")},
+			`required flag(s) "env-name" not set`},
+		{"Missing service-name flag",
+			[]keyValuePair{flag("env-name", "dev")},
+			`required flag(s) "service-name" not set`},
+	}
+	for _, tt := range cmdTests {
+		t.Run(tt.desc, func(t *testing.T) {
+			_, _, err := executeCommand(newCmdRun("run", "kam pipeline"), tt.flags...)
+			if err.Error() != tt.wantErr {
+				t.Errorf("got %s, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
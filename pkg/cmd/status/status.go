@@ -0,0 +1,166 @@
+package status
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
+	"github.com/redhat-developer/kam/pkg/pipelines/argocd"
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	backend "github.com/redhat-developer/kam/pkg/pipelines/status"
+)
+
+// RecommendedCommandName is the recommended status command name.
+const RecommendedCommandName = "status"
+
+const jsonOutput = "json"
+
+var (
+	statusExample = ktemplates.Examples(`
+	# Show the sync and health of the ArgoCD applications for each environment
+	%[1]s
+
+	# Show the status as JSON, refreshing every 10 seconds
+	%[1]s --output json --watch --interval 10s
+	`)
+
+	statusLongDesc  = ktemplates.LongDesc(`Show the live ArgoCD sync and health status of the applications in each environment`)
+	statusShortDesc = `Show the sync and health status of environments`
+)
+
+// StatusParameters encapsulates the parameters for the kam status command.
+type StatusParameters struct {
+	pipelinesFolderPath string
+	argoCDNamespace     string
+	output              string
+	watch               bool
+	interval            time.Duration
+	out                 io.Writer
+}
+
+// NewStatusParameters bootstraps a StatusParameters instance.
+func NewStatusParameters() *StatusParameters {
+	return &StatusParameters{out: os.Stdout}
+}
+
+// Complete completes StatusParameters after they've been created.
+func (o *StatusParameters) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the parameters of the StatusParameters.
+func (o *StatusParameters) Validate() error {
+	if o.output != "" && o.output != jsonOutput {
+		return fmt.Errorf("invalid output format %q, only %q is supported", o.output, jsonOutput)
+	}
+	if o.watch && o.interval <= 0 {
+		return fmt.Errorf("the interval must be greater than zero: %s", o.interval)
+	}
+	return nil
+}
+
+// Run runs the status command.
+func (o *StatusParameters) Run() error {
+	m, err := config.LoadManifest(ioutils.NewFilesystem(), o.pipelinesFolderPath)
+	if err != nil {
+		return err
+	}
+	client, err := backend.NewClient()
+	if err != nil {
+		return err
+	}
+	for {
+		envs, err := backend.Get(client, o.argoCDNamespace, m)
+		if err != nil {
+			return err
+		}
+		if err := o.print(envs); err != nil {
+			return err
+		}
+		if !o.watch {
+			return nil
+		}
+		time.Sleep(o.interval)
+	}
+}
+
+func (o *StatusParameters) print(envs []*backend.EnvironmentStatus) error {
+	if o.output == jsonOutput {
+		return printJSON(o.out, envs)
+	}
+	if o.watch {
+		fmt.Fprintf(o.out, "\n%s\n", time.Now().Format(time.RFC1123))
+	}
+	return printTree(o.out, envs)
+}
+
+func printJSON(out io.Writer, envs []*backend.EnvironmentStatus) error {
+	b, err := json.MarshalIndent(envs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal status: %w", err)
+	}
+	_, err = fmt.Fprintf(out, "%s\n", b)
+	return err
+}
+
+func printTree(out io.Writer, envs []*backend.EnvironmentStatus) error {
+	w := tabwriter.NewWriter(out, 5, 2, 3, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSYNC\tHEALTH\tREVISION\tCONDITIONS")
+	for _, env := range envs {
+		fmt.Fprintf(w, "%s\t\t\t\t\n", env.Name)
+		for i, app := range env.Applications {
+			branch := "├──"
+			if i == len(env.Applications)-1 {
+				branch = "└──"
+			}
+			fmt.Fprintf(w, "%s %s\t%s\t%s\t%s\t%s\n", branch, app.ArgoCDApp, app.Sync, app.Health, shortRevision(app.Revision), conditions(app.Conditions))
+		}
+	}
+	return w.Flush()
+}
+
+func conditions(c []backend.Condition) string {
+	s := make([]string, len(c))
+	for i := range c {
+		s[i] = c[i].Type + ": " + c[i].Message
+	}
+	return strings.Join(s, "; ")
+}
+
+func shortRevision(s string) string {
+	if len(s) > 7 {
+		return s[:7]
+	}
+	return s
+}
+
+// NewCmd creates the status command.
+func NewCmd(name, fullName string) *cobra.Command {
+	o := NewStatusParameters()
+
+	statusCmd := &cobra.Command{
+		Use:     name,
+		Short:   statusShortDesc,
+		Long:    statusLongDesc,
+		Example: fmt.Sprintf(statusExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	statusCmd.Flags().StringVar(&o.pipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+	statusCmd.Flags().StringVar(&o.argoCDNamespace, "argocd-namespace", argocd.ArgoCDNamespace, "Namespace where the ArgoCD applications are deployed")
+	statusCmd.Flags().StringVarP(&o.output, "output", "o", "", "Output format, the only supported format is json")
+	statusCmd.Flags().BoolVarP(&o.watch, "watch", "w", false, "Keep watching and printing the status at every interval")
+	statusCmd.Flags().DurationVar(&o.interval, "interval", 5*time.Second, "Interval between refreshes in watch mode")
+	return statusCmd
+}
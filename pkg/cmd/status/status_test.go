@@ -0,0 +1,62 @@
+package status
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	backend "github.com/redhat-developer/kam/pkg/pipelines/status"
+)
+
+func TestValidate(t *testing.T) {
+	validateTests := []struct {
+		desc    string
+		params  *StatusParameters
+		wantErr string
+	}{
+		{"default output", &StatusParameters{}, ""},
+		{"json output", &StatusParameters{output: "json"}, ""},
+		{"invalid output", &StatusParameters{output: "yaml"}, `invalid output format "yaml", only "json" is supported`},
+		{"watch with interval", &StatusParameters{watch: true, interval: time.Second}, ""},
+		{"watch without interval", &StatusParameters{watch: true}, "the interval must be greater than zero: 0s"},
+	}
+
+	for _, tt := range validateTests {
+		t.Run(tt.desc, func(rt *testing.T) {
+			err := tt.params.Validate()
+			if tt.wantErr == "" && err != nil {
+				rt.Fatalf("Validate() got an unexpected error: %s", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				rt.Fatalf("Validate() got %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPrintTree(t *testing.T) {
+	envs := []*backend.EnvironmentStatus{
+		{
+			Name: "dev",
+			Applications: []*backend.ApplicationStatus{
+				{ArgoCDApp: "dev-env", Sync: "Synced", Health: "Healthy", Revision: "0123456789abcdef"},
+				{Name: "taxi", ArgoCDApp: "dev-taxi", Sync: "OutOfSync", Health: "Degraded",
+					Conditions: []backend.Condition{{Type: "SyncError", Message: "failed"}}},
+			},
+		},
+	}
+	var buf bytes.Buffer
+	if err := printTree(&buf, envs); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "NAME           SYNC        HEALTH     REVISION   CONDITIONS\n" +
+		"dev                                              \n" +
+		"├── dev-env    Synced      Healthy    0123456    \n" +
+		"└── dev-taxi   OutOfSync   Degraded              SyncError: failed\n"
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Fatalf("printTree() failed:\n%s", diff)
+	}
+}
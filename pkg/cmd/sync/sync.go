@@ -0,0 +1,117 @@
+package sync
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/openshift/odo/pkg/log"
+	"github.com/spf13/cobra"
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
+	"github.com/redhat-developer/kam/pkg/pipelines/argocd"
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/pkg/pipelines/status"
+)
+
+// RecommendedCommandName is the recommended sync command name.
+const RecommendedCommandName = "sync"
+
+const pollInterval = 5 * time.Second
+
+var (
+	syncExample = ktemplates.Examples(`
+	# Sync all the ArgoCD applications of an environment
+	%[1]s --env-name dev
+
+	# Sync a single application and wait up to 10 minutes for it to become healthy
+	%[1]s --env-name dev --app-name app-taxi --wait --timeout 10m
+	`)
+
+	syncLongDesc  = ktemplates.LongDesc(`Refresh and sync the ArgoCD applications of an environment, optionally waiting for them to become healthy`)
+	syncShortDesc = `Sync the ArgoCD applications of an environment`
+)
+
+// SyncParameters encapsulates the parameters for the kam sync command.
+type SyncParameters struct {
+	pipelinesFolderPath string
+	argoCDNamespace     string
+	envName             string
+	appName             string
+	wait                bool
+	timeout             time.Duration
+}
+
+// NewSyncParameters bootstraps a SyncParameters instance.
+func NewSyncParameters() *SyncParameters {
+	return &SyncParameters{}
+}
+
+// Complete completes SyncParameters after they've been created.
+func (o *SyncParameters) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the parameters of the SyncParameters.
+func (o *SyncParameters) Validate() error {
+	if o.wait && o.timeout <= 0 {
+		return fmt.Errorf("the timeout must be greater than zero: %s", o.timeout)
+	}
+	return nil
+}
+
+// Run runs the sync command.
+func (o *SyncParameters) Run() error {
+	m, err := config.LoadManifest(ioutils.NewFilesystem(), o.pipelinesFolderPath)
+	if err != nil {
+		return err
+	}
+	names, err := status.ApplicationNames(m, o.envName, o.appName)
+	if err != nil {
+		return err
+	}
+	client, err := status.NewClient()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := status.Sync(client, o.argoCDNamespace, name); err != nil {
+			return err
+		}
+		log.Progressf("Requested sync of Application %s", name)
+	}
+	if !o.wait {
+		return nil
+	}
+	if err := status.Wait(client, o.argoCDNamespace, names, pollInterval, o.timeout); err != nil {
+		return err
+	}
+	log.Successf("Synced Application(s) %s successfully.", strings.Join(names, ", "))
+	return nil
+}
+
+// NewCmd creates the sync command.
+func NewCmd(name, fullName string) *cobra.Command {
+	o := NewSyncParameters()
+
+	syncCmd := &cobra.Command{
+		Use:     name,
+		Short:   syncShortDesc,
+		Long:    syncLongDesc,
+		Example: fmt.Sprintf(syncExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	syncCmd.Flags().StringVar(&o.envName, "env-name", "", "Name of the environment to sync")
+	_ = syncCmd.MarkFlagRequired("env-name")
+	syncCmd.Flags().StringVar(&o.appName, "app-name", "", "Name of a single application within the environment to sync")
+	syncCmd.Flags().StringVar(&o.pipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+	syncCmd.Flags().StringVar(&o.argoCDNamespace, "argocd-namespace", argocd.ArgoCDNamespace, "Namespace where the ArgoCD applications are deployed")
+	syncCmd.Flags().BoolVar(&o.wait, "wait", false, "Wait for the sync to complete and the applications to become healthy")
+	syncCmd.Flags().DurationVar(&o.timeout, "timeout", 5*time.Minute, "Maximum time to wait for the sync when --wait is used")
+	return syncCmd
+}
@@ -0,0 +1,78 @@
+package sync
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+type keyValuePair struct {
+	key   string
+	value string
+}
+
+func TestSyncCommandWithMissingParams(t *testing.T) {
+	cmdTests := []struct {
+		desc    string
+		flags   []keyValuePair
+		wantErr string
+	}{
+		{"Missing env-name flag",
+			[]keyValuePair{flag("app-name", "app-taxi")},
+			`required flag(s) "env-name" not set`},
+	}
+	for _, tt := range cmdTests {
+		t.Run(tt.desc, func(rt *testing.T) {
+			_, _, err := executeCommand(NewCmd("sync", "kam sync"), tt.flags...)
+			if err.Error() != tt.wantErr {
+				rt.Errorf("got %s, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	validateTests := []struct {
+		desc    string
+		params  *SyncParameters
+		wantErr string
+	}{
+		{"no wait", &SyncParameters{}, ""},
+		{"wait with timeout", &SyncParameters{wait: true, timeout: time.Minute}, ""},
+		{"wait without timeout", &SyncParameters{wait: true}, "the timeout must be greater than zero: 0s"},
+	}
+
+	for _, tt := range validateTests {
+		t.Run(tt.desc, func(rt *testing.T) {
+			err := tt.params.Validate()
+			if tt.wantErr == "" && err != nil {
+				rt.Fatalf("Validate() got an unexpected error: %s", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				rt.Fatalf("Validate() got %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func executeCommand(cmd *cobra.Command, flags ...keyValuePair) (c *cobra.Command, output string, err error) {
+	buf := new(bytes.Buffer)
+	cmd.SetOutput(buf)
+	for _, flag := range flags {
+		err = cmd.Flags().Set(flag.key, flag.value)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	c, err = cmd.ExecuteC()
+	return c, buf.String(), err
+}
+
+func flag(k, v string) keyValuePair {
+	return keyValuePair{
+		key:   k,
+		value: v,
+	}
+}
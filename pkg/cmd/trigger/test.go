@@ -0,0 +1,190 @@
+package trigger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
+	"github.com/redhat-developer/kam/pkg/pipelines"
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/pkg/pipelines/triggersim"
+)
+
+const (
+	testRecommendedCommandName = "test"
+	jsonOutput                 = "json"
+)
+
+var (
+	testExample = ktemplates.Examples(`
+	# Check which triggers fire for a GitHub push event
+	# Example: kam trigger test --payload push.json --header X-GitHub-Event=push
+
+	# Check which triggers fire for a Bitbucket push event, which has the secret in the webhook URL
+	# Example: kam trigger test --payload push.json --header X-Event-Key=repo:push --url "http://example.com/?secret=<secret>" --webhook-secret <secret>
+
+	%[1]s`)
+
+	testLongDesc = ktemplates.LongDesc(`Evaluate the triggers of the EventListener in the GitOps repository against a webhook payload.
+
+	The interceptors of each trigger are evaluated locally, in the same way as the EventListener would, and the triggers that
+	fire are shown with the values that the params of their TriggerBindings resolve to.
+
+	The secrets in the cluster can't be read, so the CEL interceptors compare secrets with the value of --webhook-secret, the
+	webhook secrets of the GitHub and GitLab interceptors are not verified, and interceptors other than the CEL, GitHub, GitLab
+	and Bitbucket interceptors are not evaluated.`)
+	testShortDesc = `Check which triggers fire for a webhook payload`
+)
+
+// TestOptions encapsulates the parameters for the trigger test command.
+type TestOptions struct {
+	payload             string
+	url                 string
+	headers             []string
+	webhookSecret       string
+	pipelinesFolderPath string
+	output              string
+	out                 io.Writer
+}
+
+// Complete is called when the command is completed
+func (o *TestOptions) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the parameters of the TestOptions.
+func (o *TestOptions) Validate() error {
+	if o.output != "" && o.output != jsonOutput {
+		return fmt.Errorf("invalid output format %q, only %q is supported", o.output, jsonOutput)
+	}
+	_, err := parseHeaders(o.headers)
+	return err
+}
+
+// Run runs the trigger test command.
+func (o *TestOptions) Run() error {
+	fs := ioutils.NewFilesystem()
+	m, err := config.LoadManifest(fs, o.pipelinesFolderPath)
+	if err != nil {
+		return err
+	}
+	body, err := afero.ReadFile(fs, o.payload)
+	if err != nil {
+		return fmt.Errorf("failed to read the payload: %w", err)
+	}
+	headers, err := parseHeaders(o.headers)
+	if err != nil {
+		return err
+	}
+	event, err := triggersim.NewEvent(body, headers, o.url)
+	if err != nil {
+		return err
+	}
+	results, err := pipelines.SimulateTriggers(fs, o.pipelinesFolderPath, m, event, o.webhookSecret)
+	if err != nil {
+		return err
+	}
+	if o.output == jsonOutput {
+		b, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal the results: %w", err)
+		}
+		_, err = fmt.Fprintf(o.out, "%s\n", b)
+		return err
+	}
+	return printResults(o.out, results)
+}
+
+func parseHeaders(values []string) (http.Header, error) {
+	headers := http.Header{}
+	for _, v := range values {
+		parts := strings.SplitN(v, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("invalid header %q, must be in the form name=value", v)
+		}
+		headers.Add(strings.TrimSpace(parts[0]), parts[1])
+	}
+	return headers, nil
+}
+
+func printResults(out io.Writer, results []*triggersim.Result) error {
+	for _, r := range results {
+		if !r.Fired {
+			fmt.Fprintf(out, "%s: not fired, %s\n", r.Trigger, r.Reason)
+			continue
+		}
+		fmt.Fprintf(out, "%s: fired\n", r.Trigger)
+		for _, u := range r.Unverified {
+			fmt.Fprintf(out, "  unverified: %s\n", u)
+		}
+		if len(r.Extensions) > 0 {
+			fmt.Fprintln(out, "  extensions:")
+			keys := []string{}
+			for k := range r.Extensions {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				b, err := json.Marshal(r.Extensions[k])
+				if err != nil {
+					return fmt.Errorf("failed to marshal extension %s: %w", k, err)
+				}
+				fmt.Fprintf(out, "    %s: %s\n", k, b)
+			}
+		}
+		for _, b := range r.Bindings {
+			name := b.Name
+			if name == "" {
+				name = "(inline)"
+			}
+			if b.Error != "" {
+				fmt.Fprintf(out, "  %s: %s\n", name, b.Error)
+				continue
+			}
+			fmt.Fprintf(out, "  %s:\n", name)
+			for _, p := range b.Params {
+				if p.Error != "" {
+					fmt.Fprintf(out, "    %s: %s (%s)\n", p.Name, p.Value, p.Error)
+					continue
+				}
+				fmt.Fprintf(out, "    %s: %s\n", p.Name, p.Value)
+			}
+		}
+	}
+	return nil
+}
+
+func newCmdTest(name, fullName string) *cobra.Command {
+	o := &TestOptions{out: os.Stdout}
+
+	cmd := &cobra.Command{
+		Use:     name,
+		Short:   testShortDesc,
+		Long:    testLongDesc,
+		Example: fmt.Sprintf(testExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	cmd.Flags().StringVar(&o.payload, "payload", "", "Path to a file with the JSON body of the webhook event")
+	cmd.Flags().StringArrayVar(&o.headers, "header", nil, "Header of the webhook event in the form name=value, can be repeated")
+	cmd.Flags().StringVar(&o.url, "url", "", "URL that the webhook event is sent to, with the query of the webhook URL")
+	cmd.Flags().StringVar(&o.webhookSecret, "webhook-secret", "", "Value of the webhook secret that the CEL interceptors compare secrets with")
+	cmd.Flags().StringVarP(&o.output, "output", "o", "", "Output format, the only supported format is json")
+	cmd.Flags().StringVar(&o.pipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+
+	// required flags
+	_ = cmd.MarkFlagRequired("payload")
+	return cmd
+}
@@ -0,0 +1,92 @@
+package trigger
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/triggersim"
+)
+
+func TestTestValidate(t *testing.T) {
+	validateTests := []struct {
+		desc    string
+		options *TestOptions
+		wantErr string
+	}{
+		{"headers", &TestOptions{headers: []string{"X-GitHub-Event=push", "X-Test=a=b"}}, ""},
+		{"json output", &TestOptions{output: "json"}, ""},
+		{"invalid output", &TestOptions{output: "yaml"}, `invalid output format "yaml", only "json" is supported`},
+		{"header without value", &TestOptions{headers: []string{"X-GitHub-Event"}}, `invalid header "X-GitHub-Event", must be in the form name=value`},
+		{"header without name", &TestOptions{headers: []string{"=push"}}, `invalid header "=push", must be in the form name=value`},
+	}
+
+	for _, tt := range validateTests {
+		t.Run(tt.desc, func(rt *testing.T) {
+			err := tt.options.Validate()
+			if tt.wantErr == "" && err != nil {
+				rt.Fatalf("Validate() got an unexpected error: %s", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				rt.Fatalf("Validate() got %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseHeaders(t *testing.T) {
+	got, err := parseHeaders([]string{"x-github-event=push", "X-Test=a=b", "X-Test=c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := http.Header{
+		"X-Github-Event": []string{"push"},
+		"X-Test":         []string{"a=b", "c"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("parseHeaders() failed:\n%s", diff)
+	}
+}
+
+func TestPrintResults(t *testing.T) {
+	results := []*triggersim.Result{
+		{
+			Trigger:    "app-ci-build-from-push",
+			Fired:      true,
+			Unverified: []string{"github: the webhook secret is not verified"},
+			Extensions: map[string]interface{}{"ref": "main", "count": float64(2)},
+			Bindings: []*triggersim.Binding{
+				{Name: "github-push-binding", Params: []*triggersim.Param{
+					{Name: "io.openshift.build.commit.ref", Value: "main"},
+					{Name: "io.openshift.build.commit.id", Value: "$(body.head_commit.id)", Error: "head_commit is not found"},
+				}},
+				{Name: "taxi-binding", Error: "failed to find the TriggerBinding taxi-binding"},
+				{Params: []*triggersim.Param{{Name: "eventType", Value: "push"}}},
+			},
+		},
+		{Trigger: "app-ci-build-from-pr", Reason: "expression header.match('X-GitHub-Event', 'pull_request') did not return true"},
+	}
+	var buf bytes.Buffer
+	if err := printResults(&buf, results); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `app-ci-build-from-push: fired
+  unverified: github: the webhook secret is not verified
+  extensions:
+    count: 2
+    ref: "main"
+  github-push-binding:
+    io.openshift.build.commit.ref: main
+    io.openshift.build.commit.id: $(body.head_commit.id) (head_commit is not found)
+  taxi-binding: failed to find the TriggerBinding taxi-binding
+  (inline):
+    eventType: push
+app-ci-build-from-pr: not fired, expression header.match('X-GitHub-Event', 'pull_request') did not return true
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Fatalf("printResults() failed:\n%s", diff)
+	}
+}
@@ -0,0 +1,33 @@
+package trigger
+
+import (
+	"fmt"
+
+	"github.com/redhat-developer/kam/pkg/cmd/utility"
+	"github.com/spf13/cobra"
+)
+
+// RecommendedCommandName is the recommended trigger command name.
+const RecommendedCommandName = "trigger"
+
+// NewCmd creates a new trigger command
+func NewCmd(name, fullName string) *cobra.Command {
+
+	testCmd := newCmdTest(testRecommendedCommandName, utility.GetFullName(fullName, testRecommendedCommandName))
+
+	var cmd = &cobra.Command{
+		Use:   name,
+		Short: "Inspect the triggers in the CI/CD environment",
+		Long:  "Inspect the triggers of the EventListener in the CI/CD environment of the GitOps repository",
+		Example: fmt.Sprintf("%s\n%s\n\n  See sub-commands individually for more examples",
+			fullName, testRecommendedCommandName),
+		Run: func(cmd *cobra.Command, args []string) {
+		},
+	}
+
+	cmd.Flags().AddFlagSet(testCmd.Flags())
+	cmd.AddCommand(testCmd)
+
+	cmd.Annotations = map[string]string{"command": "main"}
+	return cmd
+}
@@ -0,0 +1,78 @@
+package triggertemplate
+
+import (
+	"fmt"
+
+	"github.com/openshift/odo/pkg/log"
+	"github.com/spf13/cobra"
+	ktemplates "k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/redhat-developer/kam/pkg/cmd/genericclioptions"
+	"github.com/redhat-developer/kam/pkg/pipelines"
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+)
+
+const (
+	addRecommendedCommandName = "add"
+)
+
+var (
+	addExample = ktemplates.Examples(`
+	# Add the TriggerTemplates in a file to the CI/CD environment in GitOps
+	# Example: kam trigger-template add --from-file ./tekton/go-ci-template.yaml --pipelines-folder <path to GitOps folder>
+
+	%[1]s`)
+
+	addLongDesc = ktemplates.LongDesc(`Add custom TriggerTemplates to the CI/CD environment in GitOps.
+
+	The TriggerTemplates can then be referenced by the pipelines of environments and services in the manifest,
+	the Pipelines run by the templates must already exist in the CI/CD environment.
+	TriggerTemplates with the names of the templates that kam generates are rejected.`)
+	addShortDesc = `Add custom TriggerTemplates`
+)
+
+// AddOptions encapsulates the parameters for the trigger-template add
+// command.
+type AddOptions struct {
+	*pipelines.AddTriggerTemplateOptions
+}
+
+// Complete is called when the command is completed
+func (o *AddOptions) Complete(name string, cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+// Validate validates the parameters of the AddOptions.
+func (o *AddOptions) Validate() error {
+	return nil
+}
+
+// Run runs the trigger-template add command.
+func (o *AddOptions) Run() error {
+	if err := pipelines.AddTriggerTemplates(o.AddTriggerTemplateOptions, ioutils.NewFilesystem()); err != nil {
+		return err
+	}
+	log.Successf("Added the TriggerTemplates from %s successfully.", o.FromFile)
+	return nil
+}
+
+func newCmdAdd(name, fullName string) *cobra.Command {
+	o := &AddOptions{AddTriggerTemplateOptions: &pipelines.AddTriggerTemplateOptions{}}
+
+	cmd := &cobra.Command{
+		Use:     name,
+		Short:   addShortDesc,
+		Long:    addLongDesc,
+		Example: fmt.Sprintf(addExample, fullName),
+		Run: func(cmd *cobra.Command, args []string) {
+			genericclioptions.GenericRun(o, cmd, args)
+		},
+	}
+
+	cmd.Flags().StringVar(&o.FromFile, "from-file", "", "YAML file with the TriggerTemplates to add")
+	cmd.Flags().StringVar(&o.PipelinesFolderPath, "pipelines-folder", ".", "Folder path to retrieve manifest, eg. /test where manifest exists at /test/pipelines.yaml")
+
+	// required flags
+	_ = cmd.MarkFlagRequired("from-file")
+	return cmd
+}
@@ -0,0 +1,33 @@
+package triggertemplate
+
+import (
+	"fmt"
+
+	"github.com/redhat-developer/kam/pkg/cmd/utility"
+	"github.com/spf13/cobra"
+)
+
+// RecommendedCommandName is the recommended trigger-template command name.
+const RecommendedCommandName = "trigger-template"
+
+// NewCmd creates a new trigger-template command
+func NewCmd(name, fullName string) *cobra.Command {
+
+	addCmd := newCmdAdd(addRecommendedCommandName, utility.GetFullName(fullName, addRecommendedCommandName))
+
+	var cmd = &cobra.Command{
+		Use:   name,
+		Short: "Manage trigger templates in the CI/CD environment",
+		Long:  "Manage the TriggerTemplates in the CI/CD environment that environments and services reference to run their pipelines",
+		Example: fmt.Sprintf("%s\n%s\n\n  See sub-commands individually for more examples",
+			fullName, addRecommendedCommandName),
+		Run: func(cmd *cobra.Command, args []string) {
+		},
+	}
+
+	cmd.Flags().AddFlagSet(addCmd.Flags())
+	cmd.AddCommand(addCmd)
+
+	cmd.Annotations = map[string]string{"command": "main"}
+	return cmd
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/redhat-developer/kam/pkg/cmd/utility"
 	"github.com/redhat-developer/kam/pkg/pipelines/git"
+	"github.com/redhat-developer/kam/pkg/pipelines/giturl"
 	"gopkg.in/AlecAivazis/survey.v1"
 	"gopkg.in/AlecAivazis/survey.v1/terminal"
 	"k8s.io/apimachinery/pkg/util/validation"
@@ -90,7 +91,11 @@ func ValidateAccessToken(input interface{}, serviceRepo string) error {
 		if err != nil {
 			return fmt.Errorf("%w. %s", err, "Check that the --private-repo-driver option is provided.")
 		}
-		parsedURL, err := url.Parse(serviceRepo)
+		repoURL, err := giturl.Parse(serviceRepo)
+		if err != nil {
+			return fmt.Errorf("failed to parse the provided URL %q: %w", serviceRepo, err)
+		}
+		parsedURL, err := url.Parse(repoURL.HTTPURL())
 		if err != nil {
 			return fmt.Errorf("failed to parse the provided URL %q: %w", serviceRepo, err)
 		}
@@ -109,7 +114,7 @@ func ValidateAccessToken(input interface{}, serviceRepo string) error {
 
 func validateURL(input interface{}) error {
 	if u, ok := input.(string); ok {
-		p, err := url.Parse(u)
+		p, err := giturl.Parse(u)
 		if err != nil {
 			return fmt.Errorf("invalid URL, err: %v", err)
 		}
@@ -2,10 +2,10 @@ package accesstoken
 
 import (
 	"fmt"
-	"net/url"
 	"os"
 	"strings"
 
+	"github.com/redhat-developer/kam/pkg/pipelines/giturl"
 	"github.com/zalando/go-keyring"
 )
 
@@ -29,13 +29,14 @@ func GetAccessToken(gitRepoURL string) (string, error) {
 	return accessToken, nil
 }
 
-// HostFromURL extracts the hostname from the url passed
+// HostFromURL extracts the hostname from the url passed, which can be an SSH
+// URL.
 func HostFromURL(s string) (string, error) {
-	p, err := url.Parse(s)
+	p, err := giturl.Parse(s)
 	if err != nil {
 		return "", err
 	}
-	return strings.ToLower(p.Host), nil
+	return p.Host, nil
 }
 
 //SetSecret sets the secret in the keyring
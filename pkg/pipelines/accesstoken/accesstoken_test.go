@@ -54,6 +54,7 @@ func TestGetAccessToken(t *testing.T) {
 		{"with token in keyring present(gitlab)", "https://gitlab.com/example/service.git", "xyz123", true, false, "xyz123"},
 		{"with token in environment variable(gitlab)", "https://gitlab.com/example/service.git", "xyz123", false, true, "xyz123"},
 		{"with token in environment variable(gitlab) and keyring", "https://gitlab.com/example/service.git", "xyz123", false, true, "xyz123"},
+		{"with token in environment variable(github ssh)", "git@github.com:example/service.git", "xyz123", true, false, "xyz123"},
 	}
 
 	for i, tt := range optionTests {
@@ -1,17 +1,23 @@
 package argocd
 
 import (
+	"fmt"
 	"path/filepath"
 	"sort"
+	"strconv"
 
 	// This is a hack because ArgoCD doesn't support a compatible (code-wise)
 	// version of k8s in common with kam.
 
 	argoappv1 "github.com/redhat-developer/kam/pkg/pipelines/argocd/v1alpha1"
 
+	operatorv1 "github.com/redhat-developer/kam/pkg/pipelines/argocd/operator/v1alpha1"
 	"github.com/redhat-developer/kam/pkg/pipelines/config"
 	"github.com/redhat-developer/kam/pkg/pipelines/meta"
 	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+	"github.com/redhat-developer/kam/pkg/pipelines/scm"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
 )
 
 const appLabel = "app.kubernetes.io/name"
@@ -22,6 +28,19 @@ var (
 		"argoproj.io/v1alpha1",
 	)
 
+	argoCDTypeMeta = meta.TypeMeta(
+		"ArgoCD",
+		"argoproj.io/v1alpha1",
+	)
+
+	// ApplicationsResource identifies ArgoCD Applications for clients that
+	// read them back from the cluster.
+	ApplicationsResource = schema.GroupVersionResource{
+		Group:    "argoproj.io",
+		Version:  "v1alpha1",
+		Resource: "applications",
+	}
+
 	syncPolicy = &argoappv1.SyncPolicy{
 		Automated: &argoappv1.SyncPolicyAutomated{
 			Prune:    true,
@@ -63,9 +82,16 @@ const (
 	ArgoCDNamespace = "openshift-gitops"
 	// ArgoCDManagedByLabel is needed to identify the namespace managed by Argo CD
 	ArgoCDManagedByLabel = "argocd.argoproj.io/managed-by"
-	defaultServer        = "https://kubernetes.default.svc"
-	defaultProject       = "default"
-	argoCDSAName         = "openshift-gitops-argocd-application-controller"
+	// ArgoCDInstanceName is the name of the ArgoCD instance that is configured
+	// from the GitOps repository, this isn't the operator's default
+	// openshift-gitops instance, so that it isn't taken over.
+	ArgoCDInstanceName = "kam-gitops"
+	defaultServer      = "https://kubernetes.default.svc"
+	defaultProject     = "default"
+	argoCDSAName       = ArgoCDInstanceName + "-argocd-application-controller"
+
+	defaultRBACPolicy = "role:readonly"
+	defaultRBACScopes = "[groups]"
 )
 
 // Build creates and returns a set of resources to be used for the ArgoCD
@@ -80,13 +106,21 @@ func Build(argoNS, repoURL string, m *config.Manifest) (res.Resources, error) {
 		return res.Resources{}, nil
 	}
 
+	var drivers map[string]string
+	if m.Config != nil && m.Config.Git != nil {
+		drivers = m.Config.Git.Drivers
+	}
+	gitOpsURL := m.GitOpsURL
+	if !argoCDConfig.SSH {
+		repoURL, gitOpsURL = httpURL(repoURL, drivers), httpURL(gitOpsURL, drivers)
+	}
 	files := make(res.Resources)
-	eb := &argocdBuilder{repoURL: repoURL, files: files, argoCDConfig: argoCDConfig, argoNS: argoNS}
+	eb := &argocdBuilder{repoURL: repoURL, files: files, argoCDConfig: argoCDConfig, argoNS: argoNS, drivers: drivers}
 	err := m.Walk(eb)
 	if err != nil {
 		return nil, err
 	}
-	err = argoCDConfigResources(m.Config, m.GitOpsURL, eb.files)
+	err = argoCDConfigResources(m.Config, gitOpsURL, eb.files)
 	if err != nil {
 		return nil, err
 	}
@@ -98,38 +132,87 @@ type argocdBuilder struct {
 	argoCDConfig *config.ArgoCDConfig
 	files        res.Resources
 	argoNS       string
+	drivers      map[string]string
 }
 
 func (b *argocdBuilder) Application(env *config.Environment, app *config.Application) error {
-	basePath := filepath.ToSlash(filepath.Join(filepath.Join(config.PathForArgoCD())))
+	basePath := b.pathForEnvironment(env)
 	argoFiles := res.Resources{}
-	filename := filepath.ToSlash(filepath.Join(basePath, env.Name+"-"+app.Name+"-app.yaml"))
+	filename := filepath.ToSlash(filepath.Join(basePath, AppName(env, app)+"-app.yaml"))
 
-	argoFiles[filename] = makeApplication(app, env.Name+"-"+app.Name, b.argoNS,
+	source := makeAppSource(env, app, b.repoURL)
+	if !b.argoCDConfig.SSH {
+		source.RepoURL = httpURL(source.RepoURL, b.drivers)
+	}
+	argoApp := makeApplication(app, AppName(env, app), b.argoNS,
 		defaultProject,
 		env.Name,
 		clusterForEnv(env),
-		makeAppSource(env, app, b.repoURL))
+		source)
+	if wave := config.ApplicationSyncWaves(env)[app.Name]; wave > 0 {
+		argoApp.ObjectMeta.Annotations = map[string]string{
+			config.SyncWaveAnnotation: strconv.Itoa(wave),
+		}
+	}
+	argoFiles[filename] = argoApp
 	b.files = res.Merge(argoFiles, b.files)
 	return nil
 }
 
 func (b *argocdBuilder) Environment(env *config.Environment) error {
-	basePath := filepath.ToSlash(filepath.Join(filepath.Join(config.PathForArgoCD())))
+	basePath := b.pathForEnvironment(env)
 	argoFiles := res.Resources{}
-	filename := filepath.ToSlash(filepath.Join(basePath, env.Name+"-env-app.yaml"))
+	filename := filepath.ToSlash(filepath.Join(basePath, EnvAppName(env)+"-app.yaml"))
 
 	argoFiles[filename] = makeApplication(
 		nil,
-		env.Name+"-env", b.argoNS,
+		EnvAppName(env), b.argoNS,
 		defaultProject,
 		env.Name,
 		clusterForEnv(env),
 		makeEnvSource(env, b.repoURL))
+	if b.argoCDConfig.AppOfApps {
+		// The environment's Applications have all been visited by now, so the
+		// environment folder can be completed with its kustomization and the
+		// parent Application that syncs it.
+		argoFiles[filepath.ToSlash(filepath.Join(basePath, "kustomization.yaml"))] = kustomizationFor(basePath, res.Merge(argoFiles, b.files))
+		rootPath := filepath.ToSlash(config.PathForArgoCD())
+		argoFiles[filepath.ToSlash(filepath.Join(rootPath, EnvAppsName(env)+"-app.yaml"))] = ignoreDifferences(
+			makeApplication(nil, EnvAppsName(env), b.argoNS, defaultProject, b.argoNS, defaultServer,
+				&argoappv1.ApplicationSource{RepoURL: b.repoURL, Path: basePath}))
+	}
 	b.files = res.Merge(argoFiles, b.files)
 	return nil
 }
 
+// pathForEnvironment returns the folder that the Applications for an
+// environment are written to, with app-of-apps enabled each environment has
+// its own folder.
+func (b *argocdBuilder) pathForEnvironment(env *config.Environment) string {
+	if b.argoCDConfig.AppOfApps {
+		return filepath.ToSlash(filepath.Join(config.PathForArgoCD(), env.Name))
+	}
+	return filepath.ToSlash(config.PathForArgoCD())
+}
+
+// AppName returns the name of the ArgoCD Application that is generated for an
+// application within an environment.
+func AppName(env *config.Environment, app *config.Application) string {
+	return env.Name + "-" + app.Name
+}
+
+// EnvAppsName returns the name of the parent ArgoCD Application that is
+// generated for the Applications of an environment when app-of-apps is enabled.
+func EnvAppsName(env *config.Environment) string {
+	return env.Name + "-apps"
+}
+
+// EnvAppName returns the name of the ArgoCD Application that is generated for
+// the environment configuration.
+func EnvAppName(env *config.Environment) string {
+	return env.Name + "-env"
+}
+
 func argoCDConfigResources(cfg *config.Config, repoURL string, files res.Resources) error {
 	if cfg.ArgoCD.Namespace == "" {
 		return nil
@@ -140,18 +223,69 @@ func argoCDConfigResources(cfg *config.Config, repoURL string, files res.Resourc
 		ignoreDifferences(makeApplication(nil, "argo-app", cfg.ArgoCD.Namespace,
 			defaultProject, cfg.ArgoCD.Namespace, defaultServer,
 			&argoappv1.ApplicationSource{RepoURL: repoURL, Path: basePath}))
+	argoCD, err := makeArgoCD(cfg.ArgoCD)
+	if err != nil {
+		return err
+	}
+	files[filepath.ToSlash(filepath.Join(basePath, "argocd.yaml"))] = argoCD
 	if cfg.Pipelines != nil {
 		files[filepath.ToSlash(filepath.Join(basePath, "cicd-app.yaml"))] = ignoreDifferences(
 			makeApplication(nil, "cicd-app", cfg.ArgoCD.Namespace, defaultProject, cfg.Pipelines.Name, defaultServer,
 				&argoappv1.ApplicationSource{RepoURL: repoURL, Path: filepath.ToSlash(filepath.Join(config.PathForPipelines(cfg.Pipelines), "overlays"))}))
 	}
+	files[filename] = kustomizationFor(basePath, files)
+	return nil
+}
+
+// kustomizationFor creates a Kustomization for the files that are directly
+// within the basePath folder.
+func kustomizationFor(basePath string, files res.Resources) *res.Kustomization {
 	resourceNames := []string{}
 	for k := range files {
-		resourceNames = append(resourceNames, filepath.Base(k))
+		if filepath.ToSlash(filepath.Dir(k)) == basePath {
+			resourceNames = append(resourceNames, filepath.Base(k))
+		}
 	}
 	sort.Strings(resourceNames)
-	files[filename] = &res.Kustomization{Resources: resourceNames}
-	return nil
+	return &res.Kustomization{Resources: resourceNames}
+}
+
+// makeArgoCD creates the ArgoCD custom resource that configures the ArgoCD
+// instance, so that the instance itself is managed through GitOps.
+//
+// The users have the read-only role by default, and the RBAC configuration of
+// the manifest overrides the defaults.
+func makeArgoCD(cfg *config.ArgoCDConfig) (*operatorv1.ArgoCD, error) {
+	exclusions, err := yaml.Marshal(resourceExclusions.Resources)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the resource exclusions: %w", err)
+	}
+	defaultPolicy, scopes := defaultRBACPolicy, defaultRBACScopes
+	if cfg.RBACDefaultPolicy != "" {
+		defaultPolicy = cfg.RBACDefaultPolicy
+	}
+	if cfg.RBACScopes != "" {
+		scopes = cfg.RBACScopes
+	}
+	argoCD := &operatorv1.ArgoCD{
+		TypeMeta:   argoCDTypeMeta,
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(cfg.Namespace, ArgoCDInstanceName)),
+		Spec: operatorv1.ArgoCDSpec{
+			ResourceExclusions: string(exclusions),
+			RBAC: &operatorv1.ArgoCDRBACSpec{
+				DefaultPolicy: &defaultPolicy,
+				Scopes:        &scopes,
+			},
+			Server: operatorv1.ArgoCDServerSpec{
+				Route: operatorv1.ArgoCDRouteSpec{Enabled: true},
+			},
+		},
+	}
+	if cfg.RBACPolicy != "" {
+		policy := cfg.RBACPolicy
+		argoCD.Spec.RBAC.Policy = &policy
+	}
+	return argoCD, nil
 }
 
 func makeAppSource(env *config.Environment, app *config.Application, repoURL string) *argoappv1.ApplicationSource {
@@ -161,11 +295,47 @@ func makeAppSource(env *config.Environment, app *config.Application, repoURL str
 			Path:    filepath.ToSlash(filepath.Join(config.PathForApplication(env, app), "overlays")),
 		}
 	}
-	return &argoappv1.ApplicationSource{
+	source := &argoappv1.ApplicationSource{
 		RepoURL:        app.ConfigRepo.URL,
 		Path:           app.ConfigRepo.Path,
 		TargetRevision: app.ConfigRepo.TargetRevision,
 	}
+	if helm := app.ConfigRepo.Helm; helm != nil {
+		if helm.Chart != "" {
+			source.Chart = helm.Chart
+			source.TargetRevision = helm.Version
+		}
+		if len(helm.ValueFiles) > 0 || helm.Values != "" {
+			source.Helm = &argoappv1.ApplicationSourceHelm{
+				ValueFiles: helm.ValueFiles,
+				Values:     helm.Values,
+			}
+		}
+	}
+	return source
+}
+
+// httpURL converts SSH repository URLs to HTTPS, so that Argo CD doesn't need
+// SSH credentials to fetch the repository, other URLs are returned unchanged.
+//
+// The driver of the host is needed for the HTTPS URL, as Bitbucket Server
+// serves the repositories under /scm, the drivers configured in the manifest
+// take precedence.
+func httpURL(repoURL string, drivers map[string]string) string {
+	host, err := scm.HostnameFromURL(repoURL)
+	if err != nil {
+		return repoURL
+	}
+	driver, ok := drivers[host]
+	if !ok {
+		// Unknown hosts have no driver, and the URL is only converted.
+		driver, _ = scm.GetDriverName(repoURL)
+	}
+	u, err := scm.HTTPCloneURL(repoURL, driver)
+	if err != nil {
+		return repoURL
+	}
+	return u
 }
 
 func makeEnvSource(env *config.Environment, repoURL string) *argoappv1.ApplicationSource {
@@ -11,6 +11,7 @@ import (
 	// This is a hack because ArgoCD doesn't support a compatible (code-wise)
 	// version of k8s in common with kam
 
+	operatorv1 "github.com/redhat-developer/kam/pkg/pipelines/argocd/operator/v1alpha1"
 	argoappv1 "github.com/redhat-developer/kam/pkg/pipelines/argocd/v1alpha1"
 	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
 )
@@ -97,9 +98,11 @@ func TestBuildCreatesArgoCD(t *testing.T) {
 			},
 		},
 		"config/argocd/argo-app.yaml": fakeArgoApplication(),
+		"config/argocd/argocd.yaml":   fakeArgoCD(),
 		"config/argocd/kustomization.yaml": &res.Kustomization{
 			Resources: []string{
 				"argo-app.yaml",
+				"argocd.yaml",
 				"test-dev-env-app.yaml",
 				"test-dev-http-api-app.yaml",
 			},
@@ -132,12 +135,13 @@ func TestBuildCreatesArgoCDWithMultipleApps(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	if len(files) != 6 {
+	if len(files) != 7 {
 		t.Fatalf("got %d files, want 7\n", len(files))
 	}
 	want := &res.Kustomization{
 		Resources: []string{
 			"argo-app.yaml",
+			"argocd.yaml",
 			"test-dev-env-app.yaml",
 			"test-dev-http-api-app.yaml",
 			"test-production-env-app.yaml",
@@ -149,6 +153,116 @@ func TestBuildCreatesArgoCDWithMultipleApps(t *testing.T) {
 	}
 }
 
+func TestBuildCreatesArgoCDWithAppOfApps(t *testing.T) {
+	devEnv := &config.Environment{
+		Name: "test-dev",
+		Apps: []*config.Application{
+			testApp,
+		},
+	}
+	m := &config.Manifest{
+		Environments: []*config.Environment{
+			{Name: "test-production"},
+			devEnv,
+		},
+		Config: &config.Config{
+			ArgoCD: &config.ArgoCDConfig{Namespace: ArgoCDNamespace, AppOfApps: true},
+		},
+	}
+
+	files, err := Build(ArgoCDNamespace, testRepoURL, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := res.Resources{
+		"config/argocd/kustomization.yaml": &res.Kustomization{
+			Resources: []string{
+				"argo-app.yaml",
+				"argocd.yaml",
+				"test-dev-apps-app.yaml",
+				"test-production-apps-app.yaml",
+			},
+		},
+		"config/argocd/test-dev/kustomization.yaml": &res.Kustomization{
+			Resources: []string{
+				"test-dev-env-app.yaml",
+				"test-dev-http-api-app.yaml",
+			},
+		},
+		"config/argocd/test-production/kustomization.yaml": &res.Kustomization{
+			Resources: []string{
+				"test-production-env-app.yaml",
+			},
+		},
+		"config/argocd/test-dev-apps-app.yaml": &argoappv1.Application{
+			TypeMeta:   applicationTypeMeta,
+			ObjectMeta: meta.ObjectMeta(meta.NamespacedName(ArgoCDNamespace, "test-dev-apps")),
+			Spec: argoappv1.ApplicationSpec{
+				Source: argoappv1.ApplicationSource{
+					RepoURL: testRepoURL,
+					Path:    "config/argocd/test-dev",
+				},
+				Destination: argoappv1.ApplicationDestination{
+					Server:    defaultServer,
+					Namespace: ArgoCDNamespace,
+				},
+				Project:           defaultProject,
+				SyncPolicy:        syncPolicy,
+				IgnoreDifferences: ignoreDifferencesFields,
+			},
+		},
+	}
+	for k, v := range want {
+		if diff := cmp.Diff(v, files[k]); diff != "" {
+			t.Errorf("%s didn't match: %s\n", k, diff)
+		}
+	}
+	for _, k := range []string{"config/argocd/test-dev/test-dev-http-api-app.yaml", "config/argocd/test-production/test-production-env-app.yaml"} {
+		if files[k] == nil {
+			t.Errorf("%s was not generated", k)
+		}
+	}
+	if len(files) != 10 {
+		t.Fatalf("got %d files, want 10\n", len(files))
+	}
+}
+
+func TestBuildAddsSyncWavesToApps(t *testing.T) {
+	devEnv := &config.Environment{
+		Name: "test-dev",
+		Apps: []*config.Application{
+			{Name: "frontend", DependsOn: []string{"api"}},
+			{Name: "api", DependsOn: []string{"database"}},
+			{Name: "database"},
+		},
+	}
+	m := &config.Manifest{
+		Environments: []*config.Environment{devEnv},
+		Config: &config.Config{
+			ArgoCD: &config.ArgoCDConfig{Namespace: ArgoCDNamespace},
+		},
+	}
+
+	files, err := Build(ArgoCDNamespace, testRepoURL, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]map[string]string{
+		"config/argocd/test-dev-frontend-app.yaml": {config.SyncWaveAnnotation: "2"},
+		"config/argocd/test-dev-api-app.yaml":      {config.SyncWaveAnnotation: "1"},
+		"config/argocd/test-dev-database-app.yaml": nil,
+		"config/argocd/test-dev-env-app.yaml":      nil,
+	}
+	for k, v := range want {
+		app := files[k].(*argoappv1.Application)
+		if diff := cmp.Diff(v, app.Annotations); diff != "" {
+			t.Errorf("%s annotations didn't match: %s\n", k, diff)
+		}
+	}
+}
+
 func TestBuildWithNoRepoURL(t *testing.T) {
 	m := &config.Manifest{
 		Environments: []*config.Environment{
@@ -241,9 +355,11 @@ func TestBuildWithRepoConfig(t *testing.T) {
 			},
 		},
 		"config/argocd/argo-app.yaml": fakeArgoApplication(),
+		"config/argocd/argocd.yaml":   fakeArgoCD(),
 		"config/argocd/kustomization.yaml": &res.Kustomization{
 			Resources: []string{
 				"argo-app.yaml",
+				"argocd.yaml",
 				"test-production-env-app.yaml",
 				"test-production-prod-api-app.yaml",
 			},
@@ -255,6 +371,153 @@ func TestBuildWithRepoConfig(t *testing.T) {
 	}
 }
 
+func TestBuildWithSSHRepoURLs(t *testing.T) {
+	sshRepoURL := "git@github.com:rhd-example-gitops/example.git"
+	sshConfigRepoURL := "git@github.com:rhd-example-gitops/other-repo.git"
+	sshTests := []struct {
+		name          string
+		ssh           bool
+		wantRepoURL   string
+		wantConfigURL string
+	}{
+		{"converted to HTTPS", false, "https://github.com/rhd-example-gitops/example.git", "https://github.com/rhd-example-gitops/other-repo.git"},
+		{"SSH credentials configured", true, sshRepoURL, sshConfigRepoURL},
+	}
+
+	for _, tt := range sshTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			prodEnv := &config.Environment{
+				Name: "test-production",
+				Apps: []*config.Application{
+					{Name: "http-api"},
+					{Name: "prod-api", ConfigRepo: &config.Repository{URL: sshConfigRepoURL, Path: "deploys"}},
+				},
+			}
+			m := &config.Manifest{
+				GitOpsURL:    sshRepoURL,
+				Environments: []*config.Environment{prodEnv},
+				Config: &config.Config{
+					ArgoCD: &config.ArgoCDConfig{Namespace: ArgoCDNamespace, SSH: tt.ssh},
+				},
+			}
+
+			files, err := Build(ArgoCDNamespace, sshRepoURL, m)
+			if err != nil {
+				rt.Fatal(err)
+			}
+
+			want := map[string]string{
+				"config/argocd/argo-app.yaml":                     tt.wantRepoURL,
+				"config/argocd/test-production-env-app.yaml":      tt.wantRepoURL,
+				"config/argocd/test-production-http-api-app.yaml": tt.wantRepoURL,
+				"config/argocd/test-production-prod-api-app.yaml": tt.wantConfigURL,
+			}
+			for filename, wantURL := range want {
+				app := files[filename].(*argoappv1.Application)
+				if app.Spec.Source.RepoURL != wantURL {
+					rt.Errorf("%s got RepoURL %q, want %q", filename, app.Spec.Source.RepoURL, wantURL)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildWithStashSSHRepoURL(t *testing.T) {
+	sshRepoURL := "ssh://git@bitbucket.example.com:7999/proj/gitops.git"
+	m := &config.Manifest{
+		GitOpsURL: sshRepoURL,
+		Environments: []*config.Environment{
+			{Name: "test-production", Apps: []*config.Application{{Name: "http-api"}}},
+		},
+		Config: &config.Config{
+			ArgoCD: &config.ArgoCDConfig{Namespace: ArgoCDNamespace},
+			Git:    &config.GitConfig{Drivers: map[string]string{"bitbucket.example.com": "stash"}},
+		},
+	}
+
+	files, err := Build(ArgoCDNamespace, sshRepoURL, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantURL := "https://bitbucket.example.com/scm/proj/gitops.git"
+	for _, filename := range []string{"config/argocd/argo-app.yaml", "config/argocd/test-production-http-api-app.yaml"} {
+		app := files[filename].(*argoappv1.Application)
+		if app.Spec.Source.RepoURL != wantURL {
+			t.Errorf("%s got RepoURL %q, want %q", filename, app.Spec.Source.RepoURL, wantURL)
+		}
+	}
+}
+
+func TestMakeAppSourceWithHelmChart(t *testing.T) {
+	sourceTests := []struct {
+		desc string
+		repo *config.Repository
+		want *argoappv1.ApplicationSource
+	}{
+		{
+			"chart from a Helm repository",
+			&config.Repository{
+				URL: "https://charts.example.com",
+				Helm: &config.HelmChart{
+					Chart:      "redis",
+					Version:    "10.5.7",
+					ValueFiles: []string{"values-production.yaml"},
+					Values:     "replicas: 2\n",
+				},
+			},
+			&argoappv1.ApplicationSource{
+				RepoURL:        "https://charts.example.com",
+				Chart:          "redis",
+				TargetRevision: "10.5.7",
+				Helm: &argoappv1.ApplicationSourceHelm{
+					ValueFiles: []string{"values-production.yaml"},
+					Values:     "replicas: 2\n",
+				},
+			},
+		},
+		{
+			"chart from a Git repository",
+			&config.Repository{
+				URL:            "https://github.com/rhd-example-gitops/other-repo",
+				Path:           "charts/api",
+				TargetRevision: "master",
+				Helm:           &config.HelmChart{ValueFiles: []string{"values-production.yaml"}},
+			},
+			&argoappv1.ApplicationSource{
+				RepoURL:        "https://github.com/rhd-example-gitops/other-repo",
+				Path:           "charts/api",
+				TargetRevision: "master",
+				Helm: &argoappv1.ApplicationSourceHelm{
+					ValueFiles: []string{"values-production.yaml"},
+				},
+			},
+		},
+		{
+			"chart with no values",
+			&config.Repository{
+				URL:  "https://charts.example.com",
+				Helm: &config.HelmChart{Chart: "redis", Version: "10.5.7"},
+			},
+			&argoappv1.ApplicationSource{
+				RepoURL:        "https://charts.example.com",
+				Chart:          "redis",
+				TargetRevision: "10.5.7",
+			},
+		},
+	}
+
+	for _, tt := range sourceTests {
+		t.Run(tt.desc, func(rt *testing.T) {
+			app := &config.Application{Name: "prod-api", ConfigRepo: tt.repo}
+			got := makeAppSource(&config.Environment{Name: "test-production"}, app, testRepoURL)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				rt.Fatalf("makeAppSource() failed: %s", diff)
+			}
+		})
+	}
+}
+
 func TestBuildAddsClusterToApp(t *testing.T) {
 	testEnv = &config.Environment{
 		Name:    "test-dev",
@@ -313,9 +576,11 @@ func TestBuildAddsClusterToApp(t *testing.T) {
 			},
 		},
 		"config/argocd/argo-app.yaml": fakeArgoApplication(),
+		"config/argocd/argocd.yaml":   fakeArgoCD(),
 		"config/argocd/kustomization.yaml": &res.Kustomization{
 			Resources: []string{
 				"argo-app.yaml",
+				"argocd.yaml",
 				"test-dev-env-app.yaml",
 				"test-dev-http-api-app.yaml",
 			},
@@ -344,6 +609,34 @@ func TestIgnoreDifferences(t *testing.T) {
 	}
 }
 
+func TestMakeArgoCD(t *testing.T) {
+	got, err := makeArgoCD(&config.ArgoCDConfig{Namespace: ArgoCDNamespace})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(fakeArgoCD(), got); diff != "" {
+		t.Fatalf("makeArgoCD() failed: %s", diff)
+	}
+}
+
+func TestMakeArgoCDWithRBAC(t *testing.T) {
+	got, err := makeArgoCD(&config.ArgoCDConfig{
+		Namespace:         ArgoCDNamespace,
+		RBACDefaultPolicy: "role:admin",
+		RBACPolicy:        "g, system:cluster-admins, role:admin",
+		RBACScopes:        "[groups, email]",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defaultPolicy, policy, scopes := "role:admin", "g, system:cluster-admins, role:admin", "[groups, email]"
+	want := fakeArgoCD()
+	want.Spec.RBAC = &operatorv1.ArgoCDRBACSpec{DefaultPolicy: &defaultPolicy, Policy: &policy, Scopes: &scopes}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("makeArgoCD() failed: %s", diff)
+	}
+}
+
 func fakeArgoApplication() *argoappv1.Application {
 	return &argoappv1.Application{
 		TypeMeta:   applicationTypeMeta,
@@ -357,3 +650,16 @@ func fakeArgoApplication() *argoappv1.Application {
 		},
 	}
 }
+
+func fakeArgoCD() *operatorv1.ArgoCD {
+	defaultPolicy, scopes := "role:readonly", "[groups]"
+	return &operatorv1.ArgoCD{
+		TypeMeta:   meta.TypeMeta("ArgoCD", "argoproj.io/v1alpha1"),
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(ArgoCDNamespace, "kam-gitops")),
+		Spec: operatorv1.ArgoCDSpec{
+			ResourceExclusions: "- apiGroups:\n  - tekton.dev\n  clusters:\n  - '*'\n  kinds:\n  - TaskRun\n  - PipelineRun\n",
+			RBAC:               &operatorv1.ArgoCDRBACSpec{DefaultPolicy: &defaultPolicy, Scopes: &scopes},
+			Server:             operatorv1.ArgoCDServerSpec{Route: operatorv1.ArgoCDRouteSpec{Enabled: true}},
+		},
+	}
+}
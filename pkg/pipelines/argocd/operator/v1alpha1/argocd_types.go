@@ -14,6 +14,21 @@ type ArgoCD struct {
 	Spec ArgoCDSpec `json:"spec,omitempty"`
 }
 
+// ArgoCDRBACSpec defines the desired state for the Argo CD RBAC configuration.
+type ArgoCDRBACSpec struct {
+	// DefaultPolicy is the name of the default role which Argo CD falls back to when
+	// authorizing API requests (optional). If omitted or empty, users may still be able to login,
+	// but will see no apps, projects, etc...
+	DefaultPolicy *string `json:"defaultPolicy,omitempty"`
+
+	// Policy is CSV containing user-defined RBAC policies and role definitions.
+	Policy *string `json:"policy,omitempty"`
+
+	// Scopes controls which OIDC scopes to examine during rbac enforcement (in addition to `sub` scope).
+	// If omitted, defaults to: '[groups]'.
+	Scopes *string `json:"scopes,omitempty"`
+}
+
 // ArgoCDRouteSpec defines the desired state for an OpenShift Route.
 type ArgoCDRouteSpec struct {
 	// Enabled will toggle the creation of the OpenShift Route.
@@ -32,6 +47,9 @@ type ArgoCDSpec struct {
 	// ResourceExclusions is used to completely ignore entire classes of resource group/kinds.
 	ResourceExclusions string `json:"resourceExclusions,omitempty"`
 
+	// RBAC defines the RBAC configuration for Argo CD.
+	RBAC *ArgoCDRBACSpec `json:"rbac,omitempty"`
+
 	// Server defines the options for the ArgoCD Server component.
 	Server ArgoCDServerSpec `json:"server,omitempty"`
 }
@@ -3,11 +3,9 @@ package pipelines
 import (
 	"errors"
 	"fmt"
-	"net/url"
 	"path/filepath"
 	"sort"
 	"strconv"
-	"strings"
 
 	"github.com/mitchellh/go-homedir"
 	"github.com/openshift/odo/pkg/log"
@@ -21,6 +19,7 @@ import (
 	"github.com/redhat-developer/kam/pkg/pipelines/deployment"
 	"github.com/redhat-developer/kam/pkg/pipelines/dryrun"
 	"github.com/redhat-developer/kam/pkg/pipelines/eventlisteners"
+	"github.com/redhat-developer/kam/pkg/pipelines/giturl"
 	"github.com/redhat-developer/kam/pkg/pipelines/imagerepo"
 	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
 	"github.com/redhat-developer/kam/pkg/pipelines/meta"
@@ -32,6 +31,7 @@ import (
 	"github.com/redhat-developer/kam/pkg/pipelines/scm"
 	"github.com/redhat-developer/kam/pkg/pipelines/secrets"
 	"github.com/redhat-developer/kam/pkg/pipelines/tasks"
+	"github.com/redhat-developer/kam/pkg/pipelines/tektonapi"
 	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
 	"github.com/redhat-developer/kam/pkg/pipelines/yaml"
 	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
@@ -51,6 +51,15 @@ const (
 	appCiPipelinesPath    = "04-pipelines/app-ci-pipeline.yaml"
 	pushTemplatePath      = "06-templates/ci-dryrun-from-push-template.yaml"
 	appCIPushTemplatePath = "06-templates/app-ci-build-from-push-template.yaml"
+	buildImageTaskPath    = "03-tasks/build-image-without-push-task.yaml"
+	appCIPRPipelinesPath  = "04-pipelines/app-ci-pr-pipeline.yaml"
+	appCIPRTemplatePath   = "06-templates/app-ci-build-from-pr-template.yaml"
+	prCommentTaskPath     = "03-tasks/post-pr-comment-task.yaml"
+	updateImageTaskPath   = "03-tasks/update-gitops-image-task.yaml"
+	notificationTaskPath  = "03-tasks/send-notification-task.yaml"
+	buildpacksTaskPath    = "03-tasks/buildpacks-task.yaml"
+	ciPRPipelinesPath     = "04-pipelines/ci-dryrun-from-pr-pipeline.yaml"
+	prTemplatePath        = "06-templates/ci-dryrun-from-pr-template.yaml"
 	eventListenerPath     = "07-eventlisteners/cicd-event-listener.yaml"
 	routePath             = "08-routes/gitops-webhook-event-listener.yaml"
 
@@ -63,10 +72,11 @@ const (
 	roleBindingName     = "pipelines-service-role-binding"
 	webhookSecretLength = 20
 
-	pipelinesFile     = "pipelines.yaml"
-	bootstrapImage    = "nginxinc/nginx-unprivileged:latest"
-	appCITemplateName = "app-ci-template"
-	version           = 1
+	pipelinesFile       = "pipelines.yaml"
+	bootstrapImage      = "nginxinc/nginx-unprivileged:latest"
+	appCITemplateName   = "app-ci-template"
+	appCIPRTemplateName = "app-ci-pr-template"
+	version             = 1
 )
 
 // BootstrapOptions is a struct that provides the optional flags
@@ -84,6 +94,8 @@ type BootstrapOptions struct {
 	ServiceWebhookSecret     string // This is the secret for authenticating hooks from your app source.
 	PrivateRepoDriver        string // Records the type of the GitOpsRepoURL driver if not a well-known host.
 	PushToGit                bool   // If true, gitops repository is pushed to remote git repository.
+	ImageUpdatePullRequest   bool   // If true, built images are updated in the gitops repository with pull requests, rather than pushes.
+	TektonAPIVersion         string // The version of the Tekton APIs to generate resources for, either v1beta1 or v1.
 }
 
 // PolicyRules to be bound to service account
@@ -140,7 +152,10 @@ func Bootstrap(o *BootstrapOptions, appFs afero.Fs) error {
 		return fmt.Errorf("failed to build resources: %v", err)
 	}
 
-	bootstrapped = res.Merge(built, bootstrapped)
+	bootstrapped, err = tektonapi.ConvertResources(res.Merge(built, bootstrapped), m.GetPipelinesConfig().GetTektonAPIVersion())
+	if err != nil {
+		return fmt.Errorf("failed to convert resources: %v", err)
+	}
 	log.Successf("Created dev, stage and CICD environments")
 	_, err = yaml.WriteResources(appFs, o.OutputPath, bootstrapped)
 	if err != nil {
@@ -225,6 +240,8 @@ func bootstrapResources(o *BootstrapOptions, appFs afero.Fs) (res.Resources, res
 		}
 		configEnv.Git = &config.GitConfig{Drivers: map[string]string{host: o.PrivateRepoDriver}}
 	}
+	configEnv.Pipelines.TektonAPIVersion = o.TektonAPIVersion
+	configEnv.Pipelines.ImageUpdatePullRequest = o.ImageUpdatePullRequest
 	m := createManifest(gitOpsRepo.URL(), configEnv, envs...)
 
 	devEnv := m.GetEnvironment(ns["dev"])
@@ -274,11 +291,7 @@ func bootstrapResources(o *BootstrapOptions, appFs afero.Fs) (res.Resources, res
 	}
 
 	// This is specific to bootstrap, because there's only one service.
-	devEnv.Apps[0].Services[0].Pipelines = &config.Pipelines{
-		Integration: &config.TemplateBinding{
-			Bindings: append([]string{bindingName}, devEnv.Pipelines.Integration.Bindings...),
-		},
-	}
+	devEnv.Apps[0].Services[0].Pipelines = servicePipelines(devEnv.Pipelines, bindingName)
 	bootstrapped[pipelinesFile] = m
 
 	k.AddResources(imageRepoBindingFilename)
@@ -368,22 +381,21 @@ func applicationFromRepo(repoURL string, service *config.Service) (*config.Appli
 }
 
 func repoFromURL(raw string) (string, error) {
-	u, err := url.Parse(raw)
+	u, err := giturl.Parse(raw)
 	if err != nil {
 		return "", err
 	}
-	parts := strings.Split(u.Path, "/")
-	return strings.TrimSuffix(parts[len(parts)-1], ".git"), nil
+	return u.RepoName(), nil
 }
 
+// orgRepoFromURL returns the full path of the repository, including all the
+// groups that GitLab projects can be nested in.
 func orgRepoFromURL(raw string) (string, error) {
-	u, err := url.Parse(raw)
+	u, err := giturl.Parse(raw)
 	if err != nil {
 		return "", err
 	}
-	parts := strings.Split(u.Path, "/")
-	orgRepo := strings.Join(parts[len(parts)-2:], "/")
-	return strings.TrimSuffix(orgRepo, ".git"), nil
+	return u.RepoPath(), nil
 }
 
 func createBootstrapService(appName, ns, name string) *corev1.Service {
@@ -419,7 +431,27 @@ func defaultPipelines(r scm.Repository) *config.Pipelines {
 			Template: appCITemplateName,
 			Bindings: []string{r.PushBindingName()},
 		},
+		PullRequest: &config.TemplateBinding{
+			Template: appCIPRTemplateName,
+			Bindings: []string{r.PRBindingName()},
+		},
+	}
+}
+
+// servicePipelines prefixes the environment's bindings with the service's
+// image repository binding.
+func servicePipelines(envPipelines *config.Pipelines, imageRepoBinding string) *config.Pipelines {
+	p := &config.Pipelines{
+		Integration: &config.TemplateBinding{
+			Bindings: append([]string{imageRepoBinding}, envPipelines.Integration.Bindings...),
+		},
+	}
+	if envPipelines.PullRequest != nil {
+		p.PullRequest = &config.TemplateBinding{
+			Bindings: append([]string{imageRepoBinding}, envPipelines.PullRequest.Bindings...),
+		}
 	}
+	return p
 }
 
 // Checks whether the pipelines.yaml is present in the output path specified.
@@ -539,22 +571,40 @@ func createCICDResources(fs afero.Fs, repo scm.Repository, pipelineConfig *confi
 	}
 
 	outputs[rolebindingsPath] = roles.CreateClusterRoleBinding(meta.NamespacedName("", roleBindingName), sa, "ClusterRole", roles.ClusterRoleName)
-	script, err := dryrun.MakeScript("kubectl", cicdNamespace)
+	script, err := dryrun.MakeScript("kubectl", cicdNamespace, tasks.SummaryPath)
 	if err != nil {
 		return nil, otherOutputs, err
 	}
 	outputs[gitopsTasksPath] = tasks.CreateDeployFromSourceTask(cicdNamespace, script)
-	// currently, the commit status task doesn't support enterprise repository
-	// enable it by default once the status task supports enterprise repository
-	if o.PrivateRepoDriver == "" {
-		outputs[commitStatusTaskPath] = tasks.CreateCommitStatusTask(cicdNamespace)
+	driver, err := scm.GetDriverName(o.GitOpsRepoURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	host, err := gitHost(o.GitOpsRepoURL, driver)
+	if err != nil {
+		return nil, nil, err
 	}
-	outputs[ciPipelinesPath] = removeCommitStatus(pipelines.CreateCIPipeline(meta.NamespacedName(cicdNamespace, "ci-dryrun-from-push-pipeline"), cicdNamespace), o.PrivateRepoDriver)
-	outputs[appCiPipelinesPath] = removeCommitStatus(pipelines.CreateAppCIPipeline(meta.NamespacedName(cicdNamespace, "app-ci-pipeline")), o.PrivateRepoDriver)
+	outputs[commitStatusTaskPath] = tasks.CreateCommitStatusTask(cicdNamespace, host)
+	outputs[prCommentTaskPath] = tasks.CreatePRCommentTask(cicdNamespace, host)
+	outputs[ciPipelinesPath] = pipelines.CreateCIPipeline(meta.NamespacedName(cicdNamespace, "ci-dryrun-from-push-pipeline"), cicdNamespace)
+	outputs[ciPRPipelinesPath] = pipelines.CreateCIPRPipeline(meta.NamespacedName(cicdNamespace, "ci-dryrun-from-pr-pipeline"), driver)
+	gitOpsCloneURL, err := scm.HTTPCloneURL(o.GitOpsRepoURL, driver)
+	if err != nil {
+		return nil, nil, err
+	}
+	imageUpdate := &pipelines.ImageUpdate{GitOpsRepoURL: gitOpsCloneURL, Driver: driver, PullRequest: o.ImageUpdatePullRequest}
+	outputs[updateImageTaskPath] = tasks.CreateUpdateImageTask(cicdNamespace, host)
+	outputs[appCiPipelinesPath] = pipelines.CreateAppCIPipeline(meta.NamespacedName(cicdNamespace, "app-ci-pipeline"), imageUpdate, nil, nil, "")
+	outputs[buildImageTaskPath] = tasks.CreateBuildImageTask(cicdNamespace)
+	outputs[appCIPRPipelinesPath] = pipelines.CreateAppCIPRPipeline(meta.NamespacedName(cicdNamespace, "app-ci-pr-pipeline"))
 	pushBinding, pushBindingName := repo.CreatePushBinding(cicdNamespace)
 	outputs[filepath.ToSlash(filepath.Join("05-bindings", pushBindingName+".yaml"))] = pushBinding
+	prBinding, prBindingName := repo.CreatePRBinding(cicdNamespace)
+	outputs[filepath.ToSlash(filepath.Join("05-bindings", prBindingName+".yaml"))] = prBinding
 	outputs[pushTemplatePath] = triggers.CreateCIDryRunTemplate(cicdNamespace, saName)
+	outputs[prTemplatePath] = triggers.CreateCIDryRunPRTemplate(cicdNamespace, saName)
 	outputs[appCIPushTemplatePath] = triggers.CreateDevCIBuildPRTemplate(cicdNamespace, saName)
+	outputs[appCIPRTemplatePath] = triggers.CreateAppCIPRTemplate(cicdNamespace, saName)
 	outputs[eventListenerPath], err = eventlisteners.Generate(repo, cicdNamespace, saName, eventlisteners.GitOpsWebhookSecret)
 	if err != nil {
 		return nil, nil, err
@@ -633,11 +683,18 @@ func generateSecrets(outputs res.Resources, otherOutputs res.Resources, sa *core
 	return nil
 }
 
-// remove the commit status task and it's dependency
-func removeCommitStatus(pipeline *pipelinev1.Pipeline, driver string) *pipelinev1.Pipeline {
-	if driver == "" {
-		return pipeline
+// gitHost returns the driver and API of the host of the repository, for the
+// tasks that report to it.
+func gitHost(repoURL, driver string) (tasks.GitHost, error) {
+	apiURL, err := scm.APIURL(repoURL, driver)
+	if err != nil {
+		return tasks.GitHost{}, err
 	}
+	return tasks.GitHost{Driver: driver, APIURL: apiURL}, nil
+}
+
+// stripCommitStatus removes the commit status tasks and their dependencies.
+func stripCommitStatus(pipeline *pipelinev1.Pipeline) *pipelinev1.Pipeline {
 	pipeline.Spec.Finally = nil
 	tasks := []pipelinev1.PipelineTask{}
 	for _, task := range pipeline.Spec.Tasks {
@@ -6,19 +6,26 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/jenkins-x/go-scm/scm/factory"
 	"github.com/redhat-developer/kam/pkg/pipelines/argocd"
 	"github.com/redhat-developer/kam/pkg/pipelines/config"
 	"github.com/redhat-developer/kam/pkg/pipelines/deployment"
 	"github.com/redhat-developer/kam/pkg/pipelines/eventlisteners"
 	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
 	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+	"github.com/redhat-developer/kam/pkg/pipelines/pipelines"
 	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
 	"github.com/redhat-developer/kam/pkg/pipelines/roles"
 	"github.com/redhat-developer/kam/pkg/pipelines/routes"
 	"github.com/redhat-developer/kam/pkg/pipelines/scm"
 	"github.com/redhat-developer/kam/pkg/pipelines/secrets"
+	"github.com/redhat-developer/kam/pkg/pipelines/tasks"
+	"github.com/redhat-developer/kam/pkg/pipelines/tektonapi"
+	"github.com/spf13/afero"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
 )
 
 const (
@@ -87,6 +94,10 @@ func TestBootstrapManifest(t *testing.T) {
 							Template: "app-ci-template",
 							Bindings: []string{"github-push-binding"},
 						},
+						PullRequest: &config.TemplateBinding{
+							Template: "app-ci-pr-template",
+							Bindings: []string{"github-pr-binding"},
+						},
 					},
 					Name: "tst-dev",
 
@@ -105,6 +116,7 @@ func TestBootstrapManifest(t *testing.T) {
 									},
 									Pipelines: &config.Pipelines{
 										Integration: &config.TemplateBinding{Bindings: []string{"tst-dev-app-http-api-http-api-binding", "github-push-binding"}},
+										PullRequest: &config.TemplateBinding{Bindings: []string{"tst-dev-app-http-api-http-api-binding", "github-pr-binding"}},
 									},
 								},
 							},
@@ -134,13 +146,21 @@ func TestBootstrapManifest(t *testing.T) {
 		"02-rolebindings/pipeline-service-account.yaml",
 		"02-rolebindings/pipeline-service-role.yaml",
 		"02-rolebindings/pipeline-service-rolebinding.yaml",
+		"03-tasks/build-image-without-push-task.yaml",
 		"03-tasks/deploy-from-source-task.yaml",
+		"03-tasks/post-pr-comment-task.yaml",
 		"03-tasks/set-commit-status-task.yaml",
+		"03-tasks/update-gitops-image-task.yaml",
 		"04-pipelines/app-ci-pipeline.yaml",
+		"04-pipelines/app-ci-pr-pipeline.yaml",
+		"04-pipelines/ci-dryrun-from-pr-pipeline.yaml",
 		"04-pipelines/ci-dryrun-from-push-pipeline.yaml",
+		"05-bindings/github-pr-binding.yaml",
 		"05-bindings/github-push-binding.yaml",
 		"05-bindings/tst-dev-app-http-api-http-api-binding.yaml",
+		"06-templates/app-ci-build-from-pr-template.yaml",
 		"06-templates/app-ci-build-from-push-template.yaml",
+		"06-templates/ci-dryrun-from-pr-template.yaml",
 		"06-templates/ci-dryrun-from-push-template.yaml",
 		"07-eventlisteners/cicd-event-listener.yaml",
 		"08-routes/gitops-webhook-event-listener.yaml",
@@ -151,6 +171,26 @@ func TestBootstrapManifest(t *testing.T) {
 	}
 }
 
+func TestBootstrapManifestWithImageUpdatePullRequest(t *testing.T) {
+	params := &BootstrapOptions{
+		Prefix:                 "tst-",
+		GitOpsRepoURL:          testGitOpsRepo,
+		ImageRepo:              "image/repo",
+		GitOpsWebhookSecret:    "123",
+		GitHostAccessToken:     "test-token",
+		ServiceRepoURL:         testSvcRepo,
+		ServiceWebhookSecret:   "456",
+		ImageUpdatePullRequest: true,
+	}
+	r, _, err := bootstrapResources(params, ioutils.NewMemoryFilesystem())
+	fatalIfError(t, err)
+
+	m := r[pipelinesFile].(*config.Manifest)
+	if !m.Config.Pipelines.ImageUpdatePullRequest {
+		t.Fatal("the manifest doesn't record that images are updated with pull requests")
+	}
+}
+
 func TestBootstrapCreatesRepository(t *testing.T) {
 	params := &BootstrapOptions{
 		Prefix:               "tst-",
@@ -165,12 +205,95 @@ func TestBootstrapCreatesRepository(t *testing.T) {
 	fatalIfError(t, err)
 }
 
-func TestOrgRepoFromURL(t *testing.T) {
-	want := "my-org/gitops"
-	got, err := orgRepoFromURL(testGitOpsRepo)
+func TestBootstrapWithTektonV1(t *testing.T) {
+	fakeFs := ioutils.NewMemoryFilesystem()
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        testGitOpsRepo,
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		GitHostAccessToken:   "test-token",
+		ServiceRepoURL:       testSvcRepo,
+		ServiceWebhookSecret: "456",
+		OutputPath:           "/gitops",
+		TektonAPIVersion:     config.TektonV1,
+	}
+	err := Bootstrap(params, fakeFs)
+	fatalIfError(t, err)
+
+	m, err := config.LoadManifest(fakeFs, "/gitops")
 	fatalIfError(t, err)
-	if got != want {
-		t.Fatalf("orgRepFromURL(%s) got %s, want %s", testGitOpsRepo, got, want)
+	if v := m.GetPipelinesConfig().TektonAPIVersion; v != config.TektonV1 {
+		t.Fatalf("got Tekton API version %q, want %q", v, config.TektonV1)
+	}
+
+	wantVersions := map[string]string{
+		"config/tst-cicd/base/03-tasks/deploy-from-source-task.yaml":             tektonapi.PipelineV1,
+		"config/tst-cicd/base/04-pipelines/app-ci-pipeline.yaml":                 tektonapi.PipelineV1,
+		"config/tst-cicd/base/06-templates/app-ci-build-from-push-template.yaml": tektonapi.TriggersV1Beta1,
+		"config/tst-cicd/base/07-eventlisteners/cicd-event-listener.yaml":        tektonapi.TriggersV1Beta1,
+	}
+	for filename, want := range wantVersions {
+		data, err := afero.ReadFile(fakeFs, filepath.Join("/gitops", filename))
+		fatalIfError(t, err)
+		var obj struct {
+			APIVersion string `json:"apiVersion"`
+		}
+		fatalIfError(t, yaml.Unmarshal(data, &obj))
+		if obj.APIVersion != want {
+			t.Errorf("%s got apiVersion %q, want %q", filename, obj.APIVersion, want)
+		}
+	}
+}
+
+func TestBootstrapWithPrivateRepoDriver(t *testing.T) {
+	defer func(id factory.HostDriverIdentifier) { factory.DefaultIdentifier = id }(factory.DefaultIdentifier)
+	factory.DefaultIdentifier = factory.NewDriverIdentifier(factory.Mapping("gitlab.example.com", "gitlab"))
+	params := &BootstrapOptions{
+		Prefix:               "tst-",
+		GitOpsRepoURL:        "https://gitlab.example.com/my-org/gitops.git",
+		ImageRepo:            "image/repo",
+		GitOpsWebhookSecret:  "123",
+		ServiceRepoURL:       "https://gitlab.example.com/my-org/http-api.git",
+		ServiceWebhookSecret: "456",
+		PrivateRepoDriver:    "gitlab",
+	}
+	r, _, err := bootstrapResources(params, ioutils.NewMemoryFilesystem())
+	fatalIfError(t, err)
+
+	host := tasks.GitHost{Driver: "gitlab", APIURL: "https://gitlab.example.com/api/v4"}
+	want := res.Resources{
+		"config/tst-cicd/base/03-tasks/set-commit-status-task.yaml":   tasks.CreateCommitStatusTask("tst-cicd", host),
+		"config/tst-cicd/base/03-tasks/post-pr-comment-task.yaml":     tasks.CreatePRCommentTask("tst-cicd", host),
+		"config/tst-cicd/base/03-tasks/update-gitops-image-task.yaml": tasks.CreateUpdateImageTask("tst-cicd", host),
+	}
+	if diff := cmp.Diff(want, r, cmpopts.IgnoreMapEntries(func(k string, v interface{}) bool {
+		_, ok := want[k]
+		return !ok
+	})); diff != "" {
+		t.Fatalf("tasks failed:\n%s", diff)
+	}
+	p, ok := r["config/tst-cicd/base/04-pipelines/app-ci-pipeline.yaml"].(*pipelinev1.Pipeline)
+	if !ok || p.Spec.Tasks[0].Name != pipelines.PendingCommitStatusTask {
+		t.Fatalf("app-ci-pipeline does not report the commit status: %#v", r["config/tst-cicd/base/04-pipelines/app-ci-pipeline.yaml"])
+	}
+}
+
+func TestOrgRepoFromURL(t *testing.T) {
+	urlTests := []struct {
+		url  string
+		want string
+	}{
+		{testGitOpsRepo, "my-org/gitops"},
+		{"https://gitlab.com/group/subgroup/team/gitops.git", "group/subgroup/team/gitops"},
+	}
+
+	for _, tt := range urlTests {
+		got, err := orgRepoFromURL(tt.url)
+		fatalIfError(t, err)
+		if got != tt.want {
+			t.Fatalf("orgRepFromURL(%s) got %s, want %s", tt.url, got, tt.want)
+		}
 	}
 }
 
@@ -261,7 +384,7 @@ func TestInitialFiles(t *testing.T) {
 	prefix := "tst-"
 	gitOpsURL := "https://github.com/foo/test-repo"
 	gitOpsWebhook := "123"
-	o := BootstrapOptions{Prefix: prefix, GitOpsWebhookSecret: gitOpsWebhook, DockerConfigJSONFilename: ""}
+	o := BootstrapOptions{Prefix: prefix, GitOpsRepoURL: gitOpsURL, GitOpsWebhookSecret: gitOpsWebhook, DockerConfigJSONFilename: ""}
 
 	fakeFs := ioutils.NewMemoryFilesystem()
 	repo, err := scm.NewRepository(gitOpsURL)
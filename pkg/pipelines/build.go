@@ -1,10 +1,13 @@
 package pipelines
 
 import (
+	"path/filepath"
+
 	"github.com/redhat-developer/kam/pkg/pipelines/argocd"
 	"github.com/redhat-developer/kam/pkg/pipelines/config"
 	"github.com/redhat-developer/kam/pkg/pipelines/environments"
 	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+	"github.com/redhat-developer/kam/pkg/pipelines/tektonapi"
 	"github.com/redhat-developer/kam/pkg/pipelines/yaml"
 	"github.com/spf13/afero"
 )
@@ -26,8 +29,26 @@ func BuildResources(o *BuildParameters, appFs afero.Fs) error {
 	if err != nil {
 		return err
 	}
-	_, err = yaml.WriteResources(appFs, o.OutputPath, resources)
-	return err
+	migrated, err := migrateTektonResources(appFs, o.OutputPath, m)
+	if err != nil {
+		return err
+	}
+	resources = res.Merge(migrated, resources)
+	upgraded, err := upgradeTektonResources(appFs, o.OutputPath, m, resources)
+	if err != nil {
+		return err
+	}
+	resources, err = tektonapi.ConvertResources(res.Merge(resources, upgraded), m.GetPipelinesConfig().GetTektonAPIVersion())
+	if err != nil {
+		return err
+	}
+	if _, err = yaml.WriteResources(appFs, o.OutputPath, resources); err != nil {
+		return err
+	}
+	if m.GetPipelinesConfig() != nil && m.GitOpsURL != "" {
+		return updateKustomization(appFs, filepath.Join(o.OutputPath, pipelinesPath(m.Config)))
+	}
+	return nil
 }
 
 func buildResources(fs afero.Fs, m *config.Manifest) (res.Resources, error) {
@@ -56,5 +77,5 @@ func buildResources(fs afero.Fs, m *config.Manifest) (res.Resources, error) {
 		return nil, err
 	}
 	resources = res.Merge(argoApps, resources)
-	return resources, nil
+	return tektonapi.ConvertResources(resources, m.GetPipelinesConfig().GetTektonAPIVersion())
 }
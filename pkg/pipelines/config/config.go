@@ -103,10 +103,13 @@ func (m *Manifest) GetArgoCDConfig() *ArgoCDConfig {
 
 // Environment is a slice of Apps, these are the named apps in the namespace.
 //
+// Test configures the tests that are run for the Services in the Environment
+// that don't configure their own.
 type Environment struct {
 	Name      string         `json:"name,omitempty"`
 	Cluster   string         `json:"cluster,omitempty"`
 	Pipelines *Pipelines     `json:"pipelines,omitempty"`
+	Test      *Test          `json:"test,omitempty"`
 	Apps      []*Application `json:"apps,omitempty"`
 }
 
@@ -117,14 +120,87 @@ type Config struct {
 	Git       *GitConfig       `json:"git,omitempty"`
 }
 
+// The supported values for the TektonAPIVersion of the PipelinesConfig.
+const (
+	// TektonV1Beta1 generates tekton.dev/v1beta1 Pipelines and Tasks, and
+	// triggers.tekton.dev/v1alpha1 Triggers resources, this is the default.
+	TektonV1Beta1 = "v1beta1"
+
+	// TektonV1 generates tekton.dev/v1 Pipelines and Tasks, and
+	// triggers.tekton.dev/v1beta1 Triggers resources.
+	TektonV1 = "v1"
+)
+
 // PipelinesConfig provides configuration for the CI/CD pipelines.
 type PipelinesConfig struct {
 	Name string `json:"name,omitempty"`
+	// TektonAPIVersion selects the versions of the Tekton APIs that the
+	// generated resources are written with.
+	TektonAPIVersion string `json:"tekton_api_version,omitempty"`
+	// ImageUpdatePullRequest updates the images built by the integration
+	// pipelines in the GitOps repository with pull requests rather than
+	// pushes.
+	ImageUpdatePullRequest bool `json:"image_update_pull_request,omitempty"`
+	// Notifications are sent when the integration pipelines of the services,
+	// and the dry-run pipelines of the GitOps repository finish.
+	Notifications []*Notification `json:"notifications,omitempty"`
+	// ConsoleURL is the URL of the OpenShift console, the notifications link
+	// to the PipelineRuns in it.
+	ConsoleURL string `json:"console_url,omitempty"`
+}
+
+// DefaultNotificationSecretKey is the key of the notification secret with the
+// URL of the endpoint, if no key is configured.
+const DefaultNotificationSecretKey = "url"
+
+// Notification is an endpoint that a JSON message is posted to when a
+// pipeline finishes, e.g. the incoming webhook of a chat integration.
+//
+// The URL of the endpoint is read from the secret in the CI/CD namespace, as
+// it usually contains a token.
+//
+// If OnlyFailures is true, the message is only sent when the pipeline fails.
+type Notification struct {
+	Name         string `json:"name,omitempty"`
+	SecretName   string `json:"secret_name,omitempty"`
+	SecretKey    string `json:"secret_key,omitempty"`
+	OnlyFailures bool   `json:"only_failures,omitempty"`
+}
+
+// GetSecretKey returns the configured SecretKey, or
+// DefaultNotificationSecretKey if none is configured.
+func (n *Notification) GetSecretKey() string {
+	if n.SecretKey == "" {
+		return DefaultNotificationSecretKey
+	}
+	return n.SecretKey
+}
+
+// GetTektonAPIVersion returns the configured TektonAPIVersion, or
+// TektonV1Beta1 if none is configured.
+func (p *PipelinesConfig) GetTektonAPIVersion() string {
+	if p == nil || p.TektonAPIVersion == "" {
+		return TektonV1Beta1
+	}
+	return p.TektonAPIVersion
 }
 
 // ArgoCDConfig provides configuration for the ArgoCD application generation.
 type ArgoCDConfig struct {
 	Namespace string `json:"namespace,omitempty"`
+	// AppOfApps generates a parent Application for each environment, which
+	// syncs the environment's Applications from their own folder.
+	AppOfApps bool `json:"app_of_apps,omitempty"`
+	// SSH keeps the SSH URLs of the repositories in the Applications, by
+	// default they are converted to HTTPS URLs, Argo CD must be configured
+	// with SSH credentials for the repositories.
+	SSH bool `json:"ssh,omitempty"`
+	// RBACDefaultPolicy, RBACPolicy and RBACScopes configure the RBAC of the
+	// Argo CD instance, by default users have the role:readonly role, and
+	// their groups are used for the policy.
+	RBACDefaultPolicy string `json:"rbac_default_policy,omitempty"`
+	RBACPolicy        string `json:"rbac_policy,omitempty"`
+	RBACScopes        string `json:"rbac_scopes,omitempty"`
 }
 
 // GitConfig configures the git drivers.
@@ -140,18 +216,102 @@ func (e Environment) GoString() string {
 // Application has many services.
 // The ConfigRepo indicates that the configuration for this application lives in
 // another repository.
+//
+// DependsOn names other applications in the same environment that must be
+// synced before this application.
 type Application struct {
 	Name       string      `json:"name,omitempty"`
 	Services   []*Service  `json:"services,omitempty"`
 	ConfigRepo *Repository `json:"config_repo,omitempty"`
+	DependsOn  []string    `json:"depends_on,omitempty"`
 }
 
 // Service has an upstream source.
+//
+// DependsOn names other services in the same application whose resources must
+// be synced before the resources of this service.
+//
+// Build configures how the image is built by the integration pipeline, if it
+// is not provided, the image is built from the Dockerfile in the root of the
+// source with buildah.
+//
+// Test configures the tests that are run by the integration pipeline before
+// the image is built, this overrides the Test of the Environment.
+//
+// ContextDir is the directory of the service in its source repository,
+// services can only share a source repository if they are in different
+// directories. Pushes only trigger the integration pipeline if they change
+// files in the directory, and the image is built and the tests are run in it.
 type Service struct {
-	Name      string     `json:"name,omitempty"`
-	Webhook   *Webhook   `json:"webhook,omitempty"`
-	SourceURL string     `json:"source_url,omitempty"`
-	Pipelines *Pipelines `json:"pipelines,omitempty"`
+	Name       string     `json:"name,omitempty"`
+	Webhook    *Webhook   `json:"webhook,omitempty"`
+	SourceURL  string     `json:"source_url,omitempty"`
+	ContextDir string     `json:"context_dir,omitempty"`
+	Pipelines  *Pipelines `json:"pipelines,omitempty"`
+	DependsOn  []string   `json:"depends_on,omitempty"`
+	Build      *Build     `json:"build,omitempty"`
+	Test       *Test      `json:"test,omitempty"`
+}
+
+// The supported strategies for building the image of a Service.
+const (
+	// BuildStrategyBuildah builds the image from a Dockerfile with the buildah
+	// ClusterTask, this is the default.
+	BuildStrategyBuildah = "buildah"
+
+	// BuildStrategyS2I builds the image from the source with the s2i
+	// ClusterTask and a builder image.
+	BuildStrategyS2I = "s2i"
+
+	// BuildStrategyBuildpacks builds the image with the buildpacks Task that
+	// is generated in the CI/CD namespace, and a Cloud Native Buildpacks
+	// builder image.
+	BuildStrategyBuildpacks = "buildpacks"
+
+	// BuildStrategyCustom builds the image with a Task in the CI/CD namespace.
+	BuildStrategyCustom = "custom"
+)
+
+// Build configures the strategy for building the image of a Service, and the
+// parameters for the strategy.
+//
+// Task is the name of the Task for the custom strategy, it is passed the
+// IMAGE to build, and any of the other fields that are set as parameters.
+//
+// Params are passed as additional parameters to the build task.
+type Build struct {
+	Strategy     string            `json:"strategy,omitempty"`
+	BuilderImage string            `json:"builder_image,omitempty"`
+	Dockerfile   string            `json:"dockerfile,omitempty"`
+	ContextDir   string            `json:"context_dir,omitempty"`
+	ExtraArgs    string            `json:"extra_args,omitempty"`
+	Task         string            `json:"task,omitempty"`
+	Params       map[string]string `json:"params,omitempty"`
+}
+
+// GetStrategy returns the configured strategy, or BuildStrategyBuildah if
+// none is configured.
+func (b *Build) GetStrategy() string {
+	if b == nil || b.Strategy == "" {
+		return BuildStrategyBuildah
+	}
+	return b.Strategy
+}
+
+// Test configures a stage of the integration pipeline that runs the tests of
+// a Service in its cloned source, the image is only built and pushed if the
+// tests pass.
+//
+// The tests are either run with Command in a container from Image, or by a
+// Task in the CI/CD namespace, which is passed the source workspace.
+//
+// JUnitPath is the path of a JUnit report in the source written by Command,
+// the report is saved as the "junit" result of the stage.
+type Test struct {
+	Image     string `json:"image,omitempty"`
+	Command   string `json:"command,omitempty"`
+	JUnitPath string `json:"junit_path,omitempty"`
+	Task      string `json:"task,omitempty"`
 }
 
 // Webhook provides Github webhook secret for eventlisteners
@@ -173,13 +333,40 @@ type Repository struct {
 	TargetRevision string `json:"target_revision,omitempty"`
 	// Path is a directory path within the Git repository.
 	Path string `json:"path,omitempty"`
+	// Helm configures the Helm chart that is deployed from the repository.
+	Helm *HelmChart `json:"helm,omitempty"`
+}
+
+// HelmChart describes a Helm chart, either from a Helm chart repository, or
+// at the path within a Git repository.
+type HelmChart struct {
+	// Chart is the name of the chart in a Helm chart repository.
+	Chart string `json:"chart,omitempty"`
+	// Version is the version of the chart in the Helm chart repository.
+	Version string `json:"version,omitempty"`
+	// ValueFiles are the Helm value files to use when templating the chart.
+	ValueFiles []string `json:"value_files,omitempty"`
+	// Values are inline Helm values, as a block of YAML.
+	Values string `json:"values,omitempty"`
 }
 
 // Pipelines describes the names for pipelines to be executed for CI and CD.
 //
 // These pipelines will be executed with a Git clone URL and commit SHA.
+//
+// Branches and Tags restrict the pushes that trigger the integration pipeline
+// to the branches and tags that match one of their patterns, a "*" in a
+// pattern matches any characters. If neither is provided, pushes to all
+// branches trigger the pipeline, and pushes to tags, except on GitLab, which
+// sends separate tag push events that are only matched if Tags are provided.
+//
+// Branches and Tags can be provided without Integration, the integration
+// pipeline is then inherited from the Environment, or the defaults.
 type Pipelines struct {
 	Integration *TemplateBinding `json:"integration,omitempty"`
+	PullRequest *TemplateBinding `json:"pull_request,omitempty"`
+	Branches    []string         `json:"branches,omitempty"`
+	Tags        []string         `json:"tags,omitempty"`
 }
 
 // TemplateBinding is a combination of the template and binding to be used for a
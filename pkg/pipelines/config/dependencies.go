@@ -0,0 +1,112 @@
+package config
+
+import "sort"
+
+// SyncWaveAnnotation orders the sync of resources within an ArgoCD
+// Application, and of Applications within a parent Application.
+const SyncWaveAnnotation = "argocd.argoproj.io/sync-wave"
+
+// ApplicationSyncWaves returns the sync-wave for each application in the
+// environment, an application is synced in a later wave than all the
+// applications that it depends on.
+func ApplicationSyncWaves(env *Environment) map[string]int {
+	deps := map[string][]string{}
+	for _, app := range env.Apps {
+		deps[app.Name] = app.DependsOn
+	}
+	return syncWaves(deps)
+}
+
+// ServiceSyncWaves returns the sync-wave for each service in the application,
+// a service is synced in a later wave than all the services that it depends
+// on.
+func ServiceSyncWaves(app *Application) map[string]int {
+	deps := map[string][]string{}
+	for _, svc := range app.Services {
+		deps[svc.Name] = svc.DependsOn
+	}
+	return syncWaves(deps)
+}
+
+// syncWaves calculates the wave of each node as the length of the longest
+// chain of dependencies from it, nodes without dependencies are in wave 0.
+//
+// Unknown references and cycles are reported by validation, and are ignored
+// here.
+func syncWaves(deps map[string][]string) map[string]int {
+	waves := map[string]int{}
+	visiting := map[string]bool{}
+	var wave func(string) int
+	wave = func(name string) int {
+		if w, ok := waves[name]; ok {
+			return w
+		}
+		if visiting[name] {
+			return 0
+		}
+		visiting[name] = true
+		w := 0
+		for _, d := range deps[name] {
+			if _, ok := deps[d]; !ok {
+				continue
+			}
+			if dw := wave(d) + 1; dw > w {
+				w = dw
+			}
+		}
+		visiting[name] = false
+		waves[name] = w
+		return w
+	}
+	for name := range deps {
+		wave(name)
+	}
+	return waves
+}
+
+// findCycle returns the names that form a dependency cycle, starting and ending
+// with the same name, or nil if there are no cycles.
+func findCycle(deps map[string][]string) []string {
+	names := []string{}
+	for name := range deps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	done := map[string]bool{}
+	var path []string
+	onPath := map[string]bool{}
+	var visit func(string) []string
+	visit = func(name string) []string {
+		if done[name] {
+			return nil
+		}
+		if onPath[name] {
+			for i := range path {
+				if path[i] == name {
+					return append(append([]string{}, path[i:]...), name)
+				}
+			}
+		}
+		onPath[name] = true
+		path = append(path, name)
+		for _, d := range deps[name] {
+			if _, ok := deps[d]; !ok {
+				continue
+			}
+			if cycle := visit(d); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		onPath[name] = false
+		done[name] = true
+		return nil
+	}
+	for _, name := range names {
+		if cycle := visit(name); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestApplicationSyncWaves(t *testing.T) {
+	env := &Environment{
+		Name: "dev",
+		Apps: []*Application{
+			{Name: "frontend", DependsOn: []string{"api"}},
+			{Name: "api", DependsOn: []string{"database", "cache"}},
+			{Name: "database"},
+			{Name: "cache", DependsOn: []string{"database"}},
+			{Name: "docs", DependsOn: []string{"unknown"}},
+		},
+	}
+
+	want := map[string]int{"frontend": 3, "api": 2, "cache": 1, "database": 0, "docs": 0}
+	if diff := cmp.Diff(want, ApplicationSyncWaves(env)); diff != "" {
+		t.Fatalf("ApplicationSyncWaves() failed:\n%s", diff)
+	}
+}
+
+func TestServiceSyncWaves(t *testing.T) {
+	app := &Application{
+		Name: "taxi",
+		Services: []*Service{
+			{Name: "api", DependsOn: []string{"database"}},
+			{Name: "database"},
+		},
+	}
+
+	want := map[string]int{"api": 1, "database": 0}
+	if diff := cmp.Diff(want, ServiceSyncWaves(app)); diff != "" {
+		t.Fatalf("ServiceSyncWaves() failed:\n%s", diff)
+	}
+}
+
+func TestSyncWavesWithCycle(t *testing.T) {
+	deps := map[string][]string{"a": {"b"}, "b": {"a"}, "c": {"a"}}
+
+	waves := syncWaves(deps)
+	if len(waves) != 3 {
+		t.Fatalf("syncWaves() got %v", waves)
+	}
+}
+
+func TestFindCycle(t *testing.T) {
+	cycleTests := []struct {
+		desc string
+		deps map[string][]string
+		want []string
+	}{
+		{"no dependencies", map[string][]string{"a": nil, "b": nil}, nil},
+		{"no cycle", map[string][]string{"a": {"b", "c"}, "b": {"c"}, "c": nil}, nil},
+		{"unknown dependency", map[string][]string{"a": {"z"}}, nil},
+		{"self dependency", map[string][]string{"a": {"a"}}, []string{"a", "a"}},
+		{"cycle", map[string][]string{"a": {"b"}, "b": {"c"}, "c": {"b"}}, []string{"b", "c", "b"}},
+	}
+
+	for _, tt := range cycleTests {
+		t.Run(tt.desc, func(rt *testing.T) {
+			if diff := cmp.Diff(tt.want, findCycle(tt.deps)); diff != "" {
+				rt.Fatalf("findCycle() failed:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/mkmik/multierror"
+	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"knative.dev/pkg/apis"
+)
+
+// ResourceNames returns the names of the resources of the kind in the YAML
+// files in dir and its subdirectories, if dir doesn't exist there are no
+// resources.
+func ResourceNames(fs afero.Fs, dir, kind string) (map[string]bool, error) {
+	names := map[string]bool{}
+	exists, err := afero.DirExists(fs, dir)
+	if err != nil || !exists {
+		return names, err
+	}
+	err = afero.Walk(fs, dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !isYAML(path) {
+			return err
+		}
+		f, err := fs.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		decoder := yaml.NewYAMLOrJSONDecoder(f, 4096)
+		for {
+			var obj struct {
+				Kind     string `json:"kind"`
+				Metadata struct {
+					Name string `json:"name"`
+				} `json:"metadata"`
+			}
+			if err := decoder.Decode(&obj); err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				return fmt.Errorf("failed to parse %s: %w", path, err)
+			}
+			if obj.Kind == kind {
+				names[obj.Metadata.Name] = true
+			}
+		}
+	})
+	return names, err
+}
+
+// validateTemplates checks that the TriggerTemplates referenced by the
+// environments and services exist in the CI/CD configuration in the GitOps
+// repository at path.
+//
+// The references can't be checked until the CI/CD configuration has been
+// written.
+func validateTemplates(fs afero.Fs, path string, m *Manifest) error {
+	cfg := m.GetPipelinesConfig()
+	if cfg == nil {
+		return nil
+	}
+	base := filepath.Join(path, PathForPipelines(cfg), "base")
+	exists, err := afero.DirExists(fs, base)
+	if err != nil || !exists {
+		return err
+	}
+	templates, err := ResourceNames(fs, base, "TriggerTemplate")
+	if err != nil {
+		return err
+	}
+	errs := []error{}
+	check := func(pipelines *Pipelines, path string) {
+		if pipelines == nil {
+			return
+		}
+		if pipelines.Integration != nil {
+			errs = append(errs, missingTemplate(templates, pipelines.Integration.Template, yamlJoin(path, "pipelines", "integration", "template"))...)
+		}
+		if pipelines.PullRequest != nil {
+			errs = append(errs, missingTemplate(templates, pipelines.PullRequest.Template, yamlJoin(path, "pipelines", "pull_request", "template"))...)
+		}
+	}
+	for _, env := range m.Environments {
+		check(env.Pipelines, yamlPath(PathForEnvironment(env)))
+		for _, app := range env.Apps {
+			for _, svc := range app.Services {
+				check(svc.Pipelines, yamlPath(PathForService(app, env, svc.Name)))
+			}
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return multierror.Join(errs)
+}
+
+func missingTemplate(templates map[string]bool, name, path string) []error {
+	if name == "" || templates[name] {
+		return nil
+	}
+	return list(unknownTemplateError(name, []string{path}))
+}
+
+func unknownTemplateError(name string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("unknown TriggerTemplate %q", name),
+		Paths:   paths,
+	}
+}
+
+func isYAML(path string) bool {
+	ext := filepath.Ext(path)
+	return ext == ".yaml" || ext == ".yml"
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
+	"github.com/redhat-developer/kam/pkg/pipelines/yaml"
+	"github.com/redhat-developer/kam/test"
+)
+
+const testTemplates = `apiVersion: triggers.tekton.dev/v1alpha1
+kind: TriggerTemplate
+metadata:
+  name: app-ci-template
+---
+apiVersion: triggers.tekton.dev/v1alpha1
+kind: TriggerBinding
+metadata:
+  name: github-push-binding
+`
+
+func TestResourceNames(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	if err := afero.WriteFile(fs, "/base/06-templates/templates.yaml", []byte(testTemplates), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ResourceNames(fs, "/base", "TriggerTemplate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(map[string]bool{"app-ci-template": true}, got); diff != "" {
+		t.Fatalf("ResourceNames() failed:\n%s", diff)
+	}
+}
+
+func TestLoadManifestValidatesTemplates(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	m := &Manifest{
+		Config: &Config{
+			Pipelines: &PipelinesConfig{Name: "cicd"},
+		},
+		Environments: []*Environment{
+			{
+				Name: "dev",
+				Pipelines: &Pipelines{
+					Integration: &TemplateBinding{Template: "app-ci-template"},
+					PullRequest: &TemplateBinding{Template: "app-ci-pr-template"},
+				},
+			},
+		},
+	}
+	_, err := yaml.WriteResources(fs, "/manifest", map[string]interface{}{
+		"pipelines.yaml": m,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadManifest(fs, "/manifest"); err != nil {
+		t.Fatalf("failed to load manifest without a CI/CD configuration: %v", err)
+	}
+
+	if err := afero.WriteFile(fs, "/manifest/config/cicd/base/06-templates/templates.yaml", []byte(testTemplates), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = LoadManifest(fs, "/manifest")
+	test.AssertErrorMatch(t, `unknown TriggerTemplate "app-ci-pr-template": environments.dev.pipelines.pull_request.template`, err)
+}
@@ -5,6 +5,8 @@ import (
 
 	"github.com/jenkins-x/go-scm/scm/factory"
 	"github.com/spf13/afero"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/scm"
 )
 
 // LoadManifest reads a manifest file, and configures the environment based on
@@ -20,12 +22,15 @@ func LoadManifest(fs afero.Fs, path string) (*Manifest, error) {
 			drivers = append(drivers, factory.Mapping(k, v))
 		}
 		if len(drivers) > 0 {
-			id := factory.NewDriverIdentifier(drivers...)
+			id := scm.NewDriverIdentifier(drivers...)
 			factory.DefaultIdentifier = id
 		}
 	}
 	if err := m.Validate(); err != nil {
 		return nil, err
 	}
+	if err := validateTemplates(fs, path, m); err != nil {
+		return nil, err
+	}
 	return m, nil
 }
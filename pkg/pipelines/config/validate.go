@@ -2,18 +2,24 @@ package config
 
 import (
 	"fmt"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/mkmik/multierror"
 	"github.com/redhat-developer/kam/pkg/pipelines/scm"
 	"k8s.io/apimachinery/pkg/api/validation"
 	"knative.dev/pkg/apis"
+	"sigs.k8s.io/yaml"
 )
 
 const (
 	longServiceName  = "a service name cannot exceed 47 characters"
 	serviceNameLimit = 47
+	// defaultPRTemplate is the TriggerTemplate of the shared pull request
+	// pipeline.
+	defaultPRTemplate = "app-ci-pr-template"
 )
 
 type validateVisitor struct {
@@ -22,6 +28,7 @@ type validateVisitor struct {
 	appNames     map[string]bool
 	serviceNames map[string]bool
 	serviceURLs  map[string][]string
+	contextDirs  map[string][]string
 	configNames  map[string]bool
 }
 
@@ -34,6 +41,7 @@ func (m *Manifest) Validate() error {
 		appNames:     map[string]bool{},
 		serviceNames: map[string]bool{},
 		serviceURLs:  map[string][]string{},
+		contextDirs:  map[string][]string{},
 		configNames:  map[string]bool{},
 	}
 
@@ -73,7 +81,7 @@ func (vv *validateVisitor) validateServiceURLs(gitOpsURL string) []error {
 				errs = append(errs, inconsistentGitTypeError(gitType, url, paths))
 			}
 		}
-		if len(paths) > 1 {
+		if len(paths) > 1 && !uniqueContextDirs(vv.contextDirs[url]) {
 			errs = append(errs, duplicateSourceError(url, paths))
 		}
 	}
@@ -94,6 +102,16 @@ func (vv *validateVisitor) Environment(env *Environment) error {
 	if err := validatePipelines(env.Pipelines, envPath); err != nil {
 		vv.errs = append(vv.errs, err...)
 	}
+	if err := validateTest(env.Test, envPath); err != nil {
+		vv.errs = append(vv.errs, err...)
+	}
+	deps := map[string][]string{}
+	for _, app := range env.Apps {
+		deps[app.Name] = app.DependsOn
+	}
+	if cycle := findCycle(deps); cycle != nil {
+		vv.errs = append(vv.errs, dependencyCycleError(cycle, []string{envPath}))
+	}
 	return nil
 }
 
@@ -124,6 +142,18 @@ func (vv *validateVisitor) Application(env *Environment, app *Application) error
 			}
 		}
 	}
+	for _, name := range app.DependsOn {
+		if !hasApplication(env, name) {
+			vv.errs = append(vv.errs, unknownDependencyError(name, []string{yamlJoin(appPath, "depends_on")}))
+		}
+	}
+	deps := map[string][]string{}
+	for _, svc := range app.Services {
+		deps[svc.Name] = svc.DependsOn
+	}
+	if cycle := findCycle(deps); cycle != nil {
+		vv.errs = append(vv.errs, dependencyCycleError(cycle, []string{appPath}))
+	}
 	return nil
 }
 
@@ -137,6 +167,7 @@ func (vv *validateVisitor) Service(app *Application, env *Environment, svc *Serv
 		}
 		previous = append(previous, svcPath)
 		vv.serviceURLs[svc.SourceURL] = previous
+		vv.contextDirs[svc.SourceURL] = append(vv.contextDirs[svc.SourceURL], svc.ContextDir)
 	}
 	if err := checkDuplicateService(svc.Name, svcPath, svcRelativePath, vv.serviceNames); err != nil {
 		vv.errs = append(vv.errs, err)
@@ -154,22 +185,152 @@ func (vv *validateVisitor) Service(app *Application, env *Environment, svc *Serv
 	if err := validatePipelines(svc.Pipelines, svcPath); err != nil {
 		vv.errs = append(vv.errs, err...)
 	}
+	if err := validateContextDir(svc.ContextDir, yamlJoin(svcPath, "context_dir")); err != nil {
+		vv.errs = append(vv.errs, err)
+	}
+	if err := validateContextDirDriver(svc.SourceURL, svc.ContextDir, yamlJoin(svcPath, "context_dir")); err != nil {
+		vv.errs = append(vv.errs, err)
+	}
+	if err := validateBuild(svc.Build, svcPath); err != nil {
+		vv.errs = append(vv.errs, err...)
+	}
+	if err := validateTest(svc.Test, svcPath); err != nil {
+		vv.errs = append(vv.errs, err...)
+	}
+	test := svc.Test
+	if test == nil {
+		test = env.Test
+	}
+	if err := validatePullRequestTemplate(svc.Pipelines, test, svcPath); err != nil {
+		vv.errs = append(vv.errs, err)
+	}
+	for _, name := range svc.DependsOn {
+		if !hasService(app, name) {
+			vv.errs = append(vv.errs, unknownDependencyError(name, []string{yamlJoin(svcPath, "depends_on")}))
+		}
+	}
 	vv.serviceNames[svc.Name] = true
 	return nil
 }
 
+// uniqueContextDirs returns true if services that share a source repository
+// are all in separate directories, none of which is the root of the
+// repository, or is nested in another, as the pushes to a nested directory
+// would trigger the pipelines of both services.
+func uniqueContextDirs(dirs []string) bool {
+	cleaned := []string{}
+	for _, dir := range dirs {
+		dir = path.Clean(strings.Trim(dir, "/"))
+		if dir == "." {
+			return false
+		}
+		for _, c := range cleaned {
+			if c == dir || strings.HasPrefix(dir, c+"/") || strings.HasPrefix(c, dir+"/") {
+				return false
+			}
+		}
+		cleaned = append(cleaned, dir)
+	}
+	return true
+}
+
+func validateContextDir(dir, fieldPath string) *apis.FieldError {
+	if dir == "" {
+		return nil
+	}
+	if path.IsAbs(dir) || strings.HasPrefix(path.Clean(dir), "..") {
+		return apis.ErrInvalidValue(dir, fieldPath, "must be a relative path in the source repository")
+	}
+	return nil
+}
+
+// validateContextDirDriver rejects context directories for source
+// repositories with push events that don't list the changed files, as the
+// pushes to other directories can't be filtered out, errors from unknown
+// drivers are reported with the service URLs.
+func validateContextDirDriver(sourceURL, dir, fieldPath string) *apis.FieldError {
+	if sourceURL == "" || dir == "" {
+		return nil
+	}
+	lists, err := scm.ListsChangedFiles(sourceURL)
+	if err != nil || lists {
+		return nil
+	}
+	driver, _ := scm.GetDriverName(sourceURL)
+	return apis.ErrGeneric(fmt.Sprintf("context_dir is not supported for %s repositories, their push events don't list the changed files", driver), fieldPath)
+}
+
+// validatePullRequestTemplate rejects the default pull request template for
+// services with tests, the shared pull request pipeline can't know the tests
+// of the services, so it only builds the image, and services with tests have
+// their own pull request pipeline that runs them.
+func validatePullRequestTemplate(pipelines *Pipelines, test *Test, path string) *apis.FieldError {
+	if test == nil || pipelines == nil || pipelines.PullRequest == nil || pipelines.PullRequest.Template != defaultPRTemplate {
+		return nil
+	}
+	return apis.ErrGeneric(fmt.Sprintf("%s doesn't run the tests of the service, remove the template to run them", defaultPRTemplate), yamlJoin(path, "pipelines", "pull_request", "template"))
+}
+
+func hasApplication(env *Environment, name string) bool {
+	for _, app := range env.Apps {
+		if app.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func hasService(app *Application, name string) bool {
+	for _, svc := range app.Services {
+		if svc.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 func validateConfigRepo(repo *Repository, path string) []error {
 	missingFields := []string{}
 	errs := []error{}
 	if repo.URL == "" {
 		missingFields = append(missingFields, "url")
 	}
-	if repo.Path == "" {
+	if repo.Path == "" && (repo.Helm == nil || repo.Helm.Chart == "") {
 		missingFields = append(missingFields, "path")
 	}
 	if len(missingFields) > 0 {
 		errs = append(errs, missingFieldsError(missingFields, []string{path}))
 	}
+	if repo.Helm != nil {
+		errs = append(errs, validateHelmChart(repo, path)...)
+	}
+	return errs
+}
+
+// A chart is either fetched from a Helm chart repository by name and version,
+// or read from the path and target_revision of a Git repository.
+func validateHelmChart(repo *Repository, path string) []error {
+	errs := []error{}
+	helmPath := yamlJoin(path, "helm")
+	if repo.Helm.Chart != "" {
+		if repo.Path != "" {
+			errs = append(errs, apis.ErrMultipleOneOf(yamlJoin(path, "path"), yamlJoin(helmPath, "chart")))
+		}
+		if repo.TargetRevision != "" {
+			errs = append(errs, apis.ErrMultipleOneOf(yamlJoin(path, "target_revision"), yamlJoin(helmPath, "version")))
+		}
+		if repo.Helm.Version == "" {
+			errs = append(errs, missingFieldsError([]string{"version"}, []string{helmPath}))
+		}
+	} else if repo.Helm.Version != "" {
+		errs = append(errs, missingFieldsError([]string{"chart"}, []string{helmPath}))
+	}
+	if repo.Helm.Values != "" {
+		values := map[string]interface{}{}
+		if err := yaml.Unmarshal([]byte(repo.Helm.Values), &values); err != nil {
+			errs = append(errs, apis.ErrInvalidValue(repo.Helm.Values, yamlJoin(helmPath, "values"), err.Error()))
+		}
+	}
 	return errs
 }
 
@@ -190,17 +351,110 @@ func validateWebhook(hook *Webhook, path string) []error {
 	return errs
 }
 
+// The dockerfile and extra_args are only used by buildah, and by custom tasks,
+// while s2i and buildpacks require a builder image.
+func validateBuild(build *Build, path string) []error {
+	if build == nil {
+		return nil
+	}
+	errs := []error{}
+	buildPath := yamlJoin(path, "build")
+	switch strategy := build.GetStrategy(); strategy {
+	case BuildStrategyBuildah:
+	case BuildStrategyS2I, BuildStrategyBuildpacks:
+		if build.BuilderImage == "" {
+			errs = append(errs, missingFieldsError([]string{"builder_image"}, []string{buildPath}))
+		}
+		if build.Dockerfile != "" {
+			errs = append(errs, apis.ErrDisallowedFields(yamlJoin(buildPath, "dockerfile")))
+		}
+		if build.ExtraArgs != "" {
+			errs = append(errs, apis.ErrDisallowedFields(yamlJoin(buildPath, "extra_args")))
+		}
+	case BuildStrategyCustom:
+		if build.Task == "" {
+			errs = append(errs, missingFieldsError([]string{"task"}, []string{buildPath}))
+		}
+	default:
+		errs = append(errs, apis.ErrInvalidValue(strategy, yamlJoin(buildPath, "strategy"),
+			fmt.Sprintf("must be one of %s, %s, %s or %s", BuildStrategyBuildah, BuildStrategyS2I, BuildStrategyBuildpacks, BuildStrategyCustom)))
+	}
+	if build.Task != "" {
+		if build.GetStrategy() != BuildStrategyCustom {
+			errs = append(errs, apis.ErrDisallowedFields(yamlJoin(buildPath, "task")))
+		} else if err := validateName(build.Task, yamlJoin(buildPath, "task")); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func validateTest(test *Test, path string) []error {
+	if test == nil {
+		return nil
+	}
+	errs := []error{}
+	testPath := yamlJoin(path, "test")
+	if test.Task != "" {
+		if test.Image != "" {
+			errs = append(errs, apis.ErrDisallowedFields(yamlJoin(testPath, "image")))
+		}
+		if test.Command != "" {
+			errs = append(errs, apis.ErrDisallowedFields(yamlJoin(testPath, "command")))
+		}
+		if test.JUnitPath != "" {
+			errs = append(errs, apis.ErrDisallowedFields(yamlJoin(testPath, "junit_path")))
+		}
+		if err := validateName(test.Task, yamlJoin(testPath, "task")); err != nil {
+			errs = append(errs, err)
+		}
+		return errs
+	}
+	missing := []string{}
+	if test.Image == "" {
+		missing = append(missing, "image")
+	}
+	if test.Command == "" {
+		missing = append(missing, "command")
+	}
+	if len(missing) > 0 {
+		errs = append(errs, missingFieldsError(missing, []string{testPath}))
+	}
+	return errs
+}
+
 func validatePipelines(pipelines *Pipelines, path string) []error {
 	errs := []error{}
 	if pipelines == nil {
 		return nil
 	}
-	if pipelines.Integration == nil {
+	// The branches and tags can be configured without the integration
+	// pipeline, which is then inherited.
+	if pipelines.Integration == nil && len(pipelines.Branches) == 0 && len(pipelines.Tags) == 0 {
 		return list(missingFieldsError([]string{"integration"}, []string{yamlJoin(path, "pipelines")}))
 	}
-	for _, name := range pipelines.Integration.Bindings {
-		if err := validateName(name, yamlJoin(path, "pipelines", "integration", "binding")); err != nil {
-			errs = append(errs, err)
+	if pipelines.Integration != nil {
+		for _, name := range pipelines.Integration.Bindings {
+			if err := validateName(name, yamlJoin(path, "pipelines", "integration", "binding")); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if pipelines.PullRequest != nil {
+		for _, name := range pipelines.PullRequest.Bindings {
+			if err := validateName(name, yamlJoin(path, "pipelines", "pull_request", "binding")); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	for _, pattern := range pipelines.Branches {
+		if pattern == "" {
+			errs = append(errs, apis.ErrInvalidValue(pattern, yamlJoin(path, "pipelines", "branches"), "patterns must not be empty"))
+		}
+	}
+	for _, pattern := range pipelines.Tags {
+		if pattern == "" {
+			errs = append(errs, apis.ErrInvalidValue(pattern, yamlJoin(path, "pipelines", "tags"), "patterns must not be empty"))
 		}
 	}
 	return errs
@@ -219,11 +473,73 @@ func (vv *validateVisitor) validateConfig(manifest *Manifest) []error {
 				errs = append(errs, err)
 			}
 			vv.configNames[manifest.Config.Pipelines.Name] = true
+			if v := manifest.Config.Pipelines.TektonAPIVersion; v != "" && v != TektonV1Beta1 && v != TektonV1 {
+				errs = append(errs, apis.ErrInvalidValue(v, yamlJoin("config", "pipelines", "tekton_api_version"), fmt.Sprintf("must be one of %s or %s", TektonV1Beta1, TektonV1)))
+			}
+			errs = append(errs, validateNotifications(manifest.Config.Pipelines.Notifications, yamlJoin("config", "pipelines", "notifications"))...)
+		}
+		if manifest.Config.Git != nil {
+			errs = append(errs, validateDrivers(manifest.Config.Git.Drivers, yamlJoin("config", "git", "drivers"))...)
 		}
 	}
 	return errs
 }
 
+// validateDrivers checks that the hosts are mapped to drivers that
+// repositories can be created for.
+func validateDrivers(drivers map[string]string, path string) []error {
+	errs := []error{}
+	hosts := []string{}
+	for host := range drivers {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	supported := scm.SupportedDrivers()
+	for _, host := range hosts {
+		driver := drivers[host]
+		found := false
+		for _, v := range supported {
+			if v == driver {
+				found = true
+				break
+			}
+		}
+		if !found {
+			errs = append(errs, apis.ErrInvalidValue(driver, yamlJoin(path, host), fmt.Sprintf("must be one of %s", strings.Join(supported, ", "))))
+		}
+	}
+	return errs
+}
+
+func validateNotifications(notifications []*Notification, path string) []error {
+	errs := []error{}
+	names := map[string]bool{}
+	for i, n := range notifications {
+		notificationPath := fmt.Sprintf("%s[%d]", path, i)
+		missing := []string{}
+		if n.Name == "" {
+			missing = append(missing, "name")
+		}
+		if n.SecretName == "" {
+			missing = append(missing, "secret_name")
+		}
+		if len(missing) > 0 {
+			errs = append(errs, missingFieldsError(missing, []string{notificationPath}))
+		}
+		if n.Name == "" {
+			continue
+		}
+		if err := validateName(n.Name, yamlJoin(notificationPath, "name")); err != nil {
+			errs = append(errs, err)
+		}
+		if names[n.Name] {
+			errs = append(errs, duplicateFieldsError([]string{n.Name}, []string{notificationPath}))
+		}
+		names[n.Name] = true
+	}
+	return errs
+}
+
 func validateName(name, path string) *apis.FieldError {
 	err := validation.NameIsDNS1035Label(name, true)
 	if len(err) > 0 {
@@ -286,7 +602,21 @@ func missingServiceError(app string, paths []string) *apis.FieldError {
 
 func duplicateSourceError(url string, paths []string) *apis.FieldError {
 	return &apis.FieldError{
-		Message: fmt.Sprintf("duplicate source detected, multiple services cannot share the same source repository: %s", url),
+		Message: fmt.Sprintf("duplicate source detected, multiple services cannot share the same source repository unless they have separate context directories, that are not nested in each other: %s", url),
+		Paths:   paths,
+	}
+}
+
+func unknownDependencyError(name string, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("unknown dependency %q", name),
+		Paths:   paths,
+	}
+}
+
+func dependencyCycleError(cycle, paths []string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("dependency cycle detected: %s", strings.Join(cycle, " -> ")),
 		Paths:   paths,
 	}
 }
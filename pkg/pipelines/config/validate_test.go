@@ -80,6 +80,47 @@ var validateTests = []struct {
 			apis.ErrMultipleOneOf("environments.development.apps.app-5.services", "environments.development.apps.app-5.config_repo"),
 		}),
 	},
+	{
+		"Helm chart errors in config repo",
+		"testdata/helm_chart_error.yaml",
+		multierror.Join([]error{
+			apis.ErrMultipleOneOf("environments.development.apps.app-1.config_repo.path", "environments.development.apps.app-1.config_repo.helm.chart"),
+			apis.ErrMultipleOneOf("environments.development.apps.app-1.config_repo.target_revision", "environments.development.apps.app-1.config_repo.helm.version"),
+			missingFieldsError([]string{"version"}, []string{"environments.development.apps.app-2.config_repo.helm"}),
+			missingFieldsError([]string{"chart"}, []string{"environments.development.apps.app-3.config_repo.helm"}),
+			apis.ErrInvalidValue("replicas: [1", "environments.development.apps.app-3.config_repo.helm.values", "error converting YAML to JSON: yaml: line 1: did not find expected ',' or ']'"),
+		}),
+	},
+	{
+		"Build errors in services",
+		"testdata/build_error.yaml",
+		multierror.Join([]error{
+			apis.ErrInvalidValue("kaniko", "environments.development.apps.app-1.services.service-1.build.strategy", "must be one of buildah, s2i, buildpacks or custom"),
+			missingFieldsError([]string{"builder_image"}, []string{"environments.development.apps.app-1.services.service-2.build"}),
+			apis.ErrDisallowedFields("environments.development.apps.app-1.services.service-2.build.dockerfile"),
+			missingFieldsError([]string{"task"}, []string{"environments.development.apps.app-1.services.service-3.build"}),
+			apis.ErrDisallowedFields("environments.development.apps.app-1.services.service-4.build.task"),
+		}),
+	},
+	{
+		"Test errors in environments and services",
+		"testdata/test_error.yaml",
+		multierror.Join([]error{
+			apis.ErrDisallowedFields("environments.development.apps.app-1.services.service-1.test.command"),
+			missingFieldsError([]string{"image", "command"}, []string{"environments.development.apps.app-1.services.service-2.test"}),
+			missingFieldsError([]string{"command"}, []string{"environments.development.test"}),
+		}),
+	},
+	{
+		"Dependency errors",
+		"testdata/dependency_error.yaml",
+		multierror.Join([]error{
+			dependencyCycleError([]string{"service-1", "service-2", "service-1"}, []string{"environments.development.apps.app-1"}),
+			unknownDependencyError("service-1", []string{"environments.development.apps.app-2.services.service-3.depends_on"}),
+			unknownDependencyError("app-3", []string{"environments.development.apps.app-2.depends_on"}),
+			dependencyCycleError([]string{"app-1", "app-2", "app-1"}, []string{"environments.development"}),
+		}),
+	},
 	{
 		"duplicate environment name error",
 		"testdata/duplicate_environment.yaml",
@@ -120,11 +161,112 @@ var validateTests = []struct {
 			},
 		),
 	},
+	{
+		"services in different directories of a source repository",
+		"testdata/monorepo_services.yaml",
+		nil,
+	},
+	{
+		"services in the same directory of a source repository",
+		"testdata/context_dir_error.yaml",
+		multierror.Join(
+			[]error{
+				apis.ErrInvalidValue("../api", "environments.monorepo.apps.my-app-1.services.app-1-service-api.context_dir", "must be a relative path in the source repository"),
+				duplicateSourceError("https://github.com/testing/testing.git", []string{
+					"environments.monorepo.apps.my-app-1.services.app-1-service-http",
+					"environments.monorepo.apps.my-app-1.services.app-1-service-worker"}),
+			},
+		),
+	},
+	{
+		"services in nested directories of a source repository",
+		"testdata/nested_context_dir_error.yaml",
+		multierror.Join(
+			[]error{
+				duplicateSourceError("https://github.com/testing/testing.git", []string{
+					"environments.monorepo.apps.my-app-1.services.app-1-service-http",
+					"environments.monorepo.apps.my-app-1.services.app-1-service-worker"}),
+			},
+		),
+	},
+	{
+		"services in the root and a directory of a source repository",
+		"testdata/root_context_dir_error.yaml",
+		multierror.Join(
+			[]error{
+				duplicateSourceError("https://github.com/testing/testing.git", []string{
+					"environments.monorepo.apps.my-app-1.services.app-1-service-http",
+					"environments.monorepo.apps.my-app-1.services.app-1-service-worker"}),
+			},
+		),
+	},
+	{
+		"services in directories of a repository without changed files in its push events",
+		"testdata/context_dir_driver_error.yaml",
+		multierror.Join(
+			[]error{
+				apis.ErrGeneric("context_dir is not supported for bitbucket repositories, their push events don't list the changed files", "environments.monorepo.apps.my-app-1.services.app-1-service-http.context_dir"),
+				apis.ErrGeneric("context_dir is not supported for bitbucket repositories, their push events don't list the changed files", "environments.monorepo.apps.my-app-1.services.app-1-service-worker.context_dir"),
+			},
+		),
+	},
+	{
+		"service with tests and the default pull request template",
+		"testdata/pr_template_with_test_error.yaml",
+		multierror.Join(
+			[]error{
+				apis.ErrGeneric("app-ci-pr-template doesn't run the tests of the service, remove the template to run them", "environments.development.apps.my-app-1.services.app-1-service-http.pipelines.pull_request.template"),
+			},
+		),
+	},
 	{
 		"service with pipeline with no template",
 		"testdata/service_with_bindings_no_template.yaml",
 		nil,
 	},
+	{
+		"branch and tag patterns without the integration pipeline",
+		"testdata/ref_patterns.yaml",
+		nil,
+	},
+	{
+		"Empty branch and tag patterns",
+		"testdata/ref_patterns_error.yaml",
+		multierror.Join([]error{
+			apis.ErrInvalidValue("", "environments.development.apps.app-1.services.service-1.pipelines.tags", "patterns must not be empty"),
+			apis.ErrInvalidValue("", "environments.development.pipelines.branches", "patterns must not be empty"),
+		}),
+	},
+	{
+		"unsupported Tekton API version",
+		"testdata/tekton_api_version_error.yaml",
+		multierror.Join(
+			[]error{
+				apis.ErrInvalidValue("v1alpha1", "config.pipelines.tekton_api_version", "must be one of v1beta1 or v1"),
+			},
+		),
+	},
+	{
+		"unsupported Git driver",
+		"testdata/git_driver_error.yaml",
+		multierror.Join(
+			[]error{
+				apis.ErrInvalidValue("gogs", "config.git.drivers.gogs.example.com", "must be one of bitbucket, gitea, github, gitlab, stash"),
+			},
+		),
+	},
+	{
+		"invalid notifications",
+		"testdata/notification_error.yaml",
+		multierror.Join(
+			[]error{
+				duplicateFieldsError([]string{"chat"}, []string{"config.pipelines.notifications[1]"}),
+				missingFieldsError([]string{"name"}, []string{"config.pipelines.notifications[2]"}),
+				missingFieldsError([]string{"secret_name"}, []string{"config.pipelines.notifications[3]"}),
+				invalidNameError("Alerts", "a DNS-1035 label must consist of lower case alphanumeric characters or '-', start with an alphabetic character, and end with an alphanumeric character (e.g. 'my-name',  or 'abc-123', regex used for validation is '[a-z]([-a-z0-9]*[a-z0-9])?')", []string{"config.pipelines.notifications[3].name"}),
+			},
+		),
+	},
 	{
 		"valid manifest file",
 		"testdata/valid_manifest.yaml",
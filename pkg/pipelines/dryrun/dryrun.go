@@ -11,14 +11,39 @@ is_argocd=false
 argo_path="config/argocd"
 cicd_path="config/{{ .CICDEnv }}"
 cmd={{ .Cmd }}
+summary_file="{{ .SummaryPath }}"
 overall_exit=0
 
+if [[ ! -z "${summary_file}" ]]; then
+  : > "${summary_file}"
+fi
+
 execute() {
-  if [[ ! -z "${cmd}" ]]; then $cmd apply --dry-run=$(inputs.params.DRYRUN) -k $1; fi
-  e=$?
+  out=""
+  e=0
+  if [[ ! -z "${cmd}" ]]; then
+    out=$($cmd apply --dry-run=$(params.DRYRUN) -k $1 2>&1)
+    e=$?
+    printf "%s\n" "${out}"
+  fi
   if [ $e -gt $overall_exit ]; then
     overall_exit=$e
   fi
+  summarise "$1" $e "${out}"
+}
+
+summarise() {
+  if [[ -z "${summary_file}" ]]; then
+    return
+  fi
+  if [ $2 -ne 0 ]; then
+    printf -- "- %s: failed, %s\n" "$1" "$(tail -n 1 <<< "$3")" >> "${summary_file}"
+    return
+  fi
+  created=$(grep -c " created" <<< "$3")
+  configured=$(grep -c " configured" <<< "$3")
+  unchanged=$(grep -c " unchanged" <<< "$3")
+  printf -- "- %s: %s created, %s configured, %s unchanged\n" "$1" "${created}" "${configured}" "${unchanged}" >> "${summary_file}"
 }
 
 if [[ -d "${argo_path}" ]]; then
@@ -51,14 +76,18 @@ exit $overall_exit
 `
 
 type templateParam struct {
-	Cmd     string
-	CICDEnv string
+	Cmd         string
+	CICDEnv     string
+	SummaryPath string
 }
 
 // MakeScript will create a script that can dry-run/apply
-// across all environments/applications
-func MakeScript(command, cicdEnv string) (string, error) {
-	params := templateParam{CICDEnv: cicdEnv, Cmd: command}
+// across all environments/applications.
+//
+// If summaryPath is not empty, a line summarising the result for each
+// environment/application is written to it.
+func MakeScript(command, cicdEnv, summaryPath string) (string, error) {
+	params := templateParam{CICDEnv: cicdEnv, Cmd: command, SummaryPath: summaryPath}
 	parsed, err := template.New("dryrun_script").Parse(scriptTemplate)
 	if err != nil {
 		return "", fmt.Errorf("unable to parse template: %v", err)
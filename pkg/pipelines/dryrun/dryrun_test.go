@@ -37,7 +37,7 @@ func TestMakeScriptWithArgo(t *testing.T) {
 
 	fs := ioutils.NewFilesystem()
 	setupGitOpsTree(t, fs, tempDir, true)
-	s, err := MakeScript("", "cicd")
+	s, err := MakeScript("", "cicd", "")
 	assertNoError(t, err)
 
 	want := logsWithArgoCD
@@ -53,7 +53,7 @@ func TestMakeScriptWithoutArgo(t *testing.T) {
 
 	fs := ioutils.NewFilesystem()
 	setupGitOpsTree(t, fs, tempDir, false)
-	s, err := MakeScript("", "cicd")
+	s, err := MakeScript("", "cicd", "")
 	assertNoError(t, err)
 
 	want := logsWithoutArgoCD
@@ -63,10 +63,63 @@ func TestMakeScriptWithoutArgo(t *testing.T) {
 	}
 }
 
+func TestMakeScriptWithSummary(t *testing.T) {
+	tempDir, cleanup := tempDir(t)
+	defer cleanup()
+
+	fs := ioutils.NewFilesystem()
+	setupGitOpsTree(t, fs, tempDir, false)
+	summaryPath := filepath.Join(tempDir, "summary")
+	s, err := MakeScript("", "cicd", summaryPath)
+	assertNoError(t, err)
+
+	executeScript(t, fs, tempDir, s)
+	got, err := ioutil.ReadFile(summaryPath)
+	assertNoError(t, err)
+	want := strings.Join([]string{
+		"- config/cicd/overlays: 0 created, 0 configured, 0 unchanged",
+		"- environments/dev/env/overlays: 0 created, 0 configured, 0 unchanged",
+		"- environments/stage/env/overlays: 0 created, 0 configured, 0 unchanged\n",
+	}, "\n")
+	if string(got) != want {
+		t.Fatalf("summary failed: got \n%s want: \n%s", got, want)
+	}
+}
+
+func TestMakeScriptWithSummaryWhenApplyFails(t *testing.T) {
+	tempDir, cleanup := tempDir(t)
+	defer cleanup()
+
+	fs := ioutils.NewFilesystem()
+	setupGitOpsTree(t, fs, tempDir, false)
+	summaryPath := filepath.Join(tempDir, "summary")
+	assertNoError(t, ioutil.WriteFile(summaryPath, []byte("- stale summary\n"), 0644))
+	s, err := MakeScript("false", "cicd", summaryPath)
+	assertNoError(t, err)
+
+	scriptPath := filepath.Join(tempDir, "dryrun_script.sh")
+	assertNoError(t, afero.WriteFile(fs, scriptPath, []byte(s), 0777))
+	cmd := exec.Command(scriptPath)
+	cmd.Dir = tempDir
+	if err := cmd.Run(); err == nil {
+		t.Fatal("script didn't fail")
+	}
+	got, err := ioutil.ReadFile(summaryPath)
+	assertNoError(t, err)
+	want := strings.Join([]string{
+		"- config/cicd/overlays: failed, ",
+		"- environments/dev/env/overlays: failed, ",
+		"- environments/stage/env/overlays: failed, \n",
+	}, "\n")
+	if string(got) != want {
+		t.Fatalf("summary failed: got \n%s want: \n%s", got, want)
+	}
+}
+
 func setupGitOpsTree(t *testing.T, fs afero.Fs, base string, withArgoCD bool) {
 	t.Helper()
 	// minimal resources to have a valid GitOps tree
-	script, err := MakeScript("", "cicd")
+	script, err := MakeScript("", "cicd", "")
 	assertNoError(t, err)
 	files := res.Resources{
 		"environments/dev/env/overlays/kustomization.yaml":   res.Kustomization{Bases: []string{"../base"}},
@@ -147,6 +147,10 @@ func TestNewEnvironment(t *testing.T) {
 						Template: appCITemplateName,
 						Bindings: []string{"github-push-binding"},
 					},
+					PullRequest: &config.TemplateBinding{
+						Template: appCIPRTemplateName,
+						Bindings: []string{"github-pr-binding"},
+					},
 				},
 			},
 		},
@@ -172,6 +176,10 @@ func TestNewEnvironment(t *testing.T) {
 						Template: appCITemplateName,
 						Bindings: []string{"gitlab-push-binding"},
 					},
+					PullRequest: &config.TemplateBinding{
+						Template: appCIPRTemplateName,
+						Bindings: []string{"gitlab-pr-binding"},
+					},
 				},
 			},
 		},
@@ -2,13 +2,14 @@ package environments
 
 import (
 	"fmt"
-	"net/url"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/giturl"
 	"github.com/redhat-developer/kam/pkg/pipelines/meta"
 	"github.com/redhat-developer/kam/pkg/pipelines/namespaces"
 	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
@@ -48,11 +49,11 @@ func Build(fs afero.Fs, m *config.Manifest, saName string, o AppLinks) (res.Reso
 	files := res.Resources{}
 	cfg := m.GetPipelinesConfig()
 
-	parsed, err := url.Parse(m.GitOpsURL)
+	parsed, err := giturl.Parse(m.GitOpsURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse GitOpsURL %q: %w", m.GitOpsURL, err)
 	}
-	repoPath := strings.TrimPrefix(strings.TrimSuffix(parsed.Path, ".git"), "/")
+	repoPath := parsed.RepoPath()
 
 	eb := &envBuilder{
 		fs:              fs,
@@ -78,7 +79,7 @@ func (b *envBuilder) Application(env *config.Environment, app *config.Applicatio
 
 func (b *envBuilder) Service(app *config.Application, env *config.Environment, svc *config.Service) error {
 	svcPath := config.PathForService(app, env, svc.Name)
-	svcFiles, err := filesForService(svcPath)
+	svcFiles, err := filesForService(svcPath, config.ServiceSyncWaves(app)[svc.Name])
 	if err != nil {
 		return err
 	}
@@ -179,7 +180,7 @@ func createRoleBinding(env *config.Environment, cicdNS, saName string) *v1.RoleB
 	return roles.CreateRoleBinding(meta.NamespacedName(env.Name, fmt.Sprintf("%s-rolebinding", env.Name)), sa, "ClusterRole", "edit")
 }
 
-func filesForService(svcPath string) (res.Resources, error) {
+func filesForService(svcPath string, wave int) (res.Resources, error) {
 	envFiles := res.Resources{}
 	basePath := filepath.ToSlash(filepath.Join(svcPath, "base"))
 	overlaysPath := filepath.ToSlash(filepath.Join(svcPath, "overlays"))
@@ -188,7 +189,13 @@ func filesForService(svcPath string) (res.Resources, error) {
 	if err != nil {
 		return nil, err
 	}
-	envFiles[filepath.ToSlash(filepath.Join(svcPath, kustomization))] = &res.Kustomization{Bases: []string{"overlays"}}
+	svcKustomization := &res.Kustomization{Bases: []string{"overlays"}}
+	if wave > 0 {
+		svcKustomization.CommonAnnotations = map[string]string{
+			config.SyncWaveAnnotation: strconv.Itoa(wave),
+		}
+	}
+	envFiles[filepath.ToSlash(filepath.Join(svcPath, kustomization))] = svcKustomization
 	envFiles[filepath.ToSlash(filepath.Join(svcPath, "base", kustomization))] = &res.Kustomization{Bases: []string{"./config"}}
 	envFiles[overlaysFile] = &res.Kustomization{Bases: []string{filepath.ToSlash(overlayRel)}}
 
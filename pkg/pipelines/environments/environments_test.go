@@ -205,6 +205,30 @@ func TestBuildEnvironmentFilesWithNoCICDEnv(t *testing.T) {
 	}
 }
 
+func TestBuildEnvironmentFilesAddsSyncWaves(t *testing.T) {
+	var appFs = ioutils.NewMemoryFilesystem()
+	m := buildManifest()
+	m.Environments[0].Apps[0].Services[0].DependsOn = []string{"service-metrics"}
+
+	files, err := Build(appFs, m, "pipelines", AppsToEnvironments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := res.Resources{
+		"environments/test-dev/apps/my-app-1/services/service-http/kustomization.yaml": &res.Kustomization{
+			Bases:             []string{"overlays"},
+			CommonAnnotations: map[string]string{config.SyncWaveAnnotation: "1"},
+		},
+		"environments/test-dev/apps/my-app-1/services/service-metrics/kustomization.yaml": &res.Kustomization{Bases: []string{"overlays"}},
+	}
+	for k, v := range want {
+		if diff := cmp.Diff(v, files[k]); diff != "" {
+			t.Errorf("%s didn't match: %s\n", k, diff)
+		}
+	}
+}
+
 func TestListFiles(t *testing.T) {
 	var appFs = ioutils.NewMemoryFilesystem()
 	var envPath = "environments/test-dev"
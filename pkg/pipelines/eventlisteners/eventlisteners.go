@@ -29,6 +29,10 @@ func Generate(repo scm.Repository, ns, saName, secretName string) (triggersv1.Ev
 	if err != nil {
 		return triggersv1.EventListener{}, err
 	}
+	prTrigger, err := repo.CreatePRTrigger("ci-dryrun-from-pr", secretName, ns, "ci-dryrun-from-pr-template", []string{repo.PRBindingName()})
+	if err != nil {
+		return triggersv1.EventListener{}, err
+	}
 	return triggersv1.EventListener{
 		TypeMeta:   eventListenerTypeMeta,
 		ObjectMeta: createListenerObjectMeta("cicd-event-listener", ns),
@@ -36,6 +40,7 @@ func Generate(repo scm.Repository, ns, saName, secretName string) (triggersv1.Ev
 			ServiceAccountName: saName,
 			Triggers: []triggersv1.EventListenerTrigger{
 				pushTrigger,
+				prTrigger,
 			},
 		},
 	}, nil
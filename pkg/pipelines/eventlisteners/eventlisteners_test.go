@@ -18,6 +18,10 @@ func TestGenerateEventListener(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	prTrigger, err := repo.CreatePRTrigger("ci-dryrun-from-pr", "test", "testing", "ci-dryrun-from-pr-template", []string{"github-pr-binding"})
+	if err != nil {
+		t.Fatal(err)
+	}
 	validEventListener := triggersv1.EventListener{
 		TypeMeta: eventListenerTypeMeta,
 		ObjectMeta: metav1.ObjectMeta{
@@ -28,6 +32,7 @@ func TestGenerateEventListener(t *testing.T) {
 			ServiceAccountName: "pipeline",
 			Triggers: []triggersv1.EventListenerTrigger{
 				trigger,
+				prTrigger,
 			},
 		},
 	}
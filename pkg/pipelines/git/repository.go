@@ -9,8 +9,13 @@ import (
 
 	"github.com/jenkins-x/go-scm/scm"
 	"github.com/jenkins-x/go-scm/scm/factory"
+	"github.com/redhat-developer/kam/pkg/pipelines/giturl"
 )
 
+// webhookName is the name of the webhooks that are created, Bitbucket Server
+// requires webhooks to have a name.
+const webhookName = "tekton-event-listener"
+
 // Repository represent a Git repository ofa specific Git repository URL
 type Repository struct {
 	*scm.Client
@@ -21,7 +26,12 @@ type Repository struct {
 
 // NewRepository creates a new Git repository object
 func NewRepository(rawURL, token string) (*Repository, error) {
-	parsed, err := url.Parse(rawURL)
+	repoURL, err := giturl.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse repository URL %q: %w", rawURL, err)
+	}
+	// The API of the Git host is accessed with HTTPS for SSH URLs.
+	parsed, err := url.Parse(repoURL.HTTPURL())
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse repository URL %q: %w", rawURL, err)
 	}
@@ -35,6 +45,11 @@ func NewRepository(rawURL, token string) (*Repository, error) {
 	if err != nil {
 		return nil, fmt.Errorf("unable to get the repo name from %q: %w", rawURL, err)
 	}
+	// Bitbucket Server clone URLs are of the form
+	// https://bitbucket.example.com/scm/<project>/<repository>.git
+	if client.Driver == scm.DriverStash {
+		repoName = strings.TrimPrefix(repoName, "scm/")
+	}
 	return &Repository{name: repoName, Client: client}, nil
 }
 
@@ -47,7 +62,7 @@ func (r *Repository) ListWebhooks(listenerURL string) ([]string, error) {
 
 	ids := []string{}
 	for _, hook := range hooks {
-		if hook.Target == listenerURL {
+		if hookListenerURL(hook.Target) == listenerURL {
 			ids = append(ids, hook.ID)
 		}
 	}
@@ -55,6 +70,23 @@ func (r *Repository) ListWebhooks(listenerURL string) ([]string, error) {
 	return ids, nil
 }
 
+// hookListenerURL returns the target of a webhook without the webhook secret,
+// go-scm adds the secret to the query of the target for hosts that don't sign
+// the events, e.g. Bitbucket Cloud.
+func hookListenerURL(target string) string {
+	u, err := url.Parse(target)
+	if err != nil {
+		return target
+	}
+	query := u.Query()
+	if _, ok := query["secret"]; !ok {
+		return target
+	}
+	query.Del("secret")
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
 // DeleteWebhooks deletes all webhooks that associate with the given listener in this repository
 func (r *Repository) DeleteWebhooks(ids []string) ([]string, error) {
 	deleted := []string{}
@@ -73,11 +105,15 @@ func (r *Repository) DeleteWebhooks(ids []string) ([]string, error) {
 // It returns ID of the created webhook
 func (r *Repository) CreateWebhook(listenerURL, secret string) (string, error) {
 	in := &scm.HookInput{
+		Name:   webhookName,
 		Target: listenerURL,
 		Secret: secret,
+		// GitLab sends the pushes of tags as Tag Push Hook events, the
+		// other hosts send them as push events.
 		Events: scm.HookEvents{
 			PullRequest: true,
 			Push:        true,
+			Tag:         true,
 		},
 	}
 
@@ -85,10 +121,27 @@ func (r *Repository) CreateWebhook(listenerURL, secret string) (string, error) {
 	return created.ID, err
 }
 
-// TODO: this likely won't work for GitLab projects because it assumes that the
-// path is always composed of two elements.
+// DefaultBranch returns the name of the default branch of the repository.
+func (r *Repository) DefaultBranch() (string, error) {
+	repo, _, err := r.Client.Repositories.Find(context.Background(), r.name)
+	if err != nil {
+		return "", fmt.Errorf("failed to find repository %s: %w", r.name, err)
+	}
+	return repo.Branch, nil
+}
+
+// FindCommit returns the commit that the branch, tag or SHA refers to.
+func (r *Repository) FindCommit(ref string) (*scm.Commit, error) {
+	commit, _, err := r.Client.Git.FindCommit(context.Background(), r.name, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find commit %s in %s: %w", ref, r.name, err)
+	}
+	return commit, nil
+}
+
 // GetRepoName takes a URL of the form https://github.com/my-org/my-repo.git and
-// attempts to determine the name of the repo from this, i.e. "my-org/my-repo".
+// attempts to determine the name of the repo from this, i.e. "my-org/my-repo",
+// GitLab projects keep all of their groups, e.g. "group/subgroup/my-repo".
 func GetRepoName(u *url.URL) (string, error) {
 	var components []string
 	for _, s := range strings.Split(u.Path, "/") {
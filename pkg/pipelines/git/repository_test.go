@@ -104,6 +104,149 @@ func TestListWebHooks(t *testing.T) {
 	}
 }
 
+// Bitbucket webhooks have the secret in the query of the URL.
+func TestListWebHooksWithSecretInURL(t *testing.T) {
+	defer gock.Off()
+	defer func(id factory.HostDriverIdentifier) {
+		factory.DefaultIdentifier = id
+	}(factory.DefaultIdentifier)
+	factory.DefaultIdentifier = factory.NewDriverIdentifier(factory.Mapping("bitbucket.org", "bitbucket"))
+
+	gock.New("https://api.bitbucket.org").
+		Get("/2.0/repositories/foo/bar/hooks").
+		Reply(200).
+		Type("application/json").
+		File("testdata/bitbucket_hooks.json")
+
+	repo, err := NewRepository("https://bitbucket.org/foo/bar.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids, err := repo.ListWebhooks("http://example.com/webhook")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]string{"{d3e4a2f6-4c1f-4b9e-9bd1-4d5b1c2e7a01}"}, ids); diff != "" {
+		t.Errorf("ListWebhooks() failed:\n%s", diff)
+	}
+}
+
+func TestListWebHooksForBitbucketServer(t *testing.T) {
+	defer gock.Off()
+	defer func(id factory.HostDriverIdentifier) {
+		factory.DefaultIdentifier = id
+	}(factory.DefaultIdentifier)
+	factory.DefaultIdentifier = factory.NewDriverIdentifier(factory.Mapping("bitbucket.example.com", "stash"))
+
+	gock.New("https://bitbucket.example.com").
+		Get("/rest/api/1.0/projects/proj/repos/bar/webhooks").
+		Reply(200).
+		Type("application/json").
+		File("testdata/stash_hooks.json")
+
+	repo, err := NewRepository("https://bitbucket.example.com/scm/proj/bar.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids, err := repo.ListWebhooks("http://example.com/webhook")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]string{"10"}, ids); diff != "" {
+		t.Errorf("ListWebhooks() failed:\n%s", diff)
+	}
+}
+
+func TestListWebHooksForGitea(t *testing.T) {
+	defer gock.Off()
+	defer func(id factory.HostDriverIdentifier) {
+		factory.DefaultIdentifier = id
+	}(factory.DefaultIdentifier)
+	factory.DefaultIdentifier = factory.NewDriverIdentifier(factory.Mapping("gitea.example.com", "gitea"))
+
+	gock.New("https://gitea.example.com").
+		Get("/api/v1/version").
+		Reply(200).
+		JSON(map[string]string{"version": "1.15.0"})
+	gock.New("https://gitea.example.com").
+		Get("/api/v1/repos/foo/bar/hooks").
+		Reply(200).
+		Type("application/json").
+		File("testdata/gitea_hooks.json")
+
+	repo, err := NewRepository("https://gitea.example.com/foo/bar.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids, err := repo.ListWebhooks("http://example.com/webhook")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]string{"3"}, ids); diff != "" {
+		t.Errorf("ListWebhooks() failed:\n%s", diff)
+	}
+}
+
+// GitLab projects in subgroups are identified by their full path.
+func TestListWebHooksForGitLabSubgroup(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://gitlab.com").
+		Get("/api/v4/projects/group/subgroup/team/bar/hooks").
+		Reply(200).
+		Type("application/json").
+		JSON([]map[string]interface{}{
+			{"id": 1, "url": "http://example.com/webhook", "push_events": true},
+			{"id": 2, "url": "http://example.com/other", "push_events": true},
+		})
+
+	repo, err := NewRepository("https://gitlab.com/group/subgroup/team/bar.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids, err := repo.ListWebhooks("http://example.com/webhook")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]string{"1"}, ids); diff != "" {
+		t.Errorf("ListWebhooks() failed:\n%s", diff)
+	}
+}
+
+// The webhooks of repositories with SSH URLs are managed with the API of the
+// host.
+func TestListWebHooksWithSSHURL(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://api.github.com").
+		Get("/repos/foo/bar/hooks").
+		Reply(200).
+		Type("application/json").
+		File("testdata/hooks.json")
+
+	repo, err := NewRepository("git@github.com:foo/bar.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids, err := repo.ListWebhooks("http://example.com/webhook")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([]string{"1"}, ids); diff != "" {
+		t.Errorf("ListWebhooks() failed:\n%s", diff)
+	}
+}
+
 func TestDeleteWebHooks(t *testing.T) {
 	defer gock.Off()
 
@@ -153,6 +296,119 @@ func TestCreateWebHook(t *testing.T) {
 	}
 }
 
+// GitLab only sends the pushes of tags to webhooks with tag push events.
+func TestCreateWebHookForGitLab(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://gitlab.com").
+		Post("/api/v4/projects/foo/bar/hooks").
+		MatchParam("merge_requests_events", "true").
+		MatchParam("push_events", "true").
+		MatchParam("tag_push_events", "true").
+		MatchParam("token", "secret").
+		Reply(201).
+		Type("application/json").
+		JSON(map[string]interface{}{"id": 7, "url": "http://example.com/webhook", "push_events": true, "tag_push_events": true, "merge_requests_events": true})
+
+	repo, err := NewRepository("https://gitlab.com/foo/bar.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := repo.CreateWebhook("http://example.com/webhook", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id != "7" {
+		t.Fatalf("CreateWebhook() got %q, want %q", id, "7")
+	}
+}
+
+// Bitbucket Server requires webhooks to have a name, and signs the events with
+// the secret in the configuration.
+func TestCreateWebHookForBitbucketServer(t *testing.T) {
+	defer gock.Off()
+	defer func(id factory.HostDriverIdentifier) {
+		factory.DefaultIdentifier = id
+	}(factory.DefaultIdentifier)
+	factory.DefaultIdentifier = factory.NewDriverIdentifier(factory.Mapping("bitbucket.example.com", "stash"))
+
+	gock.New("https://bitbucket.example.com").
+		Post("/rest/api/1.0/projects/proj/repos/bar/webhooks").
+		BodyString(`"name":"tekton-event-listener".*"url":"http://example.com/webhook".*"configuration":\{"secret":"secret"\}`).
+		Reply(201).
+		Type("application/json").
+		JSON(map[string]interface{}{"id": 12, "name": "tekton-event-listener", "url": "http://example.com/webhook", "active": true})
+
+	repo, err := NewRepository("https://bitbucket.example.com/scm/proj/bar.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := repo.CreateWebhook("http://example.com/webhook", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id != "12" {
+		t.Fatalf("CreateWebhook() got %q, want %q", id, "12")
+	}
+}
+
+func TestDefaultBranch(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://api.github.com").
+		Get("/repos/foo/bar").
+		Reply(200).
+		Type("application/json").
+		SetHeaders(mockHeaders).
+		File("testdata/repo.json")
+
+	repo, err := NewRepository("https://github.com/foo/bar.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	branch, err := repo.DefaultBranch()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if branch != "main" {
+		t.Errorf("got branch %q, want %q", branch, "main")
+	}
+}
+
+func TestFindCommit(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://api.github.com").
+		Get("/repos/foo/bar/commits/main").
+		Reply(200).
+		Type("application/json").
+		SetHeaders(mockHeaders).
+		File("testdata/commit.json")
+
+	repo, err := NewRepository("https://github.com/foo/bar.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commit, err := repo.FindCommit("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if commit.Sha != "7fd1a60b01f91b314f59955a4e4d4e80d8edf11d" {
+		t.Errorf("got commit %q", commit.Sha)
+	}
+	if commit.Message != "Fix all the bugs" || commit.Author.Name != "Monalisa Octocat" {
+		t.Errorf("got commit message %q by %q", commit.Message, commit.Author.Name)
+	}
+}
+
 func TestGetRepoName(t *testing.T) {
 	urlTests := []struct {
 		url      string
@@ -161,6 +417,7 @@ func TestGetRepoName(t *testing.T) {
 		{"https://github.com/example/gitops.git?ref=main", "example/gitops"},
 		{"https://github.com/example/testing.git", "example/testing"},
 		{"https://gitlab.com/project/example/testing.git", "project/example/testing"},
+		{"https://gitlab.com/group/subgroup/team/testing.git", "group/subgroup/team/testing"},
 	}
 
 	for _, tt := range urlTests {
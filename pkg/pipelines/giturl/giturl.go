@@ -0,0 +1,83 @@
+// Package giturl parses the URLs of Git repositories, in the HTTP(S), ssh://
+// and scp-like forms, e.g. git@github.com:org/repo.git.
+package giturl
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// scpLikeURL matches the scp-like syntax that Git accepts for SSH URLs, e.g.
+// git@github.com:org/repo.git, the path can't start with a slash, so that
+// "host:/path" isn't mistaken for a port.
+var scpLikeURL = regexp.MustCompile(`^(?:([^@/]+)@)?([^:/]+):([^/].*)$`)
+
+// URL is a normalised Git repository URL.
+type URL struct {
+	// Scheme is http, https or ssh, scp-like URLs have the ssh scheme.
+	Scheme string
+	// User is the user of SSH URLs, e.g. git.
+	User string
+	// Host is the lower-cased host of the repository, HTTP(S) URLs keep their
+	// port, the port of SSH URLs isn't the port of the web server, so it's not
+	// part of the host.
+	Host string
+	// Path is the path of the repository, with a leading slash, e.g.
+	// /org/repo.git.
+	Path string
+
+	raw string
+}
+
+// Parse parses a repository URL, scp-like URLs are parsed as ssh:// URLs.
+func Parse(raw string) (*URL, error) {
+	if !strings.Contains(raw, "://") {
+		if m := scpLikeURL.FindStringSubmatch(raw); m != nil {
+			return &URL{Scheme: "ssh", User: m[1], Host: strings.ToLower(m[2]), Path: "/" + m[3], raw: raw}, nil
+		}
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	parsed := &URL{Scheme: strings.ToLower(u.Scheme), Host: strings.ToLower(u.Host), Path: u.Path, raw: raw}
+	if parsed.Scheme == "ssh" {
+		parsed.Host = strings.ToLower(u.Hostname())
+		parsed.User = u.User.Username()
+	}
+	return parsed, nil
+}
+
+// IsSSH returns true if the repository is accessed with SSH.
+func (u *URL) IsSSH() bool {
+	return u.Scheme == "ssh"
+}
+
+// String returns the URL as it was parsed.
+func (u *URL) String() string {
+	return u.raw
+}
+
+// HTTPURL returns the HTTP(S) clone URL of the repository, SSH URLs are
+// converted to HTTPS URLs on the same host and path.
+func (u *URL) HTTPURL() string {
+	if !u.IsSSH() {
+		return u.raw
+	}
+	return fmt.Sprintf("https://%s/%s", u.Host, strings.TrimPrefix(u.Path, "/"))
+}
+
+// RepoPath returns the path of the repository without the .git suffix and the
+// leading slash, e.g. org/repo.
+func (u *URL) RepoPath() string {
+	return strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+}
+
+// RepoName returns the last element of the path of the repository, without the
+// .git suffix.
+func (u *URL) RepoName() string {
+	path := u.RepoPath()
+	return path[strings.LastIndex(path, "/")+1:]
+}
@@ -0,0 +1,101 @@
+package giturl
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParse(t *testing.T) {
+	urlTests := []struct {
+		raw     string
+		want    *URL
+		httpURL string
+	}{
+		{
+			"https://github.com/org/repo.git",
+			&URL{Scheme: "https", Host: "github.com", Path: "/org/repo.git"},
+			"https://github.com/org/repo.git",
+		},
+		{
+			"http://GitLab.example.com:8080/group/subgroup/repo.git",
+			&URL{Scheme: "http", Host: "gitlab.example.com:8080", Path: "/group/subgroup/repo.git"},
+			"http://GitLab.example.com:8080/group/subgroup/repo.git",
+		},
+		{
+			"git@github.com:org/repo.git",
+			&URL{Scheme: "ssh", User: "git", Host: "github.com", Path: "/org/repo.git"},
+			"https://github.com/org/repo.git",
+		},
+		{
+			"GitLab.com:group/subgroup/repo.git",
+			&URL{Scheme: "ssh", Host: "gitlab.com", Path: "/group/subgroup/repo.git"},
+			"https://gitlab.com/group/subgroup/repo.git",
+		},
+		{
+			"ssh://git@bitbucket.example.com:7999/proj/repo.git",
+			&URL{Scheme: "ssh", User: "git", Host: "bitbucket.example.com", Path: "/proj/repo.git"},
+			"https://bitbucket.example.com/proj/repo.git",
+		},
+		{
+			"org/repo",
+			&URL{Path: "org/repo"},
+			"org/repo",
+		},
+	}
+
+	for _, tt := range urlTests {
+		t.Run(tt.raw, func(rt *testing.T) {
+			got, err := Parse(tt.raw)
+			if err != nil {
+				rt.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(URL{}), cmp.FilterPath(func(p cmp.Path) bool {
+				return p.Last().String() == ".raw"
+			}, cmp.Ignore())); diff != "" {
+				rt.Fatalf("Parse() failed:\n%s", diff)
+			}
+			if got.String() != tt.raw {
+				rt.Fatalf("String() got %q, want %q", got.String(), tt.raw)
+			}
+			if h := got.HTTPURL(); h != tt.httpURL {
+				rt.Fatalf("HTTPURL() got %q, want %q", h, tt.httpURL)
+			}
+		})
+	}
+}
+
+func TestRepoPath(t *testing.T) {
+	urlTests := []struct {
+		raw      string
+		wantPath string
+		wantName string
+	}{
+		{"https://github.com/org/repo.git", "org/repo", "repo"},
+		{"git@gitlab.com:group/subgroup/repo.git", "group/subgroup/repo", "repo"},
+		{"ssh://git@github.com/org/repo", "org/repo", "repo"},
+		{"https://example.com/repo/", "repo", "repo"},
+	}
+
+	for _, tt := range urlTests {
+		t.Run(tt.raw, func(rt *testing.T) {
+			u, err := Parse(tt.raw)
+			if err != nil {
+				rt.Fatal(err)
+			}
+			if p := u.RepoPath(); p != tt.wantPath {
+				rt.Errorf("RepoPath() got %q, want %q", p, tt.wantPath)
+			}
+			if n := u.RepoName(); n != tt.wantName {
+				rt.Errorf("RepoName() got %q, want %q", n, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestParseInvalidURL(t *testing.T) {
+	_, err := Parse("https://example.com/%zz")
+	if err == nil {
+		t.Fatal("Parse() didn't fail")
+	}
+}
@@ -0,0 +1,194 @@
+package pipelines
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/dryrun"
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+	"github.com/redhat-developer/kam/pkg/pipelines/pipelines"
+	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+	"github.com/redhat-developer/kam/pkg/pipelines/scm"
+	"github.com/redhat-developer/kam/pkg/pipelines/tasks"
+	"github.com/redhat-developer/kam/pkg/pipelines/tektonapi"
+	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
+)
+
+// generatedResource is the part of a generated Task, Pipeline or
+// TriggerTemplate that identifies the use of PipelineResources.
+type generatedResource struct {
+	Spec struct {
+		Resources json.RawMessage `json:"resources,omitempty"`
+		Tasks     []struct {
+			Name string `json:"name"`
+		} `json:"tasks,omitempty"`
+		ResourceTemplates []struct {
+			Spec struct {
+				Resources json.RawMessage `json:"resources,omitempty"`
+			} `json:"spec"`
+		} `json:"resourcetemplates,omitempty"`
+	} `json:"spec"`
+}
+
+func (r *generatedResource) usesPipelineResources() bool {
+	if len(r.Spec.Resources) > 0 && string(r.Spec.Resources) != "null" {
+		return true
+	}
+	for _, t := range r.Spec.ResourceTemplates {
+		if len(t.Spec.Resources) > 0 && string(t.Spec.Resources) != "null" {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *generatedResource) hasTask(name string) bool {
+	for _, t := range r.Spec.Tasks {
+		if t.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// migrateTektonResources regenerates the Tasks, Pipelines and
+// TriggerTemplates written by bootstrap into outputPath that were generated
+// with PipelineResources, these have been removed from Tekton, and the
+// regenerated resources clone the source into a workspace.
+//
+// Files that don't exist, or don't use PipelineResources are left alone.
+func migrateTektonResources(fs afero.Fs, outputPath string, m *config.Manifest) (res.Resources, error) {
+	cfg := m.GetPipelinesConfig()
+	if cfg == nil {
+		return res.Resources{}, nil
+	}
+	ns := cfg.Name
+	basePath := pipelinesPath(m.Config)
+	migrations := map[string]func(*generatedResource) (interface{}, error){
+		gitopsTasksPath: func(*generatedResource) (interface{}, error) {
+			script, err := dryrun.MakeScript("kubectl", ns, tasks.SummaryPath)
+			if err != nil {
+				return nil, err
+			}
+			return tasks.CreateDeployFromSourceTask(ns, script), nil
+		},
+		ciPipelinesPath: func(old *generatedResource) (interface{}, error) {
+			p := pipelines.CreateCIPipeline(meta.NamespacedName(ns, "ci-dryrun-from-push-pipeline"), ns)
+			return keepCommitStatus(old, p), nil
+		},
+		ciPRPipelinesPath: func(old *generatedResource) (interface{}, error) {
+			driver, err := scm.GetDriverName(m.GitOpsURL)
+			if err != nil {
+				return nil, err
+			}
+			p := pipelines.CreateCIPRPipeline(meta.NamespacedName(ns, "ci-dryrun-from-pr-pipeline"), driver)
+			return keepCommitStatus(old, p), nil
+		},
+		pushTemplatePath: func(*generatedResource) (interface{}, error) {
+			return triggers.CreateCIDryRunTemplate(ns, saName), nil
+		},
+		prTemplatePath: func(*generatedResource) (interface{}, error) {
+			return triggers.CreateCIDryRunPRTemplate(ns, saName), nil
+		},
+	}
+
+	migrated := res.Resources{}
+	for path, migrate := range migrations {
+		filename := filepath.Join(basePath, path)
+		old, err := readGeneratedResource(fs, filepath.Join(outputPath, filename))
+		if err != nil {
+			return nil, err
+		}
+		if old == nil || !old.usesPipelineResources() {
+			continue
+		}
+		updated, err := migrate(old)
+		if err != nil {
+			return nil, fmt.Errorf("failed to migrate %s: %w", filename, err)
+		}
+		migrated[filename] = updated
+	}
+	return migrated, nil
+}
+
+func readGeneratedResource(fs afero.Fs, filename string) (*generatedResource, error) {
+	exists, err := afero.Exists(fs, filename)
+	if err != nil || !exists {
+		return nil, err
+	}
+	data, err := afero.ReadFile(fs, filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+	var r generatedResource
+	if err := yaml.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+	return &r, nil
+}
+
+// keepCommitStatus removes the commit status tasks from the regenerated
+// pipeline if they had been removed from the original pipeline.
+func keepCommitStatus(old *generatedResource, p *pipelinev1.Pipeline) *pipelinev1.Pipeline {
+	if old.hasTask(pipelines.PendingCommitStatusTask) {
+		return p
+	}
+	return stripCommitStatus(p)
+}
+
+// upgradeTektonResources reads the Tekton resources in the pipelines
+// configuration in outputPath that were written with older API versions than
+// the configured TektonAPIVersion, so that they can be converted.
+//
+// Files that are in the generated resources are skipped, as are files with
+// more than one document.
+func upgradeTektonResources(fs afero.Fs, outputPath string, m *config.Manifest, generated res.Resources) (res.Resources, error) {
+	cfg := m.GetPipelinesConfig()
+	if cfg.GetTektonAPIVersion() != config.TektonV1 {
+		return res.Resources{}, nil
+	}
+	basePath := pipelinesPath(m.Config)
+	root := filepath.Join(outputPath, basePath)
+	exists, err := afero.DirExists(fs, root)
+	if err != nil || !exists {
+		return res.Resources{}, err
+	}
+	upgraded := res.Resources{}
+	err = afero.Walk(fs, root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || filepath.Ext(path) != ".yaml" {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		filename := filepath.Join(basePath, rel)
+		if _, ok := generated[filename]; ok {
+			return nil
+		}
+		data, err := afero.ReadFile(fs, path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if bytes.Contains(data, []byte("\n---")) {
+			return nil
+		}
+		obj := map[string]interface{}{}
+		if err := yaml.Unmarshal(data, &obj); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		if tektonapi.NeedsConversion(obj) {
+			upgraded[filename] = obj
+		}
+		return nil
+	})
+	return upgraded, err
+}
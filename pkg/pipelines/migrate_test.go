@@ -0,0 +1,190 @@
+package pipelines
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/dryrun"
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+	"github.com/redhat-developer/kam/pkg/pipelines/pipelines"
+	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+	"github.com/redhat-developer/kam/pkg/pipelines/tasks"
+	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
+)
+
+const (
+	oldDeployFromSourceTask = `apiVersion: tekton.dev/v1beta1
+kind: Task
+metadata:
+  name: deploy-from-source-task
+  namespace: cicd
+spec:
+  resources:
+    inputs:
+    - name: source
+      type: git
+`
+
+	oldCIPipeline = `apiVersion: tekton.dev/v1beta1
+kind: Pipeline
+metadata:
+  name: ci-dryrun-from-push-pipeline
+  namespace: cicd
+spec:
+  resources:
+  - name: source-repo
+    type: git
+  tasks:
+  - name: apply-source
+`
+
+	oldPushTemplate = `apiVersion: triggers.tekton.dev/v1alpha1
+kind: TriggerTemplate
+metadata:
+  name: ci-dryrun-from-push-template
+  namespace: cicd
+spec:
+  resourcetemplates:
+  - apiVersion: tekton.dev/v1beta1
+    kind: PipelineRun
+    spec:
+      resources:
+      - name: source-repo
+`
+
+	migratedPRTemplate = `apiVersion: triggers.tekton.dev/v1alpha1
+kind: TriggerTemplate
+metadata:
+  name: ci-dryrun-from-pr-template
+  namespace: cicd
+spec:
+  resourcetemplates:
+  - apiVersion: tekton.dev/v1beta1
+    kind: PipelineRun
+    spec:
+      workspaces:
+      - name: shared-data
+`
+)
+
+func TestMigrateTektonResources(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	m := &config.Manifest{
+		GitOpsURL: "https://github.com/example/gitops.git",
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{Name: "cicd"},
+		},
+	}
+	base := filepath.Join("/output", pipelinesPath(m.Config))
+	files := map[string]string{
+		gitopsTasksPath:  oldDeployFromSourceTask,
+		ciPipelinesPath:  oldCIPipeline,
+		pushTemplatePath: oldPushTemplate,
+		prTemplatePath:   migratedPRTemplate,
+	}
+	for path, body := range files {
+		if err := afero.WriteFile(fs, filepath.Join(base, path), []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := migrateTektonResources(fs, "/output", m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	script, err := dryrun.MakeScript("kubectl", "cicd", tasks.SummaryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := res.Resources{
+		filepath.Join(pipelinesPath(m.Config), gitopsTasksPath): tasks.CreateDeployFromSourceTask("cicd", script),
+		filepath.Join(pipelinesPath(m.Config), ciPipelinesPath): stripCommitStatus(
+			pipelines.CreateCIPipeline(meta.NamespacedName("cicd", "ci-dryrun-from-push-pipeline"), "cicd")),
+		filepath.Join(pipelinesPath(m.Config), pushTemplatePath): triggers.CreateCIDryRunTemplate("cicd", saName),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("migrateTektonResources() failed:\n%s", diff)
+	}
+}
+
+func TestMigrateTektonResourcesWithoutPipelinesConfig(t *testing.T) {
+	got, err := migrateTektonResources(afero.NewMemMapFs(), "/output", &config.Manifest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("migrateTektonResources() got %v, want no resources", got)
+	}
+}
+
+func TestUpgradeTektonResources(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	m := &config.Manifest{
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{Name: "cicd", TektonAPIVersion: config.TektonV1},
+		},
+	}
+	base := pipelinesPath(m.Config)
+	files := map[string]string{
+		"03-tasks/go-test-task.yaml":                 "apiVersion: tekton.dev/v1beta1\nkind: Task\nmetadata:\n  name: go-test\n",
+		"03-tasks/upgraded-task.yaml":                "apiVersion: tekton.dev/v1\nkind: Task\nmetadata:\n  name: upgraded\n",
+		"04-pipelines/app-ci-pipeline.yaml":          "apiVersion: tekton.dev/v1beta1\nkind: Pipeline\n",
+		"05-bindings/multiple-bindings.yaml":         "apiVersion: triggers.tekton.dev/v1alpha1\nkind: TriggerBinding\n---\napiVersion: triggers.tekton.dev/v1alpha1\nkind: TriggerBinding\n",
+		"kustomization.yaml":                         "resources:\n- 03-tasks/go-test-task.yaml\n",
+		"07-eventlisteners/cicd-event-listener.yaml": "apiVersion: triggers.tekton.dev/v1alpha1\nkind: EventListener\n",
+	}
+	for path, body := range files {
+		if err := afero.WriteFile(fs, filepath.Join("/output", base, path), []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	generated := res.Resources{
+		filepath.Join(base, "04-pipelines/app-ci-pipeline.yaml"): "generated",
+	}
+
+	got, err := upgradeTektonResources(fs, "/output", m, generated)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := res.Resources{
+		filepath.Join(base, "03-tasks/go-test-task.yaml"): map[string]interface{}{
+			"apiVersion": "tekton.dev/v1beta1",
+			"kind":       "Task",
+			"metadata":   map[string]interface{}{"name": "go-test"},
+		},
+		filepath.Join(base, "07-eventlisteners/cicd-event-listener.yaml"): map[string]interface{}{
+			"apiVersion": "triggers.tekton.dev/v1alpha1",
+			"kind":       "EventListener",
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("upgradeTektonResources() failed:\n%s", diff)
+	}
+}
+
+func TestUpgradeTektonResourcesWithDefaultVersion(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	m := &config.Manifest{
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{Name: "cicd"},
+		},
+	}
+	path := filepath.Join("/output", pipelinesPath(m.Config), "03-tasks/go-test-task.yaml")
+	if err := afero.WriteFile(fs, path, []byte("apiVersion: tekton.dev/v1beta1\nkind: Task\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := upgradeTektonResources(fs, "/output", m, res.Resources{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("upgradeTektonResources() got %v, want no resources", got)
+	}
+}
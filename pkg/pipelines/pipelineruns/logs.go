@@ -0,0 +1,147 @@
+package pipelineruns
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/tektonapi"
+)
+
+// The label that Tekton Pipelines adds to the TaskRuns of a PipelineRun with
+// the name of the task in the Pipeline.
+const pipelineTaskLabel = "tekton.dev/pipelineTask"
+
+// taskRun is the part of a TaskRun that is needed to find the logs of its pod.
+type taskRun struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Status            struct {
+		PodName   string       `json:"podName"`
+		StartTime *metav1.Time `json:"startTime"`
+	} `json:"status"`
+}
+
+// pollInterval is how often the PipelineRun, its TaskRuns, and their pods are
+// checked when the logs are followed, it is replaced in tests.
+var pollInterval = 2 * time.Second
+
+// Logs writes the logs of the steps of the TaskRuns of a PipelineRun to out,
+// in the order that the TaskRuns started, each line is prefixed with the
+// names of the task and the step.
+//
+// TaskRuns that haven't created a pod yet are skipped, unless the logs are
+// followed, in which case the logs are streamed as they are written, and the
+// TaskRuns that start later are waited for, until the PipelineRun completes.
+func Logs(kubeClient kubernetes.Interface, client dynamic.Interface, version, ns, name string, follow bool, out io.Writer) error {
+	shown := map[string]bool{}
+	for {
+		u, err := client.Resource(tektonapi.PipelineRunsResource(version)).Namespace(ns).Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to get PipelineRun %s: %w", name, err)
+		}
+		run, err := toPipelineRun(*u)
+		if err != nil {
+			return err
+		}
+		taskRuns, err := startedTaskRuns(client, version, ns, name)
+		if err != nil {
+			return err
+		}
+		for _, tr := range taskRuns {
+			if shown[tr.Name] {
+				continue
+			}
+			shown[tr.Name] = true
+			task := tr.Labels[pipelineTaskLabel]
+			if task == "" {
+				task = tr.Name
+			}
+			if err := podLogs(kubeClient, ns, tr.Status.PodName, task, follow, out); err != nil {
+				return err
+			}
+		}
+		// The TaskRuns are listed after the PipelineRun, so all of the TaskRuns
+		// of a completed PipelineRun have been shown.
+		if !follow || run.Status.CompletionTime != nil {
+			return nil
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// startedTaskRuns returns the TaskRuns of the PipelineRun that have created a
+// pod, in the order that they started.
+func startedTaskRuns(client dynamic.Interface, version, ns, name string) ([]*taskRun, error) {
+	list, err := client.Resource(tektonapi.TaskRunsResource(version)).Namespace(ns).List(context.Background(), metav1.ListOptions{
+		LabelSelector: pipelineRunLabel + "=" + name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the TaskRuns of %s: %w", name, err)
+	}
+	taskRuns := []*taskRun{}
+	for _, item := range list.Items {
+		tr := &taskRun{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, tr); err != nil {
+			return nil, fmt.Errorf("failed to convert TaskRun %s: %w", item.GetName(), err)
+		}
+		if tr.Status.PodName != "" && tr.Status.StartTime != nil {
+			taskRuns = append(taskRuns, tr)
+		}
+	}
+	sort.SliceStable(taskRuns, func(i, j int) bool {
+		return taskRuns[i].Status.StartTime.Before(taskRuns[j].Status.StartTime)
+	})
+	return taskRuns, nil
+}
+
+// podLogs writes the logs of the step containers of the pod in order, if the
+// logs are followed, this waits for the pod to start, and for each step to
+// finish.
+func podLogs(kubeClient kubernetes.Interface, ns, podName, task string, follow bool, out io.Writer) error {
+	pod, err := kubeClient.CoreV1().Pods(ns).Get(context.Background(), podName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get pod %s: %w", podName, err)
+	}
+	for follow && pod.Status.Phase == corev1.PodPending {
+		time.Sleep(pollInterval)
+		pod, err = kubeClient.CoreV1().Pods(ns).Get(context.Background(), podName, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to get pod %s: %w", podName, err)
+		}
+	}
+	for _, c := range pod.Spec.Containers {
+		if !strings.HasPrefix(c.Name, "step-") {
+			continue
+		}
+		stream, err := kubeClient.CoreV1().Pods(ns).GetLogs(podName, &corev1.PodLogOptions{Container: c.Name, Follow: follow}).Stream(context.Background())
+		if err != nil {
+			return fmt.Errorf("failed to get the logs of %s in pod %s: %w", c.Name, podName, err)
+		}
+		err = copyLines(out, stream, fmt.Sprintf("[%s : %s] ", task, strings.TrimPrefix(c.Name, "step-")))
+		stream.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyLines(out io.Writer, in io.Reader, prefix string) error {
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		if _, err := fmt.Fprintln(out, prefix+scanner.Text()); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
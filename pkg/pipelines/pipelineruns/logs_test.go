@@ -0,0 +1,134 @@
+package pipelineruns
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/tektonapi"
+	"github.com/redhat-developer/kam/test"
+)
+
+func TestLogs(t *testing.T) {
+	client := newFakeClient(config.TektonV1Beta1,
+		makePipelineRun("app-ci-1", "app-ci-build-from-push-taxi", 0, "True", "Succeeded", time.Minute),
+		makeTaskRun("app-ci-1-build-image", "app-ci-1", "build-image", "app-ci-1-build-image-pod", time.Minute),
+		makeTaskRun("app-ci-1-clone-source", "app-ci-1", "clone-source", "app-ci-1-clone-source-pod", 0),
+		makeTaskRun("app-ci-1-update-image", "app-ci-1", "update-image", "", 0),
+		makeTaskRun("app-ci-2-clone-source", "app-ci-2", "clone-source", "app-ci-2-clone-source-pod", 0),
+	)
+	kubeClient := kubefake.NewSimpleClientset(
+		makePod("app-ci-1-build-image-pod", "place-tools", "step-build", "step-push"),
+		makePod("app-ci-1-clone-source-pod", "step-clone"),
+	)
+	var out bytes.Buffer
+
+	if err := Logs(kubeClient, client, config.TektonV1Beta1, testNS, "app-ci-1", false, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "[clone-source : clone] fake logs\n[build-image : build] fake logs\n[build-image : push] fake logs\n"
+	if diff := cmp.Diff(want, out.String()); diff != "" {
+		t.Fatalf("Logs() failed:\n%s", diff)
+	}
+}
+
+func TestLogsWithFollow(t *testing.T) {
+	defer func(d time.Duration) { pollInterval = d }(pollInterval)
+	pollInterval = 0
+	client := newFakeClient(config.TektonV1Beta1,
+		makePipelineRun("app-ci-1", "app-ci-build-from-push-taxi", 0, "Unknown", "Running", 0),
+		makeTaskRun("app-ci-1-clone-source", "app-ci-1", "clone-source", "app-ci-1-clone-source-pod", 0),
+	)
+	// The build-image TaskRun starts, and the PipelineRun completes, after the
+	// logs of the clone-source TaskRun are shown.
+	gets := 0
+	client.PrependReactor("get", "pipelineruns", func(action ktesting.Action) (bool, runtime.Object, error) {
+		gets++
+		if gets != 2 {
+			return false, nil, nil
+		}
+		if err := client.Tracker().Add(makeTaskRun("app-ci-1-build-image", "app-ci-1", "build-image", "app-ci-1-build-image-pod", time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+		completed := makePipelineRun("app-ci-1", "app-ci-build-from-push-taxi", 0, "True", "Succeeded", 2*time.Minute)
+		if err := client.Tracker().Update(tektonapi.PipelineRunsResource(config.TektonV1Beta1), completed, testNS); err != nil {
+			t.Fatal(err)
+		}
+		return false, nil, nil
+	})
+	kubeClient := kubefake.NewSimpleClientset(
+		makePod("app-ci-1-build-image-pod", "step-build"),
+		makePod("app-ci-1-clone-source-pod", "step-clone"),
+	)
+	var out bytes.Buffer
+
+	if err := Logs(kubeClient, client, config.TektonV1Beta1, testNS, "app-ci-1", true, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "[clone-source : clone] fake logs\n[build-image : build] fake logs\n"
+	if diff := cmp.Diff(want, out.String()); diff != "" {
+		t.Fatalf("Logs() failed:\n%s", diff)
+	}
+	for _, action := range kubeClient.Actions() {
+		if action.GetSubresource() != "log" {
+			continue
+		}
+		opts := action.(ktesting.GenericAction).GetValue().(*corev1.PodLogOptions)
+		if !opts.Follow {
+			t.Fatalf("the logs of %s were not followed", opts.Container)
+		}
+	}
+}
+
+func TestLogsWithUnknownPipelineRun(t *testing.T) {
+	err := Logs(kubefake.NewSimpleClientset(), newFakeClient(config.TektonV1Beta1), config.TektonV1Beta1, testNS, "app-ci-1", false, &bytes.Buffer{})
+
+	test.AssertErrorMatch(t, "failed to get PipelineRun app-ci-1", err)
+}
+
+// makeTaskRun creates a TaskRun of a PipelineRun, that started at the offset
+// from the test time, in the pod, if there is no pod the TaskRun hasn't
+// started.
+func makeTaskRun(name, pipelineRun, task, pod string, offset time.Duration) *unstructured.Unstructured {
+	status := map[string]interface{}{}
+	if pod != "" {
+		status["podName"] = pod
+		status["startTime"] = testTime.Add(offset).Format(time.RFC3339)
+	}
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "tekton.dev/v1beta1",
+			"kind":       "TaskRun",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": testNS,
+				"labels": map[string]interface{}{
+					pipelineRunLabel:  pipelineRun,
+					pipelineTaskLabel: task,
+				},
+			},
+			"status": status,
+		},
+	}
+}
+
+func makePod(name string, containers ...string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNS},
+	}
+	for _, c := range containers {
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: c})
+	}
+	return pod
+}
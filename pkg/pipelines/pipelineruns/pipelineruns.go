@@ -0,0 +1,182 @@
+package pipelineruns
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/clientconfig"
+	"github.com/redhat-developer/kam/pkg/pipelines/tektonapi"
+	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
+)
+
+// The labels that Tekton Triggers and Pipelines add to the runs they create.
+const (
+	triggerLabel     = "triggers.tekton.dev/trigger"
+	pipelineRunLabel = "tekton.dev/pipelineRun"
+)
+
+// now is replaced in tests.
+var now = time.Now
+
+// Summary is the commit, status and duration of a PipelineRun.
+type Summary struct {
+	Name     string        `json:"name"`
+	Commit   string        `json:"commit,omitempty"`
+	Author   string        `json:"author,omitempty"`
+	Ref      string        `json:"ref,omitempty"`
+	Status   string        `json:"status"`
+	Started  *metav1.Time  `json:"started,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// pipelineRun is the part of a PipelineRun that is summarised, it is the same
+// in tekton.dev/v1beta1 and tekton.dev/v1.
+type pipelineRun struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              struct {
+		Params []struct {
+			Name  string      `json:"name"`
+			Value interface{} `json:"value"`
+		} `json:"params"`
+	} `json:"spec"`
+	Status runStatus `json:"status"`
+}
+
+type runStatus struct {
+	Conditions []struct {
+		Type   string `json:"type"`
+		Status string `json:"status"`
+		Reason string `json:"reason"`
+	} `json:"conditions"`
+	StartTime      *metav1.Time `json:"startTime"`
+	CompletionTime *metav1.Time `json:"completionTime"`
+}
+
+// List returns summaries of the most recent PipelineRuns in the namespace
+// with the API version of the Tekton version, newest first, at most limit are
+// returned if limit is greater than zero.
+//
+// If service is not empty, only the PipelineRuns that were started by the
+// push and pull request triggers of the service are returned.
+func List(client dynamic.Interface, version, ns, service string, limit int) ([]*Summary, error) {
+	opts := metav1.ListOptions{}
+	if service != "" {
+		opts.LabelSelector = fmt.Sprintf("%s in (%s,%s)", triggerLabel,
+			triggers.ServicePushTriggerName(service), triggers.ServicePRTriggerName(service))
+	}
+	list, err := client.Resource(tektonapi.PipelineRunsResource(version)).Namespace(ns).List(context.Background(), opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PipelineRuns in %s: %w", ns, err)
+	}
+	runs := []*pipelineRun{}
+	for _, item := range list.Items {
+		run, err := toPipelineRun(item)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[j].CreationTimestamp.Before(&runs[i].CreationTimestamp)
+	})
+	if limit > 0 && len(runs) > limit {
+		runs = runs[:limit]
+	}
+	summaries := make([]*Summary, len(runs))
+	for i, run := range runs {
+		summaries[i] = summarise(run)
+	}
+	return summaries, nil
+}
+
+func toPipelineRun(u unstructured.Unstructured) (*pipelineRun, error) {
+	run := &pipelineRun{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, run); err != nil {
+		return nil, fmt.Errorf("failed to convert PipelineRun %s: %w", u.GetName(), err)
+	}
+	return run, nil
+}
+
+func summarise(run *pipelineRun) *Summary {
+	s := &Summary{
+		Name:    run.Name,
+		Commit:  shortCommit(run.param("COMMIT_SHA")),
+		Author:  run.param("COMMIT_AUTHOR"),
+		Ref:     run.param("GIT_REF"),
+		Status:  run.Status.status(),
+		Started: run.Status.StartTime,
+	}
+	if run.Status.StartTime != nil {
+		end := now()
+		if run.Status.CompletionTime != nil {
+			end = run.Status.CompletionTime.Time
+		}
+		s.Duration = end.Sub(run.Status.StartTime.Time).Round(time.Second)
+	}
+	return s
+}
+
+func (r *pipelineRun) param(name string) string {
+	for _, p := range r.Spec.Params {
+		if p.Name == name {
+			if v, ok := p.Value.(string); ok {
+				return v
+			}
+		}
+	}
+	return ""
+}
+
+// status returns the reason of the Succeeded condition, e.g. Succeeded,
+// Failed or Running, or Pending if the run hasn't started.
+func (s runStatus) status() string {
+	for _, c := range s.Conditions {
+		if c.Type != "Succeeded" {
+			continue
+		}
+		if c.Reason != "" {
+			return c.Reason
+		}
+		switch c.Status {
+		case "True":
+			return "Succeeded"
+		case "False":
+			return "Failed"
+		}
+		return "Running"
+	}
+	return "Pending"
+}
+
+func shortCommit(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// NewClients creates the Kubernetes and dynamic clients from the current
+// kubeconfig.
+func NewClients() (kubernetes.Interface, dynamic.Interface, error) {
+	cfg, err := clientconfig.GetRESTConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	kubeClient, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return kubeClient, client, nil
+}
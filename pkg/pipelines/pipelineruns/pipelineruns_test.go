@@ -0,0 +1,114 @@
+package pipelineruns
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/tektonapi"
+)
+
+const testNS = "cicd"
+
+var testTime = time.Date(2021, time.January, 1, 10, 0, 0, 0, time.UTC)
+
+func TestList(t *testing.T) {
+	defer func(f func() time.Time) { now = f }(now)
+	now = func() time.Time { return testTime.Add(10 * time.Minute) }
+	client := newFakeClient(config.TektonV1Beta1,
+		makePipelineRun("app-ci-1", "app-ci-build-from-push-taxi", 0, "True", "Succeeded", 90*time.Second),
+		makePipelineRun("app-ci-2", "app-ci-build-from-pr-taxi", time.Minute, "False", "Failed", 30*time.Second),
+		makePipelineRun("app-ci-3", "app-ci-build-from-push-taxi", 2*time.Minute, "Unknown", "Running", 0),
+		makePipelineRun("app-ci-4", "app-ci-build-from-push-bus", 3*time.Minute, "True", "Succeeded", time.Minute),
+	)
+
+	got, err := List(client, config.TektonV1Beta1, testNS, "taxi", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*Summary{
+		{Name: "app-ci-3", Commit: "abc1234", Author: "Test User", Ref: "main", Status: "Running", Started: timePtr(testTime.Add(2 * time.Minute)), Duration: 8 * time.Minute},
+		{Name: "app-ci-2", Commit: "abc1234", Author: "Test User", Ref: "main", Status: "Failed", Started: timePtr(testTime.Add(time.Minute)), Duration: 30 * time.Second},
+		{Name: "app-ci-1", Commit: "abc1234", Author: "Test User", Ref: "main", Status: "Succeeded", Started: timePtr(testTime), Duration: 90 * time.Second},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("List() failed:\n%s", diff)
+	}
+}
+
+func TestListWithLimit(t *testing.T) {
+	runs := []*unstructured.Unstructured{
+		makePipelineRun("app-ci-1", "app-ci-build-from-push-taxi", 0, "True", "Succeeded", time.Minute),
+		makePipelineRun("app-ci-2", "app-ci-build-from-push-bus", time.Minute, "True", "Succeeded", time.Minute),
+	}
+	for _, run := range runs {
+		run.SetAPIVersion(tektonapi.PipelineV1)
+	}
+	client := newFakeClient(config.TektonV1, runs[0], runs[1])
+
+	got, err := List(client, config.TektonV1, testNS, "", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 1 || got[0].Name != "app-ci-2" {
+		t.Fatalf("List() got %v, want the newest PipelineRun", got)
+	}
+}
+
+func newFakeClient(version string, objs ...runtime.Object) *fake.FakeDynamicClient {
+	return fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			tektonapi.PipelineRunsResource(version): "PipelineRunList",
+			tektonapi.TaskRunsResource(version):     "TaskRunList",
+		}, objs...)
+}
+
+// makePipelineRun creates a PipelineRun created by a trigger, that started at
+// the offset from the test time, and ran for the duration, if the duration
+// is zero, the run hasn't completed.
+func makePipelineRun(name, trigger string, offset time.Duration, status, reason string, duration time.Duration) *unstructured.Unstructured {
+	started := testTime.Add(offset)
+	runStatus := map[string]interface{}{
+		"startTime": started.Format(time.RFC3339),
+		"conditions": []interface{}{
+			map[string]interface{}{"type": "Succeeded", "status": status, "reason": reason},
+		},
+	}
+	if duration > 0 {
+		runStatus["completionTime"] = started.Add(duration).Format(time.RFC3339)
+	}
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "tekton.dev/v1beta1",
+			"kind":       "PipelineRun",
+			"metadata": map[string]interface{}{
+				"name":              name,
+				"namespace":         testNS,
+				"creationTimestamp": started.Format(time.RFC3339),
+				"labels":            map[string]interface{}{triggerLabel: trigger},
+			},
+			"spec": map[string]interface{}{
+				"params": []interface{}{
+					map[string]interface{}{"name": "COMMIT_SHA", "value": "abc1234def5678"},
+					map[string]interface{}{"name": "COMMIT_AUTHOR", "value": "Test User"},
+					map[string]interface{}{"name": "GIT_REF", "value": "main"},
+				},
+			},
+			"status": runStatus,
+		},
+	}
+}
+
+func timePtr(t time.Time) *metav1.Time {
+	mt := metav1.NewTime(t)
+	return &mt
+}
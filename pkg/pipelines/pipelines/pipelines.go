@@ -6,9 +6,13 @@ import (
 	"strings"
 
 	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/types"
 
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
 	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+	"github.com/redhat-developer/kam/pkg/pipelines/tasks"
 	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
 )
 
@@ -17,14 +21,68 @@ var (
 )
 
 const (
-	pipelineWorkspace = "shared-data"
+	pipelineWorkspace    = "shared-data"
+	commitStatusTaskName = tasks.CommitStatusTaskName
 	// PendingCommitStatusTask is a task that sets pending commit status
 	PendingCommitStatusTask = "set-pending-status"
+	// TestTask is the task that runs the tests of a service before its image
+	// is built.
+	TestTask = "run-tests"
+	// JUnitResult is the result of the TestTask with the JUnit report.
+	JUnitResult = "junit"
+	// ContextDirParam is the param with the directory of a service in its
+	// source repository.
+	ContextDirParam = "CONTEXT_DIR"
+	// ServiceParam is the param with the name of the service that the
+	// integration pipeline builds.
+	ServiceParam = "SERVICE"
+	// BaseRefParam is the param with the branch that a pull request is to be
+	// merged into.
+	BaseRefParam = "BASE_REF"
+	// CheckChangesTask is the task that checks whether a pull request changes
+	// the directory of a service.
+	CheckChangesTask = "check-changes"
+	// ChangedResult is the result of the CheckChangesTask, it is "true" if
+	// the pull request changes the directory of the service.
+	ChangedResult = "changed"
+
+	junitResultLimit = 3072
+	updateImageTask  = "update-gitops-image"
+	gitImage         = "alpine/git:v2.26.2"
 )
 
+// ImageUpdate configures the update of a service's image in the GitOps
+// repository after a successful build.
+type ImageUpdate struct {
+	// GitOpsRepoURL is the clone URL of the GitOps repository.
+	GitOpsRepoURL string
+	// Driver is the driver for the GitOps repository, github, gitlab,
+	// bitbucket, stash or gitea.
+	Driver string
+	// PullRequest opens a pull request with the update, rather than pushing
+	// it directly.
+	PullRequest bool
+}
+
 // CreateAppCIPipeline creates AppCIPipeline
-func CreateAppCIPipeline(name types.NamespacedName) *pipelinev1.Pipeline {
-	return &pipelinev1.Pipeline{
+//
+// If update is not nil, the image of the service is updated in the GitOps
+// repository once it has been built and pushed.
+//
+// The build-image task uses the strategy from build, if build is nil the image
+// is built with buildah.
+//
+// If test is not nil, the tests are run between cloning the source and
+// building the image, and the image is only built if they pass.
+//
+// If contextDir is not empty, it is the default of the CONTEXT_DIR param, and
+// the image is built and the tests are run in that directory of the source,
+// unless the build has its own context directory.
+func CreateAppCIPipeline(name types.NamespacedName, update *ImageUpdate, build *config.Build, test *config.Test, contextDir string) *pipelinev1.Pipeline {
+	if contextDir != "" {
+		build = contextBuild(build)
+	}
+	p := &pipelinev1.Pipeline{
 		TypeMeta:   pipelineTypeMeta,
 		ObjectMeta: meta.ObjectMeta(name),
 		Spec: pipelinev1.PipelineSpec{
@@ -41,8 +99,8 @@ func CreateAppCIPipeline(name types.NamespacedName) *pipelinev1.Pipeline {
 				"GIT_REPO"),
 			Tasks: []pipelinev1.PipelineTask{
 				createCommitStatusPipelineTask(PendingCommitStatusTask, "pending", "The build has started"),
-				createGitCloneTask("clone-source"),
-				createBuildImageTask("build-image", "clone-source"),
+				createGitCloneTask("clone-source", "$(params.GIT_REF)", PendingCommitStatusTask),
+				createBuildImageTask("build-image", "clone-source", build),
 			},
 			Workspaces: []pipelinev1.PipelineWorkspaceDeclaration{
 				{Name: pipelineWorkspace, Description: "This workspace will receive the cloned git repo."},
@@ -52,25 +110,441 @@ func CreateAppCIPipeline(name types.NamespacedName) *pipelinev1.Pipeline {
 			},
 		},
 	}
+	p.Spec.Params = append(p.Spec.Params, serviceParamSpec())
+	if contextDir != "" {
+		p.Spec.Params = append(p.Spec.Params, pipelinev1.ParamSpec{
+			Name:        ContextDirParam,
+			Type:        "string",
+			Description: "The directory of the service in the source repository.",
+			Default:     pipelinev1.NewArrayOrString(contextDir),
+		})
+	}
+	if test != nil {
+		testTask := createTestTask(TestTask, "clone-source", test)
+		if contextDir != "" && testTask.TaskSpec != nil {
+			testTask.TaskSpec.Steps[0].WorkingDir = "$(workspaces.source.path)/$(params." + ContextDirParam + ")"
+		}
+		p.Spec.Tasks = append(p.Spec.Tasks[:2], testTask, createBuildImageTask("build-image", TestTask, build))
+		p.Spec.Finally = createTestCommitStatusTasks(p.Spec.Finally[0])
+	}
+	if update != nil {
+		p.Spec.Params = append(p.Spec.Params, paramSpec("OVERLAY_PATH"))
+		p.Spec.Tasks = append(p.Spec.Tasks, createUpdateImageTask(updateImageTask, "build-image", update))
+		p.Spec.Finally = append(createUpdateCommitStatusTasks(p.Spec.Finally[0].WhenExpressions), p.Spec.Finally[1:]...)
+	}
+	return p
+}
+
+// contextBuild returns a copy of the build that builds the image in the
+// CONTEXT_DIR of the source, if the build has no context directory.
+func contextBuild(build *config.Build) *config.Build {
+	b := config.Build{}
+	if build != nil {
+		b = *build
+	}
+	if b.ContextDir != "" {
+		return &b
+	}
+	b.ContextDir = "$(params." + ContextDirParam + ")"
+	if b.Dockerfile == "" && b.GetStrategy() == config.BuildStrategyBuildah {
+		b.Dockerfile = b.ContextDir + "/Dockerfile"
+	}
+	return &b
+}
+
+// createTestTask runs the tests with the Task from the test, or with the
+// command in a container from its image.
+func createTestTask(name, runAfter string, test *config.Test) pipelinev1.PipelineTask {
+	task := pipelinev1.PipelineTask{
+		Name: name,
+		Workspaces: []pipelinev1.WorkspacePipelineTaskBinding{
+			{Name: "source", Workspace: pipelineWorkspace},
+		},
+		RunAfter: []string{runAfter},
+	}
+	if test.Task != "" {
+		task.TaskRef = createTaskRef(test.Task, pipelinev1.NamespacedTaskKind)
+		return task
+	}
+	spec := pipelinev1.TaskSpec{
+		Workspaces: []pipelinev1.WorkspaceDeclaration{
+			{Name: "source"},
+		},
+		Steps: []pipelinev1.Step{
+			{
+				Container: corev1.Container{
+					Name:       "run-tests",
+					Image:      test.Image,
+					WorkingDir: "$(workspaces.source.path)",
+				},
+				Script: testScript(test),
+			},
+		},
+	}
+	if test.JUnitPath != "" {
+		spec.Results = []pipelinev1.TaskResult{
+			{Name: JUnitResult, Description: "The JUnit report written by the tests."},
+		}
+	}
+	task.TaskSpec = &pipelinev1.EmbeddedTask{TaskSpec: spec}
+	return task
+}
+
+// testScript runs the command, and saves the JUnit report as a result, even
+// if the command fails.
+//
+// The command is run in a subshell that exits on the first failure, so that
+// the status of multi-line commands is the status of the first failing line.
+//
+// Results are limited in size, so only the start of the report is saved.
+func testScript(test *config.Test) string {
+	if test.JUnitPath == "" {
+		return "#!/bin/sh\nset -e\n" + test.Command + "\n"
+	}
+	return fmt.Sprintf(`#!/bin/sh
+(
+set -e
+%s
+)
+status=$?
+if [ -f "%s" ]; then
+  head -c %d "%s" > "$(results.%s.path)"
+fi
+exit $status
+`, test.Command, test.JUnitPath, junitResultLimit, test.JUnitPath, JUnitResult)
+}
+
+// createTestCommitStatusTasks reports the failure of the tests as the final
+// commit status, the build-image task is skipped when they fail, and so its
+// status can't be used.
+func createTestCommitStatusTasks(final pipelinev1.PipelineTask) []pipelinev1.PipelineTask {
+	testStatus := "$(tasks." + TestTask + ".status)"
+	final.WhenExpressions = pipelinev1.WhenExpressions{
+		{Input: testStatus, Operator: selection.NotIn, Values: []string{"Failed"}},
+	}
+	failed := createCommitStatusPipelineTask("set-test-failure-status", testStatus, "The tests failed")
+	failed.WhenExpressions = pipelinev1.WhenExpressions{
+		{Input: testStatus, Operator: selection.In, Values: []string{"Failed"}},
+	}
+	return []pipelinev1.PipelineTask{final, failed}
+}
+
+// createUpdateCommitStatusTasks reports the status of the update of the image
+// in the GitOps repository as the final commit status, the update is skipped
+// when the build fails, or for services without an overlay path, and then the
+// status of the build is reported.
+//
+// The when expressions are added to all of the tasks.
+func createUpdateCommitStatusTasks(when pipelinev1.WhenExpressions) []pipelinev1.PipelineTask {
+	const desc = "The build is complete"
+	buildStatus := "$(tasks.build-image.status)"
+	overlayPath := "$(params.OVERLAY_PATH)"
+	withWhen := func(task pipelinev1.PipelineTask, expressions ...pipelinev1.WhenExpression) pipelinev1.PipelineTask {
+		task.WhenExpressions = append(append(pipelinev1.WhenExpressions{}, when...), expressions...)
+		return task
+	}
+	return []pipelinev1.PipelineTask{
+		withWhen(createCommitStatusPipelineTask("set-final-status", "$(tasks."+updateImageTask+".status)", desc),
+			pipelinev1.WhenExpression{Input: buildStatus, Operator: selection.In, Values: []string{"Succeeded"}},
+			pipelinev1.WhenExpression{Input: overlayPath, Operator: selection.NotIn, Values: []string{""}}),
+		withWhen(createCommitStatusPipelineTask("set-build-status", buildStatus, desc),
+			pipelinev1.WhenExpression{Input: buildStatus, Operator: selection.In, Values: []string{"Succeeded"}},
+			pipelinev1.WhenExpression{Input: overlayPath, Operator: selection.In, Values: []string{""}}),
+		withWhen(createCommitStatusPipelineTask("set-build-failure-status", buildStatus, desc),
+			pipelinev1.WhenExpression{Input: buildStatus, Operator: selection.NotIn, Values: []string{"Succeeded"}}),
+	}
+}
+
+// The task is skipped for services that were added before the overlay path
+// was provided by their bindings.
+func createUpdateImageTask(name, runAfter string, update *ImageUpdate) pipelinev1.PipelineTask {
+	mode := tasks.UpdateModePush
+	if update.PullRequest {
+		mode = tasks.UpdateModePullRequest
+	}
+	return pipelinev1.PipelineTask{
+		Name:     name,
+		TaskRef:  createTaskRef(tasks.UpdateImageTaskName, pipelinev1.NamespacedTaskKind),
+		RunAfter: []string{runAfter},
+		Params: []pipelinev1.Param{
+			createTaskParam("GITOPS_REPO", update.GitOpsRepoURL),
+			createTaskParam("OVERLAY_PATH", "$(params.OVERLAY_PATH)"),
+			createTaskParam("IMAGE", "$(params.IMAGE)"),
+			createTaskParam("UPDATE_MODE", mode),
+			createTaskParam("GIT_DRIVER", update.Driver),
+		},
+		WhenExpressions: pipelinev1.WhenExpressions{
+			{Input: "$(params.OVERLAY_PATH)", Operator: selection.NotIn, Values: []string{""}},
+		},
+	}
+}
+
+// CreateAppCIPRPipeline creates a pipeline that builds the source of a pull
+// request, without pushing the image, and reports the commit status on the
+// head of the pull request.
+//
+// This is shared by the services, and doesn't run any tests, as the tests are
+// configured for each service or environment, services with tests have their
+// own pipeline from CreateServiceCIPRPipeline.
+func CreateAppCIPRPipeline(name types.NamespacedName) *pipelinev1.Pipeline {
+	return &pipelinev1.Pipeline{
+		TypeMeta:   pipelineTypeMeta,
+		ObjectMeta: meta.ObjectMeta(name),
+		Spec: pipelinev1.PipelineSpec{
+			Params: paramSpecs(
+				"REPO",
+				"COMMIT_SHA",
+				"TLSVERIFY",
+				"IMAGE",
+				"GIT_REF",
+				"GIT_REPO"),
+			Tasks: []pipelinev1.PipelineTask{
+				createCommitStatusPipelineTask(PendingCommitStatusTask, "pending", "The pull request build has started"),
+				createGitCloneTask("clone-source", "$(params.GIT_REF)", PendingCommitStatusTask),
+				createBuildImageWithoutPushTask("build-image", "clone-source"),
+			},
+			Workspaces: []pipelinev1.PipelineWorkspaceDeclaration{
+				{Name: pipelineWorkspace, Description: "This workspace will receive the cloned git repo."},
+			},
+			Finally: []pipelinev1.PipelineTask{
+				createCommitStatusPipelineTask("set-final-status", "$(tasks.build-image.status)", "The pull request build is complete"),
+			},
+		},
+	}
+}
+
+// CreateServiceCIPRPipeline creates a pipeline that checks the source of a
+// pull request for a service that configures how its image is built or
+// tested, or that is in a directory of its source repository.
+//
+// If test is not nil, the tests are run after cloning the source.
+//
+// Only images built with buildah can be built without pushing them, so the
+// image is built with the Dockerfile and context of the build, if the build
+// uses buildah, and services with other strategies only run their tests.
+//
+// If contextDir is not empty, the tests and the build are run in that
+// directory, and are skipped if the pull request doesn't change it, the pull
+// request events don't list the changed files, so this can't be checked by
+// the trigger.
+func CreateServiceCIPRPipeline(name types.NamespacedName, build *config.Build, test *config.Test, contextDir string) *pipelinev1.Pipeline {
+	if contextDir != "" {
+		build = contextBuild(build)
+	}
+	p := &pipelinev1.Pipeline{
+		TypeMeta:   pipelineTypeMeta,
+		ObjectMeta: meta.ObjectMeta(name),
+		Spec: pipelinev1.PipelineSpec{
+			Params: paramSpecs(
+				"REPO",
+				"COMMIT_SHA",
+				"TLSVERIFY",
+				"IMAGE",
+				"GIT_REF",
+				"GIT_REPO"),
+			Tasks: []pipelinev1.PipelineTask{
+				createCommitStatusPipelineTask(PendingCommitStatusTask, "pending", "The pull request build has started"),
+				createGitCloneTask("clone-source", "$(params.GIT_REF)", PendingCommitStatusTask),
+			},
+			Workspaces: []pipelinev1.PipelineWorkspaceDeclaration{
+				{Name: pipelineWorkspace, Description: "This workspace will receive the cloned git repo."},
+			},
+		},
+	}
+	runAfter := "clone-source"
+	var changed pipelinev1.WhenExpressions
+	if contextDir != "" {
+		p.Spec.Params = append(p.Spec.Params,
+			pipelinev1.ParamSpec{
+				Name:        ContextDirParam,
+				Type:        "string",
+				Description: "The directory of the service in the source repository.",
+				Default:     pipelinev1.NewArrayOrString(contextDir),
+			},
+			pipelinev1.ParamSpec{
+				Name:        BaseRefParam,
+				Type:        "string",
+				Description: "The branch that the pull request is to be merged into, if it's empty the pull request is assumed to change the service.",
+				Default:     pipelinev1.NewArrayOrString(""),
+			})
+		// The history is needed to find the changes since the base branch.
+		p.Spec.Tasks[1].Params = append(p.Spec.Tasks[1].Params, createTaskParam("depth", "0"))
+		p.Spec.Tasks = append(p.Spec.Tasks, createCheckChangesTask(CheckChangesTask, runAfter))
+		runAfter = CheckChangesTask
+		changed = pipelinev1.WhenExpressions{
+			{Input: "$(tasks." + CheckChangesTask + ".results." + ChangedResult + ")", Operator: selection.In, Values: []string{"true"}},
+		}
+	}
+	last := runAfter
+	if test != nil {
+		testTask := createTestTask(TestTask, runAfter, test)
+		if contextDir != "" && testTask.TaskSpec != nil {
+			testTask.TaskSpec.Steps[0].WorkingDir = "$(workspaces.source.path)/$(params." + ContextDirParam + ")"
+		}
+		testTask.WhenExpressions = changed
+		p.Spec.Tasks = append(p.Spec.Tasks, testTask)
+		runAfter = TestTask
+		last = TestTask
+	}
+	if build.GetStrategy() == config.BuildStrategyBuildah {
+		buildTask := createBuildImageWithoutPushTask("build-image", runAfter)
+		if build != nil {
+			buildTask.Params = appendOptionalParam(buildTask.Params, "DOCKERFILE", build.Dockerfile)
+			buildTask.Params = appendOptionalParam(buildTask.Params, "CONTEXT", build.ContextDir)
+			buildTask.Params = appendOptionalParam(buildTask.Params, "BUILD_EXTRA_ARGS", build.ExtraArgs)
+		}
+		buildTask.WhenExpressions = changed
+		p.Spec.Tasks = append(p.Spec.Tasks, buildTask)
+		last = "build-image"
+	}
+	p.Spec.Finally = []pipelinev1.PipelineTask{
+		createCommitStatusPipelineTask("set-final-status", "$(tasks."+last+".status)", "The pull request build is complete"),
+	}
+	if test != nil && last != TestTask {
+		p.Spec.Finally = createTestCommitStatusTasks(p.Spec.Finally[0])
+	}
+	if contextDir != "" {
+		for i := range p.Spec.Finally {
+			p.Spec.Finally[i].WhenExpressions = append(p.Spec.Finally[i].WhenExpressions, changed...)
+		}
+		unchanged := createCommitStatusPipelineTask("set-unchanged-status", "success", "The pull request doesn't change the service")
+		unchanged.WhenExpressions = pipelinev1.WhenExpressions{
+			{Input: changed[0].Input, Operator: selection.NotIn, Values: []string{"true"}},
+		}
+		p.Spec.Finally = append(p.Spec.Finally, unchanged)
+	}
+	return p
+}
+
+// createCheckChangesTask checks whether the pull request changes the
+// CONTEXT_DIR of the source, since the base branch.
+//
+// The result is "true" if the changes can't be checked, so that the service
+// is built rather than skipped.
+func createCheckChangesTask(name, runAfter string) pipelinev1.PipelineTask {
+	return pipelinev1.PipelineTask{
+		Name: name,
+		Workspaces: []pipelinev1.WorkspacePipelineTaskBinding{
+			{Name: "source", Workspace: pipelineWorkspace},
+		},
+		RunAfter: []string{runAfter},
+		TaskSpec: &pipelinev1.EmbeddedTask{
+			TaskSpec: pipelinev1.TaskSpec{
+				Workspaces: []pipelinev1.WorkspaceDeclaration{
+					{Name: "source"},
+				},
+				Results: []pipelinev1.TaskResult{
+					{Name: ChangedResult, Description: "Whether the pull request changes the directory of the service."},
+				},
+				Steps: []pipelinev1.Step{
+					{
+						Container: corev1.Container{
+							Name:       "check-changes",
+							Image:      gitImage,
+							WorkingDir: "$(workspaces.source.path)",
+						},
+						Script: checkChangesScript,
+					},
+				},
+			},
+		},
+	}
 }
 
-func createBuildImageTask(name, runAfter string) pipelinev1.PipelineTask {
+const checkChangesScript = `#!/bin/sh
+changed=true
+if [ -n "$(params.` + BaseRefParam + `)" ] && git fetch origin "$(params.` + BaseRefParam + `)"; then
+  if git diff --quiet FETCH_HEAD...HEAD -- "$(params.` + ContextDirParam + `)"; then
+    changed=false
+  fi
+fi
+printf "%s" "${changed}" > "$(results.` + ChangedResult + `.path)"
+`
+
+func createBuildImageWithoutPushTask(name, runAfter string) pipelinev1.PipelineTask {
 	return pipelinev1.PipelineTask{
 		Name:    name,
-		TaskRef: createTaskRef("buildah", pipelinev1.ClusterTaskKind),
+		TaskRef: createTaskRef(tasks.BuildImageTaskName, pipelinev1.NamespacedTaskKind),
 		Workspaces: []pipelinev1.WorkspacePipelineTaskBinding{
 			{Name: "source", Workspace: pipelineWorkspace},
 		},
 		RunAfter: []string{runAfter},
 		Params: []pipelinev1.Param{
 			createTaskParam("TLSVERIFY", "$(params.TLSVERIFY)"),
-			createTaskParam("BUILD_EXTRA_ARGS", metadataLabelArgs()),
 			createTaskParam("IMAGE", "$(params.IMAGE)"),
 		},
 	}
 }
 
-func createGitCloneTask(name string) pipelinev1.PipelineTask {
+func createBuildImageTask(name, runAfter string, build *config.Build) pipelinev1.PipelineTask {
+	task := pipelinev1.PipelineTask{
+		Name: name,
+		Workspaces: []pipelinev1.WorkspacePipelineTaskBinding{
+			{Name: "source", Workspace: pipelineWorkspace},
+		},
+		RunAfter: []string{runAfter},
+	}
+	if build == nil {
+		build = &config.Build{}
+	}
+	switch build.GetStrategy() {
+	case config.BuildStrategyS2I:
+		task.TaskRef = createTaskRef("s2i", pipelinev1.ClusterTaskKind)
+		task.Params = []pipelinev1.Param{
+			createTaskParam("BUILDER_IMAGE", build.BuilderImage),
+			createTaskParam("TLSVERIFY", "$(params.TLSVERIFY)"),
+			createTaskParam("IMAGE", "$(params.IMAGE)"),
+		}
+		task.Params = appendOptionalParam(task.Params, "PATH_CONTEXT", build.ContextDir)
+	case config.BuildStrategyBuildpacks:
+		task.TaskRef = createTaskRef(tasks.BuildpacksTaskName, pipelinev1.NamespacedTaskKind)
+		task.Params = []pipelinev1.Param{
+			createTaskParam("BUILDER_IMAGE", build.BuilderImage),
+			createTaskParam("APP_IMAGE", "$(params.IMAGE)"),
+		}
+		task.Params = appendOptionalParam(task.Params, "SOURCE_SUBPATH", build.ContextDir)
+	case config.BuildStrategyCustom:
+		task.TaskRef = createTaskRef(build.Task, pipelinev1.NamespacedTaskKind)
+		task.Params = []pipelinev1.Param{
+			createTaskParam("IMAGE", "$(params.IMAGE)"),
+		}
+		task.Params = appendOptionalParam(task.Params, "BUILDER_IMAGE", build.BuilderImage)
+		task.Params = appendOptionalParam(task.Params, "DOCKERFILE", build.Dockerfile)
+		task.Params = appendOptionalParam(task.Params, "CONTEXT", build.ContextDir)
+		task.Params = appendOptionalParam(task.Params, "BUILD_EXTRA_ARGS", build.ExtraArgs)
+	default:
+		extraArgs := metadataLabelArgs()
+		if build.ExtraArgs != "" {
+			extraArgs = extraArgs + " " + build.ExtraArgs
+		}
+		task.TaskRef = createTaskRef("buildah", pipelinev1.ClusterTaskKind)
+		task.Params = []pipelinev1.Param{
+			createTaskParam("TLSVERIFY", "$(params.TLSVERIFY)"),
+			createTaskParam("BUILD_EXTRA_ARGS", extraArgs),
+			createTaskParam("IMAGE", "$(params.IMAGE)"),
+		}
+		task.Params = appendOptionalParam(task.Params, "DOCKERFILE", build.Dockerfile)
+		task.Params = appendOptionalParam(task.Params, "CONTEXT", build.ContextDir)
+	}
+	keys := []string{}
+	for k := range build.Params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		task.Params = append(task.Params, createTaskParam(k, build.Params[k]))
+	}
+	return task
+}
+
+func appendOptionalParam(params []pipelinev1.Param, name, value string) []pipelinev1.Param {
+	if value == "" {
+		return params
+	}
+	return append(params, createTaskParam(name, value))
+}
+
+func createGitCloneTask(name, revision string, runAfter ...string) pipelinev1.PipelineTask {
 	// The output workspace mapping here comes from the git-clone task.
 	return pipelinev1.PipelineTask{
 		Name:    name,
@@ -80,9 +554,9 @@ func createGitCloneTask(name string) pipelinev1.PipelineTask {
 		},
 		Params: []pipelinev1.Param{
 			createTaskParam("url", "$(params.GIT_REPO)"),
-			createTaskParam("revision", "$(params.GIT_REF)"),
+			createTaskParam("revision", revision),
 		},
-		RunAfter: []string{PendingCommitStatusTask},
+		RunAfter: runAfter,
 	}
 }
 
@@ -92,12 +566,14 @@ func CreateCDPipeline(name types.NamespacedName, stageNamespace string) *pipelin
 		TypeMeta:   pipelineTypeMeta,
 		ObjectMeta: meta.ObjectMeta(name),
 		Spec: pipelinev1.PipelineSpec{
-			Resources: []pipelinev1.PipelineDeclaredResource{
-				createPipelineDeclaredResource("source-repo", "git"),
-			},
+			Params: paramSpecs("COMMIT_SHA", "GIT_REPO"),
 			Tasks: []pipelinev1.PipelineTask{
+				createGitCloneTask("clone-source", "$(params.COMMIT_SHA)"),
 				createCDPipelineTask("apply-source"),
 			},
+			Workspaces: []pipelinev1.PipelineWorkspaceDeclaration{
+				{Name: pipelineWorkspace, Description: "This workspace will receive the cloned git repo."},
+			},
 		},
 	}
 }
@@ -106,9 +582,10 @@ func createCDPipelineTask(taskName string) pipelinev1.PipelineTask {
 	return pipelinev1.PipelineTask{
 		Name:    taskName,
 		TaskRef: createTaskRef("deploy-from-source-task", pipelinev1.NamespacedTaskKind),
-		Resources: &pipelinev1.PipelineTaskResources{
-			Inputs: []pipelinev1.PipelineTaskInputResource{createInputTaskResource("source", "source-repo")},
+		Workspaces: []pipelinev1.WorkspacePipelineTaskBinding{
+			{Name: "source", Workspace: pipelineWorkspace},
 		},
+		RunAfter: []string{"clone-source"},
 	}
 }
 
@@ -118,16 +595,15 @@ func CreateCIPipeline(name types.NamespacedName, stageNamespace string) *pipelin
 		TypeMeta:   pipelineTypeMeta,
 		ObjectMeta: meta.ObjectMeta(name),
 		Spec: pipelinev1.PipelineSpec{
-
-			Resources: []pipelinev1.PipelineDeclaredResource{
-				createPipelineDeclaredResource("source-repo", "git"),
-			},
-
 			Tasks: []pipelinev1.PipelineTask{
 				createCommitStatusPipelineTask(PendingCommitStatusTask, "pending", "The build has started"),
+				createGitCloneTask("clone-source", "$(params.COMMIT_SHA)", PendingCommitStatusTask),
 				createCIPipelineTask("apply-source"),
 			},
-			Params: paramSpecs("REPO", "COMMIT_SHA", "GIT_REPO"),
+			Workspaces: []pipelinev1.PipelineWorkspaceDeclaration{
+				{Name: pipelineWorkspace, Description: "This workspace will receive the cloned git repo."},
+			},
+			Params: append(paramSpecs("REPO", "COMMIT_SHA", "GIT_REPO"), commitAuthorParamSpec()),
 			Finally: []pipelinev1.PipelineTask{
 				createCommitStatusPipelineTask("set-final-status", "$(tasks.apply-source.status)", "The build is complete"),
 			},
@@ -135,20 +611,47 @@ func CreateCIPipeline(name types.NamespacedName, stageNamespace string) *pipelin
 	}
 }
 
+// CreateCIPRPipeline creates a pipeline that dry-runs the changes in a pull
+// request to the GitOps repository, and reports the summary back to the pull
+// request as a comment, and a commit status.
+func CreateCIPRPipeline(name types.NamespacedName, driver string) *pipelinev1.Pipeline {
+	return &pipelinev1.Pipeline{
+		TypeMeta:   pipelineTypeMeta,
+		ObjectMeta: meta.ObjectMeta(name),
+		Spec: pipelinev1.PipelineSpec{
+			Tasks: []pipelinev1.PipelineTask{
+				createCommitStatusPipelineTask(PendingCommitStatusTask, "pending", "The dry-run has started"),
+				createGitCloneTask("clone-source", "$(params.COMMIT_SHA)", PendingCommitStatusTask),
+				createCIPipelineTask("apply-source"),
+			},
+			Workspaces: []pipelinev1.PipelineWorkspaceDeclaration{
+				{Name: pipelineWorkspace, Description: "This workspace will receive the cloned git repo."},
+			},
+			Params: append(paramSpecs("REPO", "COMMIT_SHA", "GIT_REPO", "PULL_REQUEST"), commitAuthorParamSpec()),
+			Finally: []pipelinev1.PipelineTask{
+				createCommitStatusPipelineTask("set-final-status", "$(tasks.apply-source.status)", "The dry-run is complete"),
+				createPRCommentPipelineTask("post-summary", driver,
+					"Dry-run of $(params.COMMIT_SHA) is complete:", tasks.SummaryFile),
+			},
+		},
+	}
+}
+
 // CreateAppCDPipeline creates AppCDPipelin
 func CreateAppCDPipeline(name types.NamespacedName, deploymentPath, devNamespace string, isInternalRegistry bool) *pipelinev1.Pipeline {
 	return &pipelinev1.Pipeline{
 		TypeMeta:   pipelineTypeMeta,
 		ObjectMeta: meta.ObjectMeta(name),
 		Spec: pipelinev1.PipelineSpec{
-			Resources: []pipelinev1.PipelineDeclaredResource{
-				createPipelineDeclaredResource("source-repo", "git"),
-				createPipelineDeclaredResource("runtime-image", "image"),
-			},
+			Params: paramSpecs("GIT_REPO", "GIT_REF", "IMAGE"),
 			Tasks: []pipelinev1.PipelineTask{
-				createDevCDBuildImageTask("build-image"),
+				createGitCloneTask("clone-source", "$(params.GIT_REF)"),
+				createDevCDBuildImageTask("build-image", "clone-source"),
 				createDevCDDeployImageTask("deploy-image", devNamespace, deploymentPath),
 			},
+			Workspaces: []pipelinev1.PipelineWorkspaceDeclaration{
+				{Name: pipelineWorkspace, Description: "This workspace will receive the cloned git repo."},
+			},
 		},
 	}
 }
@@ -157,13 +660,13 @@ func createCIPipelineTask(taskName string) pipelinev1.PipelineTask {
 	return pipelinev1.PipelineTask{
 		Name:    taskName,
 		TaskRef: createTaskRef("deploy-from-source-task", pipelinev1.NamespacedTaskKind),
-		Resources: &pipelinev1.PipelineTaskResources{
-			Inputs: []pipelinev1.PipelineTaskInputResource{createInputTaskResource("source", "source-repo")},
+		Workspaces: []pipelinev1.WorkspacePipelineTaskBinding{
+			{Name: "source", Workspace: pipelineWorkspace},
 		},
 		Params: []pipelinev1.Param{
 			createTaskParam("DRYRUN", "true"),
 		},
-		RunAfter: []string{PendingCommitStatusTask},
+		RunAfter: []string{"clone-source"},
 	}
 }
 
@@ -172,13 +675,11 @@ func createDevCDDeployImageTask(name, devNamespace, deploymentPath string) pipel
 		Name:     name,
 		TaskRef:  createTaskRef("deploy-using-kubectl-task", pipelinev1.NamespacedTaskKind),
 		RunAfter: []string{"build-image"},
-		Resources: &pipelinev1.PipelineTaskResources{
-			Inputs: []pipelinev1.PipelineTaskInputResource{
-				createInputTaskResource("source", "source-repo"),
-				createInputTaskResource("image", "runtime-image"),
-			},
+		Workspaces: []pipelinev1.WorkspacePipelineTaskBinding{
+			{Name: "source", Workspace: pipelineWorkspace},
 		},
 		Params: []pipelinev1.Param{
+			createTaskParam("IMAGE", "$(params.IMAGE)"),
 			createTaskParam("PATHTODEPLOYMENT", deploymentPath),
 			createTaskParam("YAMLPATHTOIMAGE", "spec.template.spec.containers[0].image"),
 			createTaskParam("NAMESPACE", devNamespace),
@@ -189,7 +690,7 @@ func createDevCDDeployImageTask(name, devNamespace, deploymentPath string) pipel
 func createCommitStatusPipelineTask(name, state, desc string) pipelinev1.PipelineTask {
 	return pipelinev1.PipelineTask{
 		Name:    name,
-		TaskRef: createTaskRef("set-commit-status", pipelinev1.NamespacedTaskKind),
+		TaskRef: createTaskRef(commitStatusTaskName, pipelinev1.NamespacedTaskKind),
 		Params: []pipelinev1.Param{
 			createTaskParam("REPO", "$(params.REPO)"),
 			createTaskParam("GIT_REPO", "$(params.GIT_REPO)"),
@@ -200,27 +701,101 @@ func createCommitStatusPipelineTask(name, state, desc string) pipelinev1.Pipelin
 	}
 }
 
-func createInputTaskResource(name, resource string) pipelinev1.PipelineTaskInputResource {
-	return pipelinev1.PipelineTaskInputResource{
-		Name:     name,
-		Resource: resource,
+// AddGitHost sets the driver and the API of the git host on the tasks that
+// report the commit status, the defaults of the task are the host of the
+// GitOps repository, and a service's repository can be on another host.
+func AddGitHost(p *pipelinev1.Pipeline, host tasks.GitHost) {
+	for _, pipelineTasks := range [][]pipelinev1.PipelineTask{p.Spec.Tasks, p.Spec.Finally} {
+		for i := range pipelineTasks {
+			if pipelineTasks[i].TaskRef == nil || pipelineTasks[i].TaskRef.Name != commitStatusTaskName {
+				continue
+			}
+			pipelineTasks[i].Params = append(pipelineTasks[i].Params,
+				createTaskParam("GIT_DRIVER", host.Driver),
+				createTaskParam("GIT_API_URL", host.APIURL))
+		}
 	}
 }
 
-func createDevCDBuildImageTask(name string) pipelinev1.PipelineTask {
-	return pipelinev1.PipelineTask{
+// createPRCommentPipelineTask creates a task that comments on the pull request,
+// the commentFile is read from the pipeline's workspace, and appended to the
+// comment if it's not empty.
+func createPRCommentPipelineTask(name, driver, comment, commentFile string) pipelinev1.PipelineTask {
+	task := pipelinev1.PipelineTask{
 		Name:    name,
-		TaskRef: createTaskRef("buildah", pipelinev1.ClusterTaskKind),
+		TaskRef: createTaskRef(tasks.PRCommentTaskName, pipelinev1.NamespacedTaskKind),
 		Params: []pipelinev1.Param{
-			createTaskParam("TLSVERIFY", "true"),
+			createTaskParam("GIT_DRIVER", driver),
+			createTaskParam("REPO", "$(params.REPO)"),
+			createTaskParam("GIT_REPO", "$(params.GIT_REPO)"),
+			createTaskParam("PULL_REQUEST", "$(params.PULL_REQUEST)"),
+			createTaskParam("COMMENT", comment),
 		},
 	}
+	if commentFile != "" {
+		task.Params = append(task.Params, createTaskParam("COMMENT_FILE", commentFile))
+		task.Workspaces = []pipelinev1.WorkspacePipelineTaskBinding{
+			{Name: "source", Workspace: pipelineWorkspace},
+		}
+	}
+	return task
 }
 
-func createOutputTaskResource(name, resource string) pipelinev1.PipelineTaskOutputResource {
-	return pipelinev1.PipelineTaskOutputResource{
-		Name:     name,
-		Resource: resource,
+// AddNotifications adds a finally task to the pipeline for each of the
+// notifications, that sends the status of the pipeline once its other tasks
+// have finished.
+//
+// The service is the name of the service in the messages, and can refer to the
+// params of the pipeline, if it's empty the messages have the REPO. If the
+// console URL is configured, the messages link to the PipelineRun in the
+// console, with the version of the Tekton API of the configuration.
+func AddNotifications(p *pipelinev1.Pipeline, service string, cfg *config.PipelinesConfig) {
+	for _, n := range cfg.Notifications {
+		p.Spec.Finally = append(p.Spec.Finally, createNotificationPipelineTask(n, service, cfg.ConsoleURL, cfg.GetTektonAPIVersion()))
+	}
+}
+
+func createNotificationPipelineTask(n *config.Notification, service, consoleURL, apiVersion string) pipelinev1.PipelineTask {
+	const status = "$(tasks.status)"
+	task := pipelinev1.PipelineTask{
+		Name:    "notify-" + n.Name,
+		TaskRef: createTaskRef(tasks.NotificationTaskName, pipelinev1.NamespacedTaskKind),
+		Params: []pipelinev1.Param{
+			createTaskParam("URL_SECRET_NAME", n.SecretName),
+			createTaskParam("URL_SECRET_KEY", n.GetSecretKey()),
+			createTaskParam("SERVICE", service),
+			createTaskParam("REPO", "$(params.REPO)"),
+			createTaskParam("COMMIT_SHA", "$(params.COMMIT_SHA)"),
+			createTaskParam("COMMIT_AUTHOR", "$(params.COMMIT_AUTHOR)"),
+			createTaskParam("STATUS", status),
+			createTaskParam("PIPELINE_RUN", "$(context.pipelineRun.name)"),
+			createTaskParam("PIPELINE_RUN_NAMESPACE", "$(context.pipelineRun.namespace)"),
+		},
+	}
+	if consoleURL != "" {
+		task.Params = append(task.Params, createTaskParam("PIPELINE_RUN_URL",
+			fmt.Sprintf("%s/k8s/ns/$(context.pipelineRun.namespace)/tekton.dev~%s~PipelineRun/$(context.pipelineRun.name)", strings.TrimSuffix(consoleURL, "/"), apiVersion)))
+	}
+	if n.OnlyFailures {
+		task.WhenExpressions = pipelinev1.WhenExpressions{
+			{Input: status, Operator: selection.In, Values: []string{"Failed"}},
+		}
+	}
+	return task
+}
+
+func createDevCDBuildImageTask(name, runAfter string) pipelinev1.PipelineTask {
+	return pipelinev1.PipelineTask{
+		Name:    name,
+		TaskRef: createTaskRef("buildah", pipelinev1.ClusterTaskKind),
+		Workspaces: []pipelinev1.WorkspacePipelineTaskBinding{
+			{Name: "source", Workspace: pipelineWorkspace},
+		},
+		RunAfter: []string{runAfter},
+		Params: []pipelinev1.Param{
+			createTaskParam("TLSVERIFY", "true"),
+			createTaskParam("IMAGE", "$(params.IMAGE)"),
+		},
 	}
 }
 
@@ -242,10 +817,6 @@ func createTaskParam(name, value string) pipelinev1.Param {
 	}
 }
 
-func createPipelineDeclaredResource(name, resourceType string) pipelinev1.PipelineDeclaredResource {
-	return pipelinev1.PipelineDeclaredResource{Name: name, Type: resourceType}
-}
-
 func metadataLabelArgs() string {
 	labels := map[string]string{
 		triggers.GitCommitID:      "$(params.COMMIT_SHA)",
@@ -273,3 +844,25 @@ func paramSpecs(s ...string) []pipelinev1.ParamSpec {
 func paramSpec(name string) pipelinev1.ParamSpec {
 	return pipelinev1.ParamSpec{Name: name, Type: "string"}
 }
+
+// serviceParamSpec is optional, as it is only used by the notifications, and
+// older service bindings don't provide the service name.
+func serviceParamSpec() pipelinev1.ParamSpec {
+	return pipelinev1.ParamSpec{
+		Name:        ServiceParam,
+		Type:        "string",
+		Description: "The name of the service that the image is built for.",
+		Default:     pipelinev1.NewArrayOrString(""),
+	}
+}
+
+// commitAuthorParamSpec is optional in the dry-run pipelines, as it is only
+// used by the notifications, and older TriggerTemplates don't provide it.
+func commitAuthorParamSpec() pipelinev1.ParamSpec {
+	return pipelinev1.ParamSpec{
+		Name:        "COMMIT_AUTHOR",
+		Type:        "string",
+		Description: "The name of the user that made the commit.",
+		Default:     pipelinev1.NewArrayOrString(""),
+	}
+}
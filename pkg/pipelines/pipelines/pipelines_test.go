@@ -1,14 +1,22 @@
 package pipelines
 
 import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/types"
 
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
 	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+	"github.com/redhat-developer/kam/pkg/pipelines/tasks"
 )
 
 func Test_createTaskParam(t *testing.T) {
@@ -51,13 +59,13 @@ func Test_paramSpecs(t *testing.T) {
 
 func TestCreateAppCIPipeline(t *testing.T) {
 	name := types.NamespacedName{Name: "test-pipeline", Namespace: "test-ns"}
-	p := CreateAppCIPipeline(name)
+	p := CreateAppCIPipeline(name, nil, nil, nil, "")
 
 	want := &pipelinev1.Pipeline{
 		TypeMeta:   pipelineTypeMeta,
 		ObjectMeta: meta.ObjectMeta(name),
 		Spec: pipelinev1.PipelineSpec{
-			Params: paramSpecs(
+			Params: append(paramSpecs(
 				"REPO",
 				"COMMIT_SHA",
 				"TLSVERIFY",
@@ -67,7 +75,7 @@ func TestCreateAppCIPipeline(t *testing.T) {
 				"COMMIT_DATE",
 				"COMMIT_AUTHOR",
 				"COMMIT_MESSAGE",
-				"GIT_REPO"),
+				"GIT_REPO"), serviceParamSpec()),
 			Workspaces: []pipelinev1.PipelineWorkspaceDeclaration{
 				{Name: pipelineWorkspace, Description: "This workspace will receive the cloned git repo."},
 			},
@@ -117,3 +125,629 @@ func TestCreateAppCIPipeline(t *testing.T) {
 		t.Fatalf("CreateAppCIPipeline failed:\n%s", diff)
 	}
 }
+
+func TestCreateAppCIPRPipeline(t *testing.T) {
+	name := types.NamespacedName{Name: "test-pr-pipeline", Namespace: "test-ns"}
+	p := CreateAppCIPRPipeline(name)
+
+	want := &pipelinev1.Pipeline{
+		TypeMeta:   pipelineTypeMeta,
+		ObjectMeta: meta.ObjectMeta(name),
+		Spec: pipelinev1.PipelineSpec{
+			Params: paramSpecs(
+				"REPO",
+				"COMMIT_SHA",
+				"TLSVERIFY",
+				"IMAGE",
+				"GIT_REF",
+				"GIT_REPO"),
+			Workspaces: []pipelinev1.PipelineWorkspaceDeclaration{
+				{Name: pipelineWorkspace, Description: "This workspace will receive the cloned git repo."},
+			},
+			Tasks: []pipelinev1.PipelineTask{
+				createCommitStatusPipelineTask(PendingCommitStatusTask, "pending", "The pull request build has started"),
+
+				{
+					Name:    "clone-source",
+					TaskRef: &pipelinev1.TaskRef{Name: "git-clone", Kind: "ClusterTask"},
+					Params: []pipelinev1.Param{
+						createTaskParam("url", "$(params.GIT_REPO)"),
+						createTaskParam("revision", "$(params.GIT_REF)"),
+					},
+					Workspaces: []pipelinev1.WorkspacePipelineTaskBinding{
+						{Name: "output", Workspace: pipelineWorkspace},
+					},
+					RunAfter: []string{PendingCommitStatusTask},
+				},
+
+				{
+					Name:     "build-image",
+					RunAfter: []string{"clone-source"},
+					TaskRef:  &pipelinev1.TaskRef{Name: "build-image-without-push", Kind: "Task"},
+					Workspaces: []pipelinev1.WorkspacePipelineTaskBinding{
+						{Name: "source", Workspace: pipelineWorkspace},
+					},
+					Params: []pipelinev1.Param{
+						createTaskParam("TLSVERIFY", "$(params.TLSVERIFY)"),
+						createTaskParam("IMAGE", "$(params.IMAGE)"),
+					},
+				},
+			},
+			Finally: []v1beta1.PipelineTask{
+				createCommitStatusPipelineTask("set-final-status", "$(tasks.build-image.status)", "The pull request build is complete"),
+			},
+		},
+	}
+
+	if diff := cmp.Diff(want, p); diff != "" {
+		t.Fatalf("CreateAppCIPRPipeline failed:\n%s", diff)
+	}
+}
+
+func TestCreateServiceCIPRPipeline(t *testing.T) {
+	name := types.NamespacedName{Name: "test-pr-pipeline", Namespace: "test-ns"}
+	build := &config.Build{Dockerfile: "./build/Dockerfile", ExtraArgs: "--build-arg=VERSION=1"}
+	p := CreateServiceCIPRPipeline(name, build, &config.Test{Task: "go-test"}, "")
+
+	wantTasks := []string{PendingCommitStatusTask, "clone-source", TestTask, "build-image"}
+	if diff := cmp.Diff(wantTasks, pipelineTaskNames(p.Spec.Tasks)); diff != "" {
+		t.Fatalf("CreateServiceCIPRPipeline tasks failed:\n%s", diff)
+	}
+	wantBuild := pipelinev1.PipelineTask{
+		Name:     "build-image",
+		RunAfter: []string{TestTask},
+		TaskRef:  &pipelinev1.TaskRef{Name: "build-image-without-push", Kind: "Task"},
+		Workspaces: []pipelinev1.WorkspacePipelineTaskBinding{
+			{Name: "source", Workspace: pipelineWorkspace},
+		},
+		Params: []pipelinev1.Param{
+			createTaskParam("TLSVERIFY", "$(params.TLSVERIFY)"),
+			createTaskParam("IMAGE", "$(params.IMAGE)"),
+			createTaskParam("DOCKERFILE", "./build/Dockerfile"),
+			createTaskParam("BUILD_EXTRA_ARGS", "--build-arg=VERSION=1"),
+		},
+	}
+	if diff := cmp.Diff(wantBuild, p.Spec.Tasks[3]); diff != "" {
+		t.Fatalf("CreateServiceCIPRPipeline build task failed:\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"set-final-status", "set-test-failure-status"}, pipelineTaskNames(p.Spec.Finally)); diff != "" {
+		t.Fatalf("CreateServiceCIPRPipeline finally tasks failed:\n%s", diff)
+	}
+}
+
+func TestCreateServiceCIPRPipelineWithContextDir(t *testing.T) {
+	name := types.NamespacedName{Name: "test-pr-pipeline", Namespace: "test-ns"}
+	p := CreateServiceCIPRPipeline(name, nil, nil, "services/api")
+
+	changed := pipelinev1.WhenExpressions{
+		{Input: "$(tasks.check-changes.results.changed)", Operator: selection.In, Values: []string{"true"}},
+	}
+	wantClone := createGitCloneTask("clone-source", "$(params.GIT_REF)", PendingCommitStatusTask)
+	wantClone.Params = append(wantClone.Params, createTaskParam("depth", "0"))
+	if diff := cmp.Diff(wantClone, p.Spec.Tasks[1]); diff != "" {
+		t.Fatalf("CreateServiceCIPRPipeline clone task failed:\n%s", diff)
+	}
+	if diff := cmp.Diff(changed, p.Spec.Tasks[3].WhenExpressions); diff != "" {
+		t.Fatalf("CreateServiceCIPRPipeline build task when expressions failed:\n%s", diff)
+	}
+	wantParams := []pipelinev1.Param{
+		createTaskParam("TLSVERIFY", "$(params.TLSVERIFY)"),
+		createTaskParam("IMAGE", "$(params.IMAGE)"),
+		createTaskParam("DOCKERFILE", "$(params.CONTEXT_DIR)/Dockerfile"),
+		createTaskParam("CONTEXT", "$(params.CONTEXT_DIR)"),
+	}
+	if diff := cmp.Diff(wantParams, p.Spec.Tasks[3].Params); diff != "" {
+		t.Fatalf("CreateServiceCIPRPipeline build task params failed:\n%s", diff)
+	}
+	if diff := cmp.Diff(changed, p.Spec.Finally[0].WhenExpressions); diff != "" {
+		t.Fatalf("CreateServiceCIPRPipeline final status when expressions failed:\n%s", diff)
+	}
+	unchanged := createCommitStatusPipelineTask("set-unchanged-status", "success", "The pull request doesn't change the service")
+	unchanged.WhenExpressions = pipelinev1.WhenExpressions{
+		{Input: "$(tasks.check-changes.results.changed)", Operator: selection.NotIn, Values: []string{"true"}},
+	}
+	if diff := cmp.Diff(unchanged, p.Spec.Finally[1]); diff != "" {
+		t.Fatalf("CreateServiceCIPRPipeline unchanged status failed:\n%s", diff)
+	}
+}
+
+// Only buildah can build images without pushing them, so the pull requests of
+// services with other strategies only run the tests.
+func TestCreateServiceCIPRPipelineWithS2I(t *testing.T) {
+	name := types.NamespacedName{Name: "test-pr-pipeline", Namespace: "test-ns"}
+	build := &config.Build{Strategy: config.BuildStrategyS2I, BuilderImage: "registry.access.redhat.com/ubi8/nodejs-14"}
+	p := CreateServiceCIPRPipeline(name, build, &config.Test{Task: "npm-test"}, "")
+
+	wantTasks := []string{PendingCommitStatusTask, "clone-source", TestTask}
+	if diff := cmp.Diff(wantTasks, pipelineTaskNames(p.Spec.Tasks)); diff != "" {
+		t.Fatalf("CreateServiceCIPRPipeline tasks failed:\n%s", diff)
+	}
+	want := createCommitStatusPipelineTask("set-final-status", "$(tasks.run-tests.status)", "The pull request build is complete")
+	if diff := cmp.Diff([]pipelinev1.PipelineTask{want}, p.Spec.Finally); diff != "" {
+		t.Fatalf("CreateServiceCIPRPipeline finally tasks failed:\n%s", diff)
+	}
+}
+
+func pipelineTaskNames(tasks []pipelinev1.PipelineTask) []string {
+	names := []string{}
+	for _, t := range tasks {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+func TestCheckChangesScript(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	origin := t.TempDir()
+	git(t, origin, "init", "-q", "-b", "main")
+	writeFile(t, filepath.Join(origin, "services", "api", "main.go"))
+	writeFile(t, filepath.Join(origin, "services", "web", "main.go"))
+	git(t, origin, "add", ".")
+	git(t, origin, "commit", "-q", "-m", "initial")
+
+	checkTests := []struct {
+		name    string
+		baseRef string
+		file    string
+		want    string
+	}{
+		{"change in the directory", "main", "services/api/handler.go", "true"},
+		{"change in another directory", "main", "services/web/handler.go", "false"},
+		{"no base branch", "", "services/web/handler.go", "true"},
+		{"unknown base branch", "unknown", "services/web/handler.go", "true"},
+	}
+
+	for _, tt := range checkTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			source := rt.TempDir()
+			git(rt, source, "clone", "-q", origin, ".")
+			git(rt, source, "checkout", "-q", "-b", "feature")
+			writeFile(rt, filepath.Join(source, filepath.FromSlash(tt.file)))
+			git(rt, source, "add", ".")
+			git(rt, source, "commit", "-q", "-m", "change")
+			result := filepath.Join(rt.TempDir(), "changed")
+			script := strings.NewReplacer(
+				"$(params.BASE_REF)", tt.baseRef,
+				"$(params.CONTEXT_DIR)", "services/api",
+				"$(results.changed.path)", result).Replace(checkChangesScript)
+
+			cmd := exec.Command("/bin/sh", "-c", script)
+			cmd.Dir = source
+			if out, err := cmd.CombinedOutput(); err != nil {
+				rt.Fatalf("script failed: %s\n%s", err, out)
+			}
+			got, err := os.ReadFile(result)
+			if err != nil {
+				rt.Fatal(err)
+			}
+			if string(got) != tt.want {
+				rt.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func git(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %s failed: %s\n%s", strings.Join(args, " "), err, out)
+	}
+}
+
+func writeFile(t *testing.T, name string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCreateCIPRPipeline(t *testing.T) {
+	name := types.NamespacedName{Name: "test-pr-pipeline", Namespace: "test-ns"}
+	p := CreateCIPRPipeline(name, "gitlab")
+
+	wantComment := pipelinev1.PipelineTask{
+		Name:    "post-summary",
+		TaskRef: &pipelinev1.TaskRef{Name: "post-pr-comment", Kind: "Task"},
+		Params: []pipelinev1.Param{
+			createTaskParam("GIT_DRIVER", "gitlab"),
+			createTaskParam("REPO", "$(params.REPO)"),
+			createTaskParam("GIT_REPO", "$(params.GIT_REPO)"),
+			createTaskParam("PULL_REQUEST", "$(params.PULL_REQUEST)"),
+			createTaskParam("COMMENT", "Dry-run of $(params.COMMIT_SHA) is complete:"),
+			createTaskParam("COMMENT_FILE", ".kam-summary.md"),
+		},
+		Workspaces: []pipelinev1.WorkspacePipelineTaskBinding{
+			{Name: "source", Workspace: pipelineWorkspace},
+		},
+	}
+	if diff := cmp.Diff(append(paramSpecs("REPO", "COMMIT_SHA", "GIT_REPO", "PULL_REQUEST"), commitAuthorParamSpec()), p.Spec.Params); diff != "" {
+		t.Fatalf("CreateCIPRPipeline params failed:\n%s", diff)
+	}
+	if diff := cmp.Diff(createGitCloneTask("clone-source", "$(params.COMMIT_SHA)", PendingCommitStatusTask), p.Spec.Tasks[1]); diff != "" {
+		t.Fatalf("CreateCIPRPipeline clone task failed:\n%s", diff)
+	}
+	if diff := cmp.Diff(createCIPipelineTask("apply-source"), p.Spec.Tasks[2]); diff != "" {
+		t.Fatalf("CreateCIPRPipeline dry-run task failed:\n%s", diff)
+	}
+	if diff := cmp.Diff(wantComment, p.Spec.Finally[1]); diff != "" {
+		t.Fatalf("CreateCIPRPipeline comment task failed:\n%s", diff)
+	}
+}
+
+// The finally tasks that refer to the results of a failed task are skipped, so
+// the summary is posted from the workspace when the dry-run fails.
+func TestCreateCIPRPipelinePostsSummaryWhenApplyFails(t *testing.T) {
+	p := CreateCIPRPipeline(types.NamespacedName{Name: "test-pr-pipeline", Namespace: "test-ns"}, "github")
+
+	for _, task := range p.Spec.Finally {
+		for _, param := range task.Params {
+			if strings.Contains(param.Value.StringVal, "$(tasks.apply-source.results.") {
+				t.Errorf("finally task %s refers to the results of apply-source in param %s", task.Name, param.Name)
+			}
+		}
+	}
+}
+
+func TestCreateAppCIPipelineWithImageUpdate(t *testing.T) {
+	name := types.NamespacedName{Name: "test-pipeline", Namespace: "test-ns"}
+	p := CreateAppCIPipeline(name, &ImageUpdate{GitOpsRepoURL: "https://github.com/org/gitops.git", Driver: "github", PullRequest: true}, nil, nil, "")
+
+	if diff := cmp.Diff(paramSpec("OVERLAY_PATH"), p.Spec.Params[len(p.Spec.Params)-1]); diff != "" {
+		t.Fatalf("CreateAppCIPipeline params failed:\n%s", diff)
+	}
+	want := pipelinev1.PipelineTask{
+		Name:     "update-gitops-image",
+		TaskRef:  &pipelinev1.TaskRef{Name: "update-gitops-image", Kind: "Task"},
+		RunAfter: []string{"build-image"},
+		Params: []pipelinev1.Param{
+			createTaskParam("GITOPS_REPO", "https://github.com/org/gitops.git"),
+			createTaskParam("OVERLAY_PATH", "$(params.OVERLAY_PATH)"),
+			createTaskParam("IMAGE", "$(params.IMAGE)"),
+			createTaskParam("UPDATE_MODE", "pull-request"),
+			createTaskParam("GIT_DRIVER", "github"),
+		},
+		WhenExpressions: pipelinev1.WhenExpressions{
+			{Input: "$(params.OVERLAY_PATH)", Operator: "notin", Values: []string{""}},
+		},
+	}
+	if diff := cmp.Diff(want, p.Spec.Tasks[len(p.Spec.Tasks)-1]); diff != "" {
+		t.Fatalf("CreateAppCIPipeline update task failed:\n%s", diff)
+	}
+}
+
+func TestCreateAppCIPipelineWithImageUpdateStatus(t *testing.T) {
+	name := types.NamespacedName{Name: "test-pipeline", Namespace: "test-ns"}
+	update := &ImageUpdate{GitOpsRepoURL: "https://github.com/org/gitops.git", Driver: "github"}
+	p := CreateAppCIPipeline(name, update, nil, &config.Test{Task: "go-test"}, "")
+
+	buildStatus := "$(tasks.build-image.status)"
+	notTestFailure := pipelinev1.WhenExpression{Input: "$(tasks.run-tests.status)", Operator: selection.NotIn, Values: []string{"Failed"}}
+	withWhen := func(task pipelinev1.PipelineTask, expressions ...pipelinev1.WhenExpression) pipelinev1.PipelineTask {
+		task.WhenExpressions = expressions
+		return task
+	}
+	testFailure := createCommitStatusPipelineTask("set-test-failure-status", "$(tasks.run-tests.status)", "The tests failed")
+	want := []pipelinev1.PipelineTask{
+		withWhen(createCommitStatusPipelineTask("set-final-status", "$(tasks.update-gitops-image.status)", "The build is complete"),
+			notTestFailure,
+			pipelinev1.WhenExpression{Input: buildStatus, Operator: selection.In, Values: []string{"Succeeded"}},
+			pipelinev1.WhenExpression{Input: "$(params.OVERLAY_PATH)", Operator: selection.NotIn, Values: []string{""}}),
+		withWhen(createCommitStatusPipelineTask("set-build-status", buildStatus, "The build is complete"),
+			notTestFailure,
+			pipelinev1.WhenExpression{Input: buildStatus, Operator: selection.In, Values: []string{"Succeeded"}},
+			pipelinev1.WhenExpression{Input: "$(params.OVERLAY_PATH)", Operator: selection.In, Values: []string{""}}),
+		withWhen(createCommitStatusPipelineTask("set-build-failure-status", buildStatus, "The build is complete"),
+			notTestFailure,
+			pipelinev1.WhenExpression{Input: buildStatus, Operator: selection.NotIn, Values: []string{"Succeeded"}}),
+		withWhen(testFailure, pipelinev1.WhenExpression{Input: "$(tasks.run-tests.status)", Operator: selection.In, Values: []string{"Failed"}}),
+	}
+	if diff := cmp.Diff(want, p.Spec.Finally); diff != "" {
+		t.Fatalf("CreateAppCIPipeline final status failed:\n%s", diff)
+	}
+}
+
+func TestCreateAppCIPipelineWithBuild(t *testing.T) {
+	buildTests := []struct {
+		name  string
+		build *config.Build
+		want  pipelinev1.PipelineTask
+	}{
+		{
+			"buildah with a dockerfile",
+			&config.Build{Dockerfile: "./build/Dockerfile", ContextDir: "src", ExtraArgs: "--squash"},
+			pipelinev1.PipelineTask{
+				TaskRef: &pipelinev1.TaskRef{Name: "buildah", Kind: "ClusterTask"},
+				Params: []pipelinev1.Param{
+					createTaskParam("TLSVERIFY", "$(params.TLSVERIFY)"),
+					createTaskParam("BUILD_EXTRA_ARGS", metadataLabelArgs()+" --squash"),
+					createTaskParam("IMAGE", "$(params.IMAGE)"),
+					createTaskParam("DOCKERFILE", "./build/Dockerfile"),
+					createTaskParam("CONTEXT", "src"),
+				},
+			},
+		},
+		{
+			"s2i",
+			&config.Build{Strategy: config.BuildStrategyS2I, BuilderImage: "registry.access.redhat.com/ubi8/nodejs-14", ContextDir: "web"},
+			pipelinev1.PipelineTask{
+				TaskRef: &pipelinev1.TaskRef{Name: "s2i", Kind: "ClusterTask"},
+				Params: []pipelinev1.Param{
+					createTaskParam("BUILDER_IMAGE", "registry.access.redhat.com/ubi8/nodejs-14"),
+					createTaskParam("TLSVERIFY", "$(params.TLSVERIFY)"),
+					createTaskParam("IMAGE", "$(params.IMAGE)"),
+					createTaskParam("PATH_CONTEXT", "web"),
+				},
+			},
+		},
+		{
+			"buildpacks",
+			&config.Build{Strategy: config.BuildStrategyBuildpacks, BuilderImage: "paketobuildpacks/builder:base", Params: map[string]string{"RUN_IMAGE": "paketobuildpacks/run:base", "CACHE": "cache"}},
+			pipelinev1.PipelineTask{
+				TaskRef: &pipelinev1.TaskRef{Name: "buildpacks", Kind: "Task"},
+				Params: []pipelinev1.Param{
+					createTaskParam("BUILDER_IMAGE", "paketobuildpacks/builder:base"),
+					createTaskParam("APP_IMAGE", "$(params.IMAGE)"),
+					createTaskParam("CACHE", "cache"),
+					createTaskParam("RUN_IMAGE", "paketobuildpacks/run:base"),
+				},
+			},
+		},
+		{
+			"custom",
+			&config.Build{Strategy: config.BuildStrategyCustom, Task: "kaniko", Dockerfile: "Containerfile"},
+			pipelinev1.PipelineTask{
+				TaskRef: &pipelinev1.TaskRef{Name: "kaniko", Kind: "Task"},
+				Params: []pipelinev1.Param{
+					createTaskParam("IMAGE", "$(params.IMAGE)"),
+					createTaskParam("DOCKERFILE", "Containerfile"),
+				},
+			},
+		},
+	}
+
+	name := types.NamespacedName{Name: "test-pipeline", Namespace: "test-ns"}
+	for _, tt := range buildTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			p := CreateAppCIPipeline(name, nil, tt.build, nil, "")
+			want := tt.want
+			want.Name = "build-image"
+			want.RunAfter = []string{"clone-source"}
+			want.Workspaces = []pipelinev1.WorkspacePipelineTaskBinding{
+				{Name: "source", Workspace: "shared-data"},
+			}
+			if diff := cmp.Diff(want, p.Spec.Tasks[2]); diff != "" {
+				rt.Fatalf("CreateAppCIPipeline build task failed:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTestScriptFailsOnAnyLine(t *testing.T) {
+	dir := t.TempDir()
+	script := testScript(&config.Test{Command: "false\necho '<testsuites/>' > report.xml", JUnitPath: "report.xml"})
+	script = strings.ReplaceAll(script, "$(results.junit.path)", filepath.Join(dir, "junit"))
+
+	cmd := exec.Command("/bin/sh", "-c", script)
+	cmd.Dir = dir
+	if err := cmd.Run(); err == nil {
+		t.Fatal("script didn't fail when the first line of the command failed")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "report.xml")); !os.IsNotExist(err) {
+		t.Fatalf("the command wasn't stopped by the failure: %v", err)
+	}
+}
+
+func TestCreateAppCIPipelineWithTest(t *testing.T) {
+	name := types.NamespacedName{Name: "test-pipeline", Namespace: "test-ns"}
+	p := CreateAppCIPipeline(name, nil, nil, &config.Test{Image: "golang:1.17", Command: "go test ./... 2>&1 | go-junit-report > report.xml", JUnitPath: "report.xml"}, "")
+
+	wantTest := pipelinev1.PipelineTask{
+		Name: "run-tests",
+		TaskSpec: &pipelinev1.EmbeddedTask{
+			TaskSpec: pipelinev1.TaskSpec{
+				Workspaces: []pipelinev1.WorkspaceDeclaration{{Name: "source"}},
+				Results: []pipelinev1.TaskResult{
+					{Name: "junit", Description: "The JUnit report written by the tests."},
+				},
+				Steps: []pipelinev1.Step{
+					{
+						Container: corev1.Container{
+							Name:       "run-tests",
+							Image:      "golang:1.17",
+							WorkingDir: "$(workspaces.source.path)",
+						},
+						Script: `#!/bin/sh
+(
+set -e
+go test ./... 2>&1 | go-junit-report > report.xml
+)
+status=$?
+if [ -f "report.xml" ]; then
+  head -c 3072 "report.xml" > "$(results.junit.path)"
+fi
+exit $status
+`,
+					},
+				},
+			},
+		},
+		Workspaces: []pipelinev1.WorkspacePipelineTaskBinding{
+			{Name: "source", Workspace: "shared-data"},
+		},
+		RunAfter: []string{"clone-source"},
+	}
+	if diff := cmp.Diff(wantTest, p.Spec.Tasks[2]); diff != "" {
+		t.Fatalf("CreateAppCIPipeline test task failed:\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"run-tests"}, p.Spec.Tasks[3].RunAfter); diff != "" {
+		t.Fatalf("CreateAppCIPipeline build task failed:\n%s", diff)
+	}
+
+	finalStatus := createCommitStatusPipelineTask("set-final-status", "$(tasks.build-image.status)", "The build is complete")
+	finalStatus.WhenExpressions = pipelinev1.WhenExpressions{
+		{Input: "$(tasks.run-tests.status)", Operator: "notin", Values: []string{"Failed"}},
+	}
+	failureStatus := createCommitStatusPipelineTask("set-test-failure-status", "$(tasks.run-tests.status)", "The tests failed")
+	failureStatus.WhenExpressions = pipelinev1.WhenExpressions{
+		{Input: "$(tasks.run-tests.status)", Operator: "in", Values: []string{"Failed"}},
+	}
+	if diff := cmp.Diff([]pipelinev1.PipelineTask{finalStatus, failureStatus}, p.Spec.Finally); diff != "" {
+		t.Fatalf("CreateAppCIPipeline finally tasks failed:\n%s", diff)
+	}
+}
+
+func TestCreateAppCIPipelineWithTestTask(t *testing.T) {
+	name := types.NamespacedName{Name: "test-pipeline", Namespace: "test-ns"}
+	p := CreateAppCIPipeline(name, nil, nil, &config.Test{Task: "go-test"}, "")
+
+	want := pipelinev1.PipelineTask{
+		Name:    "run-tests",
+		TaskRef: &pipelinev1.TaskRef{Name: "go-test", Kind: "Task"},
+		Workspaces: []pipelinev1.WorkspacePipelineTaskBinding{
+			{Name: "source", Workspace: "shared-data"},
+		},
+		RunAfter: []string{"clone-source"},
+	}
+	if diff := cmp.Diff(want, p.Spec.Tasks[2]); diff != "" {
+		t.Fatalf("CreateAppCIPipeline test task failed:\n%s", diff)
+	}
+}
+
+func TestCreateAppCIPipelineWithContextDir(t *testing.T) {
+	name := types.NamespacedName{Name: "test-pipeline", Namespace: "test-ns"}
+	p := CreateAppCIPipeline(name, nil, nil, &config.Test{Image: "golang:1.17", Command: "go test ./..."}, "services/api")
+
+	wantParam := pipelinev1.ParamSpec{
+		Name:        "CONTEXT_DIR",
+		Type:        "string",
+		Description: "The directory of the service in the source repository.",
+		Default:     pipelinev1.NewArrayOrString("services/api"),
+	}
+	if diff := cmp.Diff(wantParam, p.Spec.Params[len(p.Spec.Params)-1]); diff != "" {
+		t.Fatalf("CreateAppCIPipeline params failed:\n%s", diff)
+	}
+	if got := p.Spec.Tasks[2].TaskSpec.Steps[0].WorkingDir; got != "$(workspaces.source.path)/$(params.CONTEXT_DIR)" {
+		t.Fatalf("CreateAppCIPipeline test task got working dir %q", got)
+	}
+	wantBuildParams := []pipelinev1.Param{
+		createTaskParam("TLSVERIFY", "$(params.TLSVERIFY)"),
+		createTaskParam("BUILD_EXTRA_ARGS", metadataLabelArgs()),
+		createTaskParam("IMAGE", "$(params.IMAGE)"),
+		createTaskParam("DOCKERFILE", "$(params.CONTEXT_DIR)/Dockerfile"),
+		createTaskParam("CONTEXT", "$(params.CONTEXT_DIR)"),
+	}
+	if diff := cmp.Diff(wantBuildParams, p.Spec.Tasks[3].Params); diff != "" {
+		t.Fatalf("CreateAppCIPipeline build task failed:\n%s", diff)
+	}
+}
+
+func TestCreateAppCIPipelineWithContextDirAndBuildContext(t *testing.T) {
+	name := types.NamespacedName{Name: "test-pipeline", Namespace: "test-ns"}
+	build := &config.Build{Strategy: config.BuildStrategyS2I, BuilderImage: "nodejs", ContextDir: "services"}
+	p := CreateAppCIPipeline(name, nil, build, nil, "services/api")
+
+	want := []pipelinev1.Param{
+		createTaskParam("BUILDER_IMAGE", "nodejs"),
+		createTaskParam("TLSVERIFY", "$(params.TLSVERIFY)"),
+		createTaskParam("IMAGE", "$(params.IMAGE)"),
+		createTaskParam("PATH_CONTEXT", "services"),
+	}
+	if diff := cmp.Diff(want, p.Spec.Tasks[2].Params); diff != "" {
+		t.Fatalf("CreateAppCIPipeline build task failed:\n%s", diff)
+	}
+}
+
+func TestAddNotifications(t *testing.T) {
+	name := types.NamespacedName{Name: "test-pipeline", Namespace: "test-ns"}
+	p := CreateAppCIPipeline(name, nil, nil, nil, "")
+	AddNotifications(p, "taxi", &config.PipelinesConfig{
+		ConsoleURL: "https://console.example.com/",
+		Notifications: []*config.Notification{
+			{Name: "chat", SecretName: "chat-webhook"},
+			{Name: "alerts", SecretName: "alerts-webhook", SecretKey: "endpoint", OnlyFailures: true},
+		},
+	})
+
+	want := []pipelinev1.PipelineTask{
+		{
+			Name:    "notify-chat",
+			TaskRef: &pipelinev1.TaskRef{Name: "send-notification", Kind: "Task"},
+			Params: []pipelinev1.Param{
+				createTaskParam("URL_SECRET_NAME", "chat-webhook"),
+				createTaskParam("URL_SECRET_KEY", "url"),
+				createTaskParam("SERVICE", "taxi"),
+				createTaskParam("REPO", "$(params.REPO)"),
+				createTaskParam("COMMIT_SHA", "$(params.COMMIT_SHA)"),
+				createTaskParam("COMMIT_AUTHOR", "$(params.COMMIT_AUTHOR)"),
+				createTaskParam("STATUS", "$(tasks.status)"),
+				createTaskParam("PIPELINE_RUN", "$(context.pipelineRun.name)"),
+				createTaskParam("PIPELINE_RUN_NAMESPACE", "$(context.pipelineRun.namespace)"),
+				createTaskParam("PIPELINE_RUN_URL", "https://console.example.com/k8s/ns/$(context.pipelineRun.namespace)/tekton.dev~v1beta1~PipelineRun/$(context.pipelineRun.name)"),
+			},
+		},
+		{
+			Name:    "notify-alerts",
+			TaskRef: &pipelinev1.TaskRef{Name: "send-notification", Kind: "Task"},
+			Params: []pipelinev1.Param{
+				createTaskParam("URL_SECRET_NAME", "alerts-webhook"),
+				createTaskParam("URL_SECRET_KEY", "endpoint"),
+				createTaskParam("SERVICE", "taxi"),
+				createTaskParam("REPO", "$(params.REPO)"),
+				createTaskParam("COMMIT_SHA", "$(params.COMMIT_SHA)"),
+				createTaskParam("COMMIT_AUTHOR", "$(params.COMMIT_AUTHOR)"),
+				createTaskParam("STATUS", "$(tasks.status)"),
+				createTaskParam("PIPELINE_RUN", "$(context.pipelineRun.name)"),
+				createTaskParam("PIPELINE_RUN_NAMESPACE", "$(context.pipelineRun.namespace)"),
+				createTaskParam("PIPELINE_RUN_URL", "https://console.example.com/k8s/ns/$(context.pipelineRun.namespace)/tekton.dev~v1beta1~PipelineRun/$(context.pipelineRun.name)"),
+			},
+			WhenExpressions: pipelinev1.WhenExpressions{
+				{Input: "$(tasks.status)", Operator: "in", Values: []string{"Failed"}},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, p.Spec.Finally[1:]); diff != "" {
+		t.Fatalf("AddNotifications failed:\n%s", diff)
+	}
+}
+
+func TestAddGitHost(t *testing.T) {
+	name := types.NamespacedName{Name: "test-pipeline", Namespace: "test-ns"}
+	p := CreateAppCIPipeline(name, nil, nil, nil, "")
+	AddGitHost(p, tasks.GitHost{Driver: "gitlab", APIURL: "https://gitlab.example.com/api/v4"})
+
+	hostParams := []pipelinev1.Param{
+		createTaskParam("GIT_DRIVER", "gitlab"),
+		createTaskParam("GIT_API_URL", "https://gitlab.example.com/api/v4"),
+	}
+	for _, task := range []pipelinev1.PipelineTask{p.Spec.Tasks[0], p.Spec.Finally[0]} {
+		if diff := cmp.Diff(hostParams, task.Params[len(task.Params)-2:]); diff != "" {
+			t.Fatalf("AddGitHost %s failed:\n%s", task.Name, diff)
+		}
+	}
+	if diff := cmp.Diff(CreateAppCIPipeline(name, nil, nil, nil, "").Spec.Tasks[1:], p.Spec.Tasks[1:]); diff != "" {
+		t.Fatalf("AddGitHost changed other tasks:\n%s", diff)
+	}
+}
+
+func TestAddNotificationsWithTektonV1(t *testing.T) {
+	name := types.NamespacedName{Name: "test-pipeline", Namespace: "test-ns"}
+	p := CreateAppCIPipeline(name, nil, nil, nil, "")
+	AddNotifications(p, "taxi", &config.PipelinesConfig{
+		ConsoleURL:       "https://console.example.com",
+		TektonAPIVersion: config.TektonV1,
+		Notifications:    []*config.Notification{{Name: "chat", SecretName: "chat-webhook"}},
+	})
+
+	want := createTaskParam("PIPELINE_RUN_URL", "https://console.example.com/k8s/ns/$(context.pipelineRun.namespace)/tekton.dev~v1~PipelineRun/$(context.pipelineRun.name)")
+	params := p.Spec.Finally[len(p.Spec.Finally)-1].Params
+	if diff := cmp.Diff(want, params[len(params)-1]); diff != "" {
+		t.Fatalf("AddNotifications failed:\n%s", diff)
+	}
+}
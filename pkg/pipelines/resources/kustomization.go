@@ -4,9 +4,10 @@ import "sort"
 
 // Kustomization is a structural representation of the Kustomize file format.
 type Kustomization struct {
-	Resources    []string          `json:"resources,omitempty"`
-	Bases        []string          `json:"bases,omitempty"`
-	CommonLabels map[string]string `json:"commonLabels,omitempty"`
+	Resources         []string          `json:"resources,omitempty"`
+	Bases             []string          `json:"bases,omitempty"`
+	CommonLabels      map[string]string `json:"commonLabels,omitempty"`
+	CommonAnnotations map[string]string `json:"commonAnnotations,omitempty"`
 }
 
 func (k *Kustomization) AddResources(s ...string) {
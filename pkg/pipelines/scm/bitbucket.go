@@ -0,0 +1,206 @@
+package scm
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/jenkins-x/go-scm/scm/factory"
+	"github.com/redhat-developer/kam/pkg/pipelines/giturl"
+	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+const (
+	bitbucketPushEventFilters = "header.match('X-Event-Key', 'repo:push') && body.repository.full_name == '%s'"
+	bitbucketPREventFilters   = "(header.match('X-Event-Key', 'pullrequest:created') || header.match('X-Event-Key', 'pullrequest:updated')) && body.repository.full_name == '%s'"
+	bitbucketType             = "bitbucket"
+	bitbucketHost             = "bitbucket.org"
+
+	// Bitbucket Cloud reports the pushed branch or tag in the changes.
+	bitbucketPushRef = "('refs/' + (body.push.changes[0].new.type == 'tag' ? 'tags/' : 'heads/') + body.push.changes[0].new.name)"
+
+	// webhookSecretParam is the query param of the webhook URL with the
+	// secret, it is the param that go-scm adds the secret to when it creates
+	// Bitbucket webhooks.
+	webhookSecretParam = "secret"
+)
+
+type bitbucketSpec struct {
+	pushBinding string
+	prBinding   string
+}
+
+func init() {
+	gits[bitbucketType] = newBitbucket
+	factory.DefaultIdentifier = NewDriverIdentifier()
+}
+
+// NewDriverIdentifier returns a go-scm driver identifier with the mappings,
+// and the hosts that kam supports that go-scm doesn't identify, i.e.
+// bitbucket.org.
+func NewDriverIdentifier(extras ...factory.MappingFunc) factory.HostDriverIdentifier {
+	return factory.NewDriverIdentifier(append([]factory.MappingFunc{factory.Mapping(bitbucketHost, bitbucketType)}, extras...)...)
+}
+
+func newBitbucket(rawURL string) (Repository, error) {
+	path, err := processRawURL(rawURL, proccessBitbucketPath)
+	if err != nil {
+		return nil, err
+	}
+	return &repository{url: rawURL, path: path, spec: &bitbucketSpec{pushBinding: "bitbucket-push-binding", prBinding: "bitbucket-pr-binding"}}, nil
+}
+
+func proccessBitbucketPath(parsedURL *giturl.URL) (string, error) {
+	components, err := splitRepositoryPath(parsedURL)
+	if err != nil {
+		return "", err
+	}
+	if len(components) != 2 {
+		return "", invalidRepoPathError(bitbucketType, parsedURL.Path)
+	}
+	return strings.Join(components, "/"), nil
+}
+
+func (r *bitbucketSpec) pushBindingName() string {
+	return r.pushBinding
+}
+
+// A push can update several branches and tags, only the first is built.
+func (r *bitbucketSpec) pushBindingParams() []triggersv1.Param {
+	return []triggersv1.Param{
+		createBindingParam("gitrepositoryurl", "$(body.repository.links.html.href)"),
+		createBindingParam("fullname", "$(body.repository.full_name)"),
+		createBindingParam(triggers.GitRef, "$(extensions.ref)"),
+		createBindingParam(triggers.GitCommitID, "$(body.push.changes[0].new.target.hash)"),
+		createBindingParam(triggers.GitCommitDate, "$(body.push.changes[0].new.target.date)"),
+		createBindingParam(triggers.GitCommitMessage, "$(body.push.changes[0].new.target.message)"),
+		createBindingParam(triggers.GitCommitAuthor, "$(body.push.changes[0].new.target.author.raw)"),
+	}
+}
+
+func (r *bitbucketSpec) pushEventFilters() string {
+	return bitbucketPushEventFilters
+}
+
+// Bitbucket sends push events for tags and branches.
+func (r *bitbucketSpec) tagPushEventFilters() string {
+	return bitbucketPushEventFilters
+}
+
+func (r *bitbucketSpec) prBindingName() string {
+	return r.prBinding
+}
+
+// The commit status is reported against the head of the pull request, in the
+// repository that the pull request was opened against.
+func (r *bitbucketSpec) prBindingParams() []triggersv1.Param {
+	return []triggersv1.Param{
+		createBindingParam("gitrepositoryurl", "$(body.pullrequest.source.repository.links.html.href)"),
+		createBindingParam("fullname", "$(body.repository.full_name)"),
+		createBindingParam(triggers.GitRef, "$(body.pullrequest.source.branch.name)"),
+		createBindingParam(triggers.GitCommitID, "$(body.pullrequest.source.commit.hash)"),
+		createBindingParam(triggers.GitCommitDate, "$(body.pullrequest.updated_on)"),
+		createBindingParam(triggers.GitCommitMessage, "$(body.pullrequest.title)"),
+		createBindingParam(triggers.GitCommitAuthor, "$(body.pullrequest.author.display_name)"),
+		createBindingParam(triggers.PullRequestNumber, "$(body.pullrequest.id)"),
+		createBindingParam(triggers.PullRequestBaseRef, "$(body.pullrequest.destination.branch.name)"),
+	}
+}
+
+func (r *bitbucketSpec) prEventFilters() string {
+	return bitbucketPREventFilters
+}
+
+// Bitbucket Cloud doesn't sign the events, or send the secret in a header, so
+// the secret is sent in the query of the webhook URL, and compared with the
+// secret in the EventListener's namespace by a CEL interceptor.
+func (r *bitbucketSpec) eventInterceptor(secretNamespace, secretName string) (*triggersv1.EventInterceptor, error) {
+	query := "requestURL.parseURL().query"
+	filter := fmt.Sprintf("'%[2]s' in %[1]s && %[1]s['%[2]s'].compareSecret('%[3]s', '%[4]s', '%[5]s')", query, webhookSecretParam, webhookSecretKey, secretName, secretNamespace)
+	raw, err := json.Marshal(filter)
+	if err != nil {
+		return nil, err
+	}
+	return &triggersv1.EventInterceptor{
+		Ref: triggersv1.InterceptorRef{
+			Name: "cel",
+		},
+		Params: []triggersv1.InterceptorParams{
+			{
+				Name:  "filter",
+				Value: v1.JSON{Raw: raw},
+			},
+		},
+	}, nil
+}
+
+// The body has the fields that are used by the push binding and filters.
+func (r *bitbucketSpec) pushEventBody(url, path string, event PushEvent) interface{} {
+	kind, name := "branch", strings.TrimPrefix(event.Ref, "refs/heads/")
+	if strings.HasPrefix(event.Ref, "refs/tags/") {
+		kind, name = "tag", strings.TrimPrefix(event.Ref, "refs/tags/")
+	}
+	return map[string]interface{}{
+		"push": map[string]interface{}{
+			"changes": []interface{}{
+				map[string]interface{}{
+					"new": map[string]interface{}{
+						"type": kind,
+						"name": name,
+						"target": map[string]interface{}{
+							"hash":    event.SHA,
+							"date":    event.Date,
+							"message": event.Message,
+							"author":  map[string]string{"raw": event.Author},
+						},
+					},
+				},
+			},
+		},
+		"repository": map[string]interface{}{
+			"full_name": path,
+			"links": map[string]interface{}{
+				"html": map[string]string{"href": url},
+			},
+		},
+	}
+}
+
+// The secret is sent in the URL, rather than the headers.
+func (r *bitbucketSpec) pushEventHeaders(event PushEvent, body []byte, secret string) http.Header {
+	headers := http.Header{}
+	headers.Set("X-Event-Key", "repo:push")
+	return headers
+}
+
+func (r *bitbucketSpec) pushRef() string {
+	return bitbucketPushRef
+}
+
+// Bitbucket doesn't list the changed files in push events.
+func (r *bitbucketSpec) listsChangedFiles() bool {
+	return false
+}
+
+func (r *bitbucketSpec) webhookURL(listenerURL, secret string) (string, error) {
+	u, err := url.Parse(listenerURL)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	query.Set(webhookSecretParam, secret)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+func (r *bitbucketSpec) pushOverlays() []triggersv1.CELOverlay {
+	return nil
+}
+
+func (r *bitbucketSpec) prOverlays() []triggersv1.CELOverlay {
+	return nil
+}
@@ -0,0 +1,226 @@
+package scm
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCreatePushBindingForBitbucket(t *testing.T) {
+	repo, err := NewRepository("https://bitbucket.org/org/test.git")
+	assertNoError(t, err)
+	want := triggersv1.TriggerBinding{
+		TypeMeta: triggers.TriggerBindingTypeMeta,
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "bitbucket-push-binding",
+			Namespace: "testns",
+		},
+		Spec: triggersv1.TriggerBindingSpec{
+			Params: []triggersv1.Param{
+				{Name: "gitrepositoryurl", Value: "$(body.repository.links.html.href)"},
+				{Name: "fullname", Value: "$(body.repository.full_name)"},
+				{Name: triggers.GitRef, Value: "$(extensions.ref)"},
+				{Name: triggers.GitCommitID, Value: "$(body.push.changes[0].new.target.hash)"},
+				{Name: triggers.GitCommitDate, Value: "$(body.push.changes[0].new.target.date)"},
+				{Name: triggers.GitCommitMessage, Value: "$(body.push.changes[0].new.target.message)"},
+				{Name: triggers.GitCommitAuthor, Value: "$(body.push.changes[0].new.target.author.raw)"},
+			},
+		},
+	}
+	got, name := repo.CreatePushBinding("testns")
+	if name != "bitbucket-push-binding" {
+		t.Fatalf("CreatePushBinding() returned a wrong binding: want %v got %v", "bitbucket-push-binding", name)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("CreatePushBinding() failed:\n%s", diff)
+	}
+}
+
+func TestCreatePushTriggerForBitbucket(t *testing.T) {
+	repo, err := NewRepository("https://bitbucket.org/org/test.git")
+	assertNoError(t, err)
+	rawSecretFilter, err := json.Marshal("'secret' in requestURL.parseURL().query && requestURL.parseURL().query['secret'].compareSecret('webhook-secret-key', 'secret', 'ns')")
+	assertNoError(t, err)
+	rawFilter, rawOverlays, err := celParams(bitbucketPushEventFilters, "org/test", refOverlays(bitbucketPushRef))
+	assertNoError(t, err)
+	name := "test-template"
+	want := triggersv1.EventListenerTrigger{
+		Name: "test",
+		Bindings: []*triggersv1.EventListenerBinding{
+			{Ref: "test-binding"},
+		},
+		Template: &triggersv1.EventListenerTemplate{Ref: &name},
+		Interceptors: []*triggersv1.EventInterceptor{
+			{
+				Ref: triggersv1.InterceptorRef{Name: "cel"},
+				Params: []triggersv1.InterceptorParams{
+					{Name: "filter", Value: apiextensionsv1.JSON{Raw: rawSecretFilter}},
+				},
+			},
+			{
+				Ref: triggersv1.InterceptorRef{Name: "cel"},
+				Params: []triggersv1.InterceptorParams{
+					{Name: "filter", Value: apiextensionsv1.JSON{Raw: rawFilter}},
+					{Name: "overlays", Value: apiextensionsv1.JSON{Raw: rawOverlays}},
+				},
+			},
+		},
+	}
+	got, err := repo.CreatePushTrigger("test", "secret", "ns", "test-template", []string{"test-binding"})
+	assertNoError(t, err)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("CreatePushTrigger() failed:\n%s", diff)
+	}
+}
+
+func TestBitbucketEventInterceptorWithSecretNamespace(t *testing.T) {
+	repo, err := NewRepository("https://bitbucket.org/org/test.git")
+	assertNoError(t, err)
+
+	got, err := repo.(*repository).spec.eventInterceptor("cicd", "webhook-secret")
+	assertNoError(t, err)
+	var filter string
+	assertNoError(t, json.Unmarshal(got.Params[0].Value.Raw, &filter))
+	want := "'secret' in requestURL.parseURL().query && requestURL.parseURL().query['secret'].compareSecret('webhook-secret-key', 'webhook-secret', 'cicd')"
+	if filter != want {
+		t.Fatalf("eventInterceptor() filter got %q, want %q", filter, want)
+	}
+}
+
+// Bitbucket push events don't list the changed files, so the context
+// directory isn't matched.
+func TestCreateFilteredPushTriggerForBitbucket(t *testing.T) {
+	repo, err := NewRepository("https://bitbucket.org/org/test.git")
+	assertNoError(t, err)
+
+	got, err := repo.CreateFilteredPushTrigger("test", "secret", "ns", "test-template", []string{"test-binding"}, PushFilter{Branches: []string{"main"}, ContextDir: "services/taxi"})
+	assertNoError(t, err)
+
+	var filter string
+	if err := json.Unmarshal(got.Interceptors[1].Params[0].Value.Raw, &filter); err != nil {
+		t.Fatal(err)
+	}
+	want := "(header.match('X-Event-Key', 'repo:push') && body.repository.full_name == 'org/test') && " +
+		"(('refs/' + (body.push.changes[0].new.type == 'tag' ? 'tags/' : 'heads/') + body.push.changes[0].new.name).matches('^refs/heads/(main)$'))"
+	if diff := cmp.Diff(want, filter); diff != "" {
+		t.Fatalf("CreateFilteredPushTrigger() failed:\n%s", diff)
+	}
+}
+
+func TestNewBitbucketRepository(t *testing.T) {
+	tests := []struct {
+		url      string
+		repoPath string
+		errMsg   string
+	}{
+		{
+			"https://bitbucket.org",
+			"",
+			"invalid repository URL https://bitbucket.org: path is empty",
+		},
+		{
+			"https://bitbucket.org/a",
+			"",
+			"invalid repository path for bitbucket: /a",
+		},
+		{
+			"https://bitbucket.org/a/b/c",
+			"",
+			"invalid repository path for bitbucket: /a/b/c",
+		},
+		{
+			"https://bitbucket.org/foo/bar.git",
+			"foo/bar",
+			"",
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("Test %d", i), func(rt *testing.T) {
+			repo, err := NewRepository(tt.url)
+			if err != nil {
+				if diff := cmp.Diff(tt.errMsg, err.Error()); diff != "" {
+					rt.Fatalf("repo path errMsg mismatch: \n%s", diff)
+				}
+			}
+			if repo != nil {
+				if diff := cmp.Diff(tt.repoPath, repo.(*repository).path); diff != "" {
+					rt.Fatalf("repo path mismatch: got\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestCreatePRBindingForBitbucket(t *testing.T) {
+	repo, err := NewRepository("https://bitbucket.org/org/test.git")
+	assertNoError(t, err)
+	want := triggersv1.TriggerBinding{
+		TypeMeta: triggers.TriggerBindingTypeMeta,
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "bitbucket-pr-binding",
+			Namespace: "testns",
+		},
+		Spec: triggersv1.TriggerBindingSpec{
+			Params: []triggersv1.Param{
+				{Name: "gitrepositoryurl", Value: "$(body.pullrequest.source.repository.links.html.href)"},
+				{Name: "fullname", Value: "$(body.repository.full_name)"},
+				{Name: triggers.GitRef, Value: "$(body.pullrequest.source.branch.name)"},
+				{Name: triggers.GitCommitID, Value: "$(body.pullrequest.source.commit.hash)"},
+				{Name: triggers.GitCommitDate, Value: "$(body.pullrequest.updated_on)"},
+				{Name: triggers.GitCommitMessage, Value: "$(body.pullrequest.title)"},
+				{Name: triggers.GitCommitAuthor, Value: "$(body.pullrequest.author.display_name)"},
+				{Name: triggers.PullRequestNumber, Value: "$(body.pullrequest.id)"},
+				{Name: triggers.PullRequestBaseRef, Value: "$(body.pullrequest.destination.branch.name)"},
+			},
+		},
+	}
+	got, name := repo.CreatePRBinding("testns")
+	if name != "bitbucket-pr-binding" {
+		t.Fatalf("CreatePRBinding() returned a wrong binding: want %v got %v", "bitbucket-pr-binding", name)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("CreatePRBinding() failed:\n%s", diff)
+	}
+}
+
+func TestCreatePushEventForBitbucket(t *testing.T) {
+	repo, err := NewRepository("https://bitbucket.org/org/test.git")
+	assertNoError(t, err)
+	event := PushEvent{Ref: "refs/tags/v1.0", SHA: "abc123", Message: "Release", Author: "Test User", Date: "2021-01-01T00:00:00Z"}
+
+	headers, body, err := repo.CreatePushEvent(event, "secret")
+	assertNoError(t, err)
+
+	wantBody := `{"push":{"changes":[{"new":{"name":"v1.0","target":{"author":{"raw":"Test User"},"date":"2021-01-01T00:00:00Z","hash":"abc123","message":"Release"},"type":"tag"}}]},` +
+		`"repository":{"full_name":"org/test","links":{"html":{"href":"https://bitbucket.org/org/test.git"}}}}`
+	if diff := cmp.Diff(wantBody, string(body)); diff != "" {
+		t.Fatalf("CreatePushEvent() body failed:\n%s", diff)
+	}
+	wantHeaders := http.Header{
+		"X-Event-Key":  []string{"repo:push"},
+		"Content-Type": []string{"application/json"},
+	}
+	if diff := cmp.Diff(wantHeaders, headers); diff != "" {
+		t.Fatalf("CreatePushEvent() headers failed:\n%s", diff)
+	}
+}
+
+func TestWebhookURLForBitbucket(t *testing.T) {
+	repo, err := NewRepository("https://bitbucket.org/org/test.git")
+	assertNoError(t, err)
+
+	got, err := repo.WebhookURL("https://el.example.com/hooks", "a secret")
+	assertNoError(t, err)
+
+	if want := "https://el.example.com/hooks?secret=a+secret"; got != want {
+		t.Fatalf("WebhookURL() got %q, want %q", got, want)
+	}
+}
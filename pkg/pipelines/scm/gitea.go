@@ -0,0 +1,161 @@
+package scm
+
+import (
+	"crypto/sha256"
+	"net/http"
+	"strings"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/giturl"
+	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+)
+
+const (
+	giteaPushEventFilters = "(header.match('X-Gitea-Event', 'push') && body.repository.full_name == '%s')"
+	giteaPREventFilters   = "(header.match('X-Gitea-Event', 'pull_request') && body.action in ['opened', 'synchronized'] && body.repository.full_name == '%s')"
+	giteaType             = "gitea"
+)
+
+type giteaSpec struct {
+	pushBinding string
+	prBinding   string
+}
+
+func init() {
+	gits[giteaType] = newGitea
+}
+
+func newGitea(rawURL string) (Repository, error) {
+	path, err := processRawURL(rawURL, proccessGiteaPath)
+	if err != nil {
+		return nil, err
+	}
+	return &repository{url: rawURL, path: path, spec: &giteaSpec{pushBinding: "gitea-push-binding", prBinding: "gitea-pr-binding"}}, nil
+}
+
+func proccessGiteaPath(parsedURL *giturl.URL) (string, error) {
+	components, err := splitRepositoryPath(parsedURL)
+	if err != nil {
+		return "", err
+	}
+	if len(components) != 2 {
+		return "", invalidRepoPathError(giteaType, parsedURL.Path)
+	}
+	return strings.Join(components, "/"), nil
+}
+
+func (r *giteaSpec) pushBindingName() string {
+	return r.pushBinding
+}
+
+// Gitea lists the commits of a push with the most recent first.
+func (r *giteaSpec) pushBindingParams() []triggersv1.Param {
+	return []triggersv1.Param{
+		createBindingParam("gitrepositoryurl", "$(body.repository.clone_url)"),
+		createBindingParam("fullname", "$(body.repository.full_name)"),
+		createBindingParam(triggers.GitRef, "$(extensions.ref)"),
+		createBindingParam(triggers.GitCommitID, "$(body.after)"),
+		createBindingParam(triggers.GitCommitDate, "$(body.commits[0].timestamp)"),
+		createBindingParam(triggers.GitCommitMessage, "$(body.commits[0].message)"),
+		createBindingParam(triggers.GitCommitAuthor, "$(body.commits[0].author.name)"),
+	}
+}
+
+func (r *giteaSpec) pushEventFilters() string {
+	return giteaPushEventFilters
+}
+
+// Gitea sends push events for tags and branches.
+func (r *giteaSpec) tagPushEventFilters() string {
+	return giteaPushEventFilters
+}
+
+func (r *giteaSpec) prBindingName() string {
+	return r.prBinding
+}
+
+// The commit status is reported against the head of the pull request, in the
+// repository that the pull request was opened against.
+func (r *giteaSpec) prBindingParams() []triggersv1.Param {
+	return []triggersv1.Param{
+		createBindingParam("gitrepositoryurl", "$(body.pull_request.head.repo.clone_url)"),
+		createBindingParam("fullname", "$(body.repository.full_name)"),
+		createBindingParam(triggers.GitRef, "$(body.pull_request.head.ref)"),
+		createBindingParam(triggers.GitCommitID, "$(body.pull_request.head.sha)"),
+		createBindingParam(triggers.GitCommitDate, "$(body.pull_request.updated_at)"),
+		createBindingParam(triggers.GitCommitMessage, "$(body.pull_request.title)"),
+		createBindingParam(triggers.GitCommitAuthor, "$(body.pull_request.user.login)"),
+		createBindingParam(triggers.PullRequestNumber, "$(body.number)"),
+		createBindingParam(triggers.PullRequestBaseRef, "$(body.pull_request.base.ref)"),
+	}
+}
+
+func (r *giteaSpec) prEventFilters() string {
+	return giteaPREventFilters
+}
+
+// Gitea signs the events in the same way as GitHub, so the Triggers GitHub
+// interceptor verifies them.
+func (r *giteaSpec) eventInterceptor(secretNamespace, secretName string) (*triggersv1.EventInterceptor, error) {
+	raw, err := secretParam(secretName, webhookSecretKey)
+	if err != nil {
+		return nil, err
+	}
+	return eventInterceptorWithSecret(githubType, raw), nil
+}
+
+// The body has the fields that are used by the push binding and filters.
+func (r *giteaSpec) pushEventBody(url, path string, event PushEvent) interface{} {
+	commit := map[string]interface{}{
+		"id":        event.SHA,
+		"timestamp": event.Date,
+		"message":   event.Message,
+		"author":    map[string]string{"name": event.Author},
+		"added":     []string{},
+		"modified":  event.Modified,
+		"removed":   []string{},
+	}
+	return map[string]interface{}{
+		"ref":   event.Ref,
+		"after": event.SHA,
+		"repository": map[string]string{
+			"full_name": path,
+			"clone_url": url,
+		},
+		"commits": []interface{}{commit},
+	}
+}
+
+// Gitea signs the body with a SHA-256 HMAC, in its own header, and the header
+// that GitHub uses.
+func (r *giteaSpec) pushEventHeaders(event PushEvent, body []byte, secret string) http.Header {
+	signature := signBody(sha256.New, body, secret)
+	headers := http.Header{}
+	headers.Set("X-Gitea-Event", "push")
+	headers.Set("X-GitHub-Event", "push")
+	headers.Set("X-Gitea-Signature", signature)
+	headers.Set("X-Hub-Signature", "sha256="+signature)
+	headers.Set("X-Hub-Signature-256", "sha256="+signature)
+	return headers
+}
+
+func (r *giteaSpec) pushRef() string {
+	return bodyRef
+}
+
+func (r *giteaSpec) listsChangedFiles() bool {
+	return true
+}
+
+// Gitea signs the events with the secret, so it isn't in the URL.
+func (r *giteaSpec) webhookURL(listenerURL, secret string) (string, error) {
+	return listenerURL, nil
+}
+
+func (r *giteaSpec) pushOverlays() []triggersv1.CELOverlay {
+	return nil
+}
+
+func (r *giteaSpec) prOverlays() []triggersv1.CELOverlay {
+	return nil
+}
@@ -0,0 +1,152 @@
+package scm
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCreatePushBindingForGitea(t *testing.T) {
+	repo, err := newGitea("https://gitea.example.com/org/test.git")
+	assertNoError(t, err)
+	want := triggersv1.TriggerBinding{
+		TypeMeta: triggers.TriggerBindingTypeMeta,
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "gitea-push-binding",
+			Namespace: "testns",
+		},
+		Spec: triggersv1.TriggerBindingSpec{
+			Params: []triggersv1.Param{
+				{Name: "gitrepositoryurl", Value: "$(body.repository.clone_url)"},
+				{Name: "fullname", Value: "$(body.repository.full_name)"},
+				{Name: triggers.GitRef, Value: "$(extensions.ref)"},
+				{Name: triggers.GitCommitID, Value: "$(body.after)"},
+				{Name: triggers.GitCommitDate, Value: "$(body.commits[0].timestamp)"},
+				{Name: triggers.GitCommitMessage, Value: "$(body.commits[0].message)"},
+				{Name: triggers.GitCommitAuthor, Value: "$(body.commits[0].author.name)"},
+			},
+		},
+	}
+	got, name := repo.CreatePushBinding("testns")
+	if name != "gitea-push-binding" {
+		t.Fatalf("CreatePushBinding() returned a wrong binding: want %v got %v", "gitea-push-binding", name)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("CreatePushBinding() failed:\n%s", diff)
+	}
+}
+
+func TestNewGiteaRepository(t *testing.T) {
+	tests := []struct {
+		url      string
+		repoPath string
+		errMsg   string
+	}{
+		{
+			"https://gitea.example.com/a",
+			"",
+			"invalid repository path for gitea: /a",
+		},
+		{
+			"https://gitea.example.com/a/b/c",
+			"",
+			"invalid repository path for gitea: /a/b/c",
+		},
+		{
+			"https://gitea.example.com/foo/bar.git",
+			"foo/bar",
+			"",
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("Test %d", i), func(rt *testing.T) {
+			repo, err := newGitea(tt.url)
+			if err != nil {
+				if diff := cmp.Diff(tt.errMsg, err.Error()); diff != "" {
+					rt.Fatalf("repo path errMsg mismatch: \n%s", diff)
+				}
+			}
+			if repo != nil {
+				if diff := cmp.Diff(tt.repoPath, repo.(*repository).path); diff != "" {
+					rt.Fatalf("repo path mismatch: got\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestCreatePRTriggerForGitea(t *testing.T) {
+	repo, err := newGitea("https://gitea.example.com/org/test.git")
+	assertNoError(t, err)
+	rawSecret, err := secretParam("secret", "webhook-secret-key")
+	assertNoError(t, err)
+	rawFilter, _, err := celParams(giteaPREventFilters, "org/test", nil)
+	assertNoError(t, err)
+	name := "test-template"
+	want := triggersv1.EventListenerTrigger{
+		Name: "test",
+		Bindings: []*triggersv1.EventListenerBinding{
+			{Ref: "test-binding"},
+		},
+		Template: &triggersv1.EventListenerTemplate{Ref: &name},
+		Interceptors: []*triggersv1.EventInterceptor{
+			{
+				Ref: triggersv1.InterceptorRef{Name: "github"},
+				Params: []triggersv1.InterceptorParams{
+					{Name: "secretRef", Value: apiextensionsv1.JSON{Raw: rawSecret}},
+				},
+			},
+			{
+				Ref: triggersv1.InterceptorRef{Name: "cel"},
+				Params: []triggersv1.InterceptorParams{
+					{Name: "filter", Value: apiextensionsv1.JSON{Raw: rawFilter}},
+				},
+			},
+		},
+	}
+	got, err := repo.CreatePRTrigger("test", "secret", "ns", "test-template", []string{"test-binding"})
+	assertNoError(t, err)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("CreatePRTrigger() failed:\n%s", diff)
+	}
+}
+
+func TestCreatePushEventForGitea(t *testing.T) {
+	repo, err := newGitea("https://gitea.example.com/org/test.git")
+	assertNoError(t, err)
+	event := PushEvent{Ref: "refs/heads/main", SHA: "abc123", Message: "Fix it", Author: "Test User", Date: "2021-01-01T00:00:00Z", Modified: []string{"api/"}}
+
+	headers, body, err := repo.CreatePushEvent(event, "secret")
+	assertNoError(t, err)
+
+	wantBody := `{"after":"abc123","commits":[{"added":[],"author":{"name":"Test User"},"id":"abc123","message":"Fix it","modified":["api/"],"removed":[],"timestamp":"2021-01-01T00:00:00Z"}],` +
+		`"ref":"refs/heads/main","repository":{"clone_url":"https://gitea.example.com/org/test.git","full_name":"org/test"}}`
+	if diff := cmp.Diff(wantBody, string(body)); diff != "" {
+		t.Fatalf("CreatePushEvent() body failed:\n%s", diff)
+	}
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	signature := hex.EncodeToString(mac.Sum(nil))
+	wantHeaders := map[string]string{
+		"X-Gitea-Event":       "push",
+		"X-Github-Event":      "push",
+		"X-Gitea-Signature":   signature,
+		"X-Hub-Signature":     "sha256=" + signature,
+		"X-Hub-Signature-256": "sha256=" + signature,
+		"Content-Type":        "application/json",
+	}
+	for k, v := range wantHeaders {
+		if got := headers.Get(k); got != v {
+			t.Errorf("CreatePushEvent() header %s got %q, want %q", k, got, v)
+		}
+	}
+}
@@ -1,20 +1,28 @@
 package scm
 
 import (
-	"net/url"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"net/http"
 	"strings"
 
+	"github.com/redhat-developer/kam/pkg/pipelines/giturl"
 	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
 )
 
 const (
 	githubPushEventFilters = "(header.match('X-GitHub-Event', 'push') && body.repository.full_name == '%s')"
+	githubPREventFilters   = "(header.match('X-GitHub-Event', 'pull_request') && body.action in ['opened', 'synchronize'] && body.repository.full_name == '%s')"
 	githubType             = "github"
 )
 
 type githubSpec struct {
 	pushBinding string
+	prBinding   string
 }
 
 func init() {
@@ -26,10 +34,10 @@ func newGitHub(rawURL string) (Repository, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &repository{url: rawURL, path: path, spec: &githubSpec{pushBinding: "github-push-binding"}}, nil
+	return &repository{url: rawURL, path: path, spec: &githubSpec{pushBinding: "github-push-binding", prBinding: "github-pr-binding"}}, nil
 }
 
-func proccessGitHubPath(parsedURL *url.URL) (string, error) {
+func proccessGitHubPath(parsedURL *giturl.URL) (string, error) {
 	components, err := splitRepositoryPath(parsedURL)
 	if err != nil {
 		return "", err
@@ -62,6 +70,35 @@ func (r *githubSpec) pushEventFilters() string {
 	return githubPushEventFilters
 }
 
+// GitHub sends push events for tags and branches.
+func (r *githubSpec) tagPushEventFilters() string {
+	return githubPushEventFilters
+}
+
+func (r *githubSpec) prBindingName() string {
+	return r.prBinding
+}
+
+// The commit status is reported against the head of the pull request, in the
+// repository that the pull request was opened against.
+func (r *githubSpec) prBindingParams() []triggersv1.Param {
+	return []triggersv1.Param{
+		createBindingParam("gitrepositoryurl", "$(body.pull_request.head.repo.clone_url)"),
+		createBindingParam("fullname", "$(body.repository.full_name)"),
+		createBindingParam(triggers.GitRef, "$(body.pull_request.head.ref)"),
+		createBindingParam(triggers.GitCommitID, "$(body.pull_request.head.sha)"),
+		createBindingParam(triggers.GitCommitDate, "$(body.pull_request.updated_at)"),
+		createBindingParam(triggers.GitCommitMessage, "$(body.pull_request.title)"),
+		createBindingParam(triggers.GitCommitAuthor, "$(body.pull_request.user.login)"),
+		createBindingParam(triggers.PullRequestNumber, "$(body.number)"),
+		createBindingParam(triggers.PullRequestBaseRef, "$(body.pull_request.base.ref)"),
+	}
+}
+
+func (r *githubSpec) prEventFilters() string {
+	return githubPREventFilters
+}
+
 func (r *githubSpec) eventInterceptor(secretNamespace, secretName string) (*triggersv1.EventInterceptor, error) {
 	raw, err := secretParam(secretName, webhookSecretKey)
 	if err != nil {
@@ -69,3 +106,62 @@ func (r *githubSpec) eventInterceptor(secretNamespace, secretName string) (*trig
 	}
 	return eventInterceptorWithSecret(githubType, raw), nil
 }
+
+// The body has the fields that are used by the push binding and filters.
+func (r *githubSpec) pushEventBody(url, path string, event PushEvent) interface{} {
+	commit := map[string]interface{}{
+		"id":        event.SHA,
+		"timestamp": event.Date,
+		"message":   event.Message,
+		"author":    map[string]string{"name": event.Author},
+		"added":     []string{},
+		"modified":  event.Modified,
+		"removed":   []string{},
+	}
+	return map[string]interface{}{
+		"ref":   event.Ref,
+		"after": event.SHA,
+		"repository": map[string]string{
+			"full_name": path,
+			"clone_url": url,
+		},
+		"head_commit": commit,
+		"commits":     []interface{}{commit},
+	}
+}
+
+// GitHub signs the body with both SHA-1 and SHA-256 HMACs.
+func (r *githubSpec) pushEventHeaders(event PushEvent, body []byte, secret string) http.Header {
+	headers := http.Header{}
+	headers.Set("X-GitHub-Event", "push")
+	headers.Set("X-Hub-Signature", "sha1="+signBody(sha1.New, body, secret))
+	headers.Set("X-Hub-Signature-256", "sha256="+signBody(sha256.New, body, secret))
+	return headers
+}
+
+func signBody(h func() hash.Hash, body []byte, secret string) string {
+	mac := hmac.New(h, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (r *githubSpec) pushRef() string {
+	return bodyRef
+}
+
+func (r *githubSpec) listsChangedFiles() bool {
+	return true
+}
+
+// GitHub signs the events with the secret, so it isn't in the URL.
+func (r *githubSpec) webhookURL(listenerURL, secret string) (string, error) {
+	return listenerURL, nil
+}
+
+func (r *githubSpec) pushOverlays() []triggersv1.CELOverlay {
+	return nil
+}
+
+func (r *githubSpec) prOverlays() []triggersv1.CELOverlay {
+	return nil
+}
@@ -1,7 +1,11 @@
 package scm
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -67,7 +71,7 @@ func TestCreateCDTriggersForGithub(t *testing.T) {
 	assertNoError(t, err)
 	rawSecret, err := secretParam("secret", "webhook-secret-key")
 	assertNoError(t, err)
-	rawFilter, rawOverlays, err := celParams(githubPushEventFilters, "org/test")
+	rawFilter, rawOverlays, err := celParams(githubPushEventFilters, "org/test", branchRefOverlay)
 	assertNoError(t, err)
 	name := "test-template"
 	want := triggersv1.EventListenerTrigger{
@@ -149,6 +153,16 @@ func TestNewGitHubRepository(t *testing.T) {
 			"",
 			"invalid repository path for github: /foo/bar/test.git",
 		},
+		{
+			"git@github.com:foo/bar.git",
+			"foo/bar",
+			"",
+		},
+		{
+			"ssh://git@github.com/foo/bar.git",
+			"foo/bar",
+			"",
+		},
 	}
 
 	for i, tt := range tests {
@@ -167,3 +181,118 @@ func TestNewGitHubRepository(t *testing.T) {
 		})
 	}
 }
+
+func TestCreatePRBindingForGithub(t *testing.T) {
+	repo, err := NewRepository("http://github.com/org/test")
+	assertNoError(t, err)
+	want := triggersv1.TriggerBinding{
+		TypeMeta: triggers.TriggerBindingTypeMeta,
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "github-pr-binding",
+			Namespace: "testns",
+		},
+		Spec: triggersv1.TriggerBindingSpec{
+			Params: []triggersv1.Param{
+				{Name: "gitrepositoryurl", Value: "$(body.pull_request.head.repo.clone_url)"},
+				{Name: "fullname", Value: "$(body.repository.full_name)"},
+				{Name: triggers.GitRef, Value: "$(body.pull_request.head.ref)"},
+				{Name: triggers.GitCommitID, Value: "$(body.pull_request.head.sha)"},
+				{Name: triggers.GitCommitDate, Value: "$(body.pull_request.updated_at)"},
+				{Name: triggers.GitCommitMessage, Value: "$(body.pull_request.title)"},
+				{Name: triggers.GitCommitAuthor, Value: "$(body.pull_request.user.login)"},
+				{Name: triggers.PullRequestNumber, Value: "$(body.number)"},
+				{Name: triggers.PullRequestBaseRef, Value: "$(body.pull_request.base.ref)"},
+			},
+		},
+	}
+	got, name := repo.CreatePRBinding("testns")
+	if name != "github-pr-binding" {
+		t.Fatalf("CreatePRBinding() returned a wrong binding: want %v got %v", "github-pr-binding", name)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("CreatePRBinding() failed:\n%s", diff)
+	}
+}
+
+func TestCreatePRTriggerForGithub(t *testing.T) {
+	repo, err := NewRepository("http://github.com/org/test")
+	assertNoError(t, err)
+	rawSecret, err := secretParam("secret", "webhook-secret-key")
+	assertNoError(t, err)
+	rawFilter, _, err := celParams(githubPREventFilters, "org/test", nil)
+	assertNoError(t, err)
+	name := "test-template"
+	want := triggersv1.EventListenerTrigger{
+		Name: "test",
+		Bindings: []*triggersv1.EventListenerBinding{
+			{Ref: "test-binding"},
+		},
+		Template: &triggersv1.EventListenerTemplate{Ref: &name},
+		Interceptors: []*triggersv1.EventInterceptor{
+			{
+				Ref: triggersv1.InterceptorRef{Name: "github"},
+				Params: []triggersv1.InterceptorParams{
+					{Name: "secretRef", Value: apiextensionsv1.JSON{Raw: rawSecret}},
+				},
+			},
+			{
+				Ref: triggersv1.InterceptorRef{Name: "cel"},
+				Params: []triggersv1.InterceptorParams{
+					{Name: "filter", Value: apiextensionsv1.JSON{Raw: rawFilter}},
+				},
+			},
+		},
+	}
+	got, err := repo.CreatePRTrigger("test", "secret", "ns", "test-template", []string{"test-binding"})
+	assertNoError(t, err)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("CreatePRTrigger() failed:\n%s", diff)
+	}
+}
+
+func TestCreatePushEventForGithub(t *testing.T) {
+	repo, err := NewRepository("https://github.com/org/test.git")
+	assertNoError(t, err)
+	event := PushEvent{Ref: "refs/heads/main", SHA: "abc123", Message: "Fix it", Author: "Test User", Date: "2021-01-01T00:00:00Z", Modified: []string{"api/"}}
+
+	headers, body, err := repo.CreatePushEvent(event, "secret")
+	assertNoError(t, err)
+
+	wantBody := `{"after":"abc123","commits":[{"added":[],"author":{"name":"Test User"},"id":"abc123","message":"Fix it","modified":["api/"],"removed":[],"timestamp":"2021-01-01T00:00:00Z"}],` +
+		`"head_commit":{"added":[],"author":{"name":"Test User"},"id":"abc123","message":"Fix it","modified":["api/"],"removed":[],"timestamp":"2021-01-01T00:00:00Z"},` +
+		`"ref":"refs/heads/main","repository":{"clone_url":"https://github.com/org/test.git","full_name":"org/test"}}`
+	if diff := cmp.Diff(wantBody, string(body)); diff != "" {
+		t.Fatalf("CreatePushEvent() body failed:\n%s", diff)
+	}
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	wantHeaders := map[string]string{
+		"X-Github-Event":      "push",
+		"X-Hub-Signature-256": "sha256=" + hex.EncodeToString(mac.Sum(nil)),
+		"Content-Type":        "application/json",
+	}
+	for k, v := range wantHeaders {
+		if got := headers.Get(k); got != v {
+			t.Errorf("CreatePushEvent() header %s got %q, want %q", k, got, v)
+		}
+	}
+	if !strings.HasPrefix(headers.Get("X-Hub-Signature"), "sha1=") {
+		t.Errorf("CreatePushEvent() header X-Hub-Signature got %q", headers.Get("X-Hub-Signature"))
+	}
+}
+
+// The events of repositories with SSH URLs have the HTTPS clone URL.
+func TestCreatePushEventForGithubSSHURL(t *testing.T) {
+	repo, err := NewRepository("git@github.com:org/test.git")
+	assertNoError(t, err)
+
+	_, body, err := repo.CreatePushEvent(PushEvent{Ref: "refs/heads/main", SHA: "abc123"}, "secret")
+	assertNoError(t, err)
+
+	if !strings.Contains(string(body), `"repository":{"clone_url":"https://github.com/org/test.git","full_name":"org/test"}`) {
+		t.Fatalf("CreatePushEvent() body failed: %s", body)
+	}
+	if got := repo.URL(); got != "git@github.com:org/test.git" {
+		t.Fatalf("URL() got %q", got)
+	}
+}
@@ -1,20 +1,24 @@
 package scm
 
 import (
-	"net/url"
+	"net/http"
 	"strings"
 
+	"github.com/redhat-developer/kam/pkg/pipelines/giturl"
 	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
 )
 
 const (
-	gitlabPushEventFilters = "header.match('X-Gitlab-Event','Push Hook') && body.project.path_with_namespace == '%s'"
-	gitlabType             = "gitlab"
+	gitlabPushEventFilters    = "header.match('X-Gitlab-Event','Push Hook') && body.project.path_with_namespace == '%s'"
+	gitlabTagPushEventFilters = "(header.match('X-Gitlab-Event','Push Hook') || header.match('X-Gitlab-Event','Tag Push Hook')) && body.project.path_with_namespace == '%s'"
+	gitlabPREventFilters      = "header.match('X-Gitlab-Event','Merge Request Hook') && body.object_attributes.action in ['open', 'update'] && body.project.path_with_namespace == '%s'"
+	gitlabType                = "gitlab"
 )
 
 type gitlabSpec struct {
 	pushBinding string
+	prBinding   string
 }
 
 func init() {
@@ -26,10 +30,10 @@ func newGitLab(rawURL string) (Repository, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &repository{url: rawURL, path: path, spec: &gitlabSpec{pushBinding: "gitlab-push-binding"}}, nil
+	return &repository{url: rawURL, path: path, spec: &gitlabSpec{pushBinding: "gitlab-push-binding", prBinding: "gitlab-pr-binding"}}, nil
 }
 
-func proccessGitLabPath(parsedURL *url.URL) (string, error) {
+func proccessGitLabPath(parsedURL *giturl.URL) (string, error) {
 	components, err := splitRepositoryPath(parsedURL)
 	if err != nil {
 		return "", err
@@ -61,6 +65,34 @@ func (r *gitlabSpec) pushEventFilters() string {
 	return gitlabPushEventFilters
 }
 
+func (r *gitlabSpec) tagPushEventFilters() string {
+	return gitlabTagPushEventFilters
+}
+
+func (r *gitlabSpec) prBindingName() string {
+	return r.prBinding
+}
+
+// The commit status is reported against the last commit of the merge request,
+// in the project that the merge request was opened against.
+func (r *gitlabSpec) prBindingParams() []triggersv1.Param {
+	return []triggersv1.Param{
+		createBindingParam("gitrepositoryurl", "$(body.object_attributes.source.git_http_url)"),
+		createBindingParam("fullname", "$(body.project.path_with_namespace)"),
+		createBindingParam(triggers.GitRef, "$(body.object_attributes.source_branch)"),
+		createBindingParam(triggers.GitCommitID, "$(body.object_attributes.last_commit.id)"),
+		createBindingParam(triggers.GitCommitDate, "$(body.object_attributes.last_commit.timestamp)"),
+		createBindingParam(triggers.GitCommitMessage, "$(body.object_attributes.last_commit.message)"),
+		createBindingParam(triggers.GitCommitAuthor, "$(body.object_attributes.last_commit.author.name)"),
+		createBindingParam(triggers.PullRequestNumber, "$(body.object_attributes.iid)"),
+		createBindingParam(triggers.PullRequestBaseRef, "$(body.object_attributes.target_branch)"),
+	}
+}
+
+func (r *gitlabSpec) prEventFilters() string {
+	return gitlabPREventFilters
+}
+
 func (r *gitlabSpec) eventInterceptor(secretNamespace, secretName string) (*triggersv1.EventInterceptor, error) {
 	raw, err := secretParam(secretName, webhookSecretKey)
 	if err != nil {
@@ -68,3 +100,63 @@ func (r *gitlabSpec) eventInterceptor(secretNamespace, secretName string) (*trig
 	}
 	return eventInterceptorWithSecret(gitlabType, raw), nil
 }
+
+// The body has the fields that are used by the push binding and filters.
+func (r *gitlabSpec) pushEventBody(url, path string, event PushEvent) interface{} {
+	kind := "push"
+	if strings.HasPrefix(event.Ref, "refs/tags/") {
+		kind = "tag_push"
+	}
+	return map[string]interface{}{
+		"object_kind": kind,
+		"ref":         event.Ref,
+		"after":       event.SHA,
+		"project": map[string]string{
+			"path_with_namespace": path,
+			"git_http_url":        url,
+		},
+		"commits": []interface{}{
+			map[string]interface{}{
+				"id":        event.SHA,
+				"timestamp": event.Date,
+				"message":   event.Message,
+				"author":    map[string]string{"name": event.Author},
+				"added":     []string{},
+				"modified":  event.Modified,
+				"removed":   []string{},
+			},
+		},
+	}
+}
+
+// GitLab sends the secret as a token, rather than signing the body.
+func (r *gitlabSpec) pushEventHeaders(event PushEvent, body []byte, secret string) http.Header {
+	headers := http.Header{}
+	headers.Set("X-Gitlab-Event", "Push Hook")
+	if strings.HasPrefix(event.Ref, "refs/tags/") {
+		headers.Set("X-Gitlab-Event", "Tag Push Hook")
+	}
+	headers.Set("X-Gitlab-Token", secret)
+	return headers
+}
+
+func (r *gitlabSpec) pushRef() string {
+	return bodyRef
+}
+
+func (r *gitlabSpec) listsChangedFiles() bool {
+	return true
+}
+
+// GitLab sends the secret in a header, so it isn't in the URL.
+func (r *gitlabSpec) webhookURL(listenerURL, secret string) (string, error) {
+	return listenerURL, nil
+}
+
+func (r *gitlabSpec) pushOverlays() []triggersv1.CELOverlay {
+	return nil
+}
+
+func (r *gitlabSpec) prOverlays() []triggersv1.CELOverlay {
+	return nil
+}
@@ -1,7 +1,9 @@
 package scm
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -68,7 +70,7 @@ func TestCreateCDTriggersForGitLab(t *testing.T) {
 	assertNoError(t, err)
 	rawSecret, err := secretParam("secret", "webhook-secret-key")
 	assertNoError(t, err)
-	rawFilter, rawOverlays, err := celParams(gitlabPushEventFilters, "org/test")
+	rawFilter, rawOverlays, err := celParams(gitlabPushEventFilters, "org/test", branchRefOverlay)
 	assertNoError(t, err)
 	name := "test-template"
 	want := triggersv1.EventListenerTrigger{
@@ -119,6 +121,23 @@ func TestCreateCDTriggersForGitLab(t *testing.T) {
 	}
 }
 
+func TestCreateFilteredPushTriggerForGitLab(t *testing.T) {
+	repo, err := NewRepository("http://gitlab.com/org/test")
+	assertNoError(t, err)
+
+	got, err := repo.CreateFilteredPushTrigger("test", "secret", "ns", "test-template", []string{"test-binding"}, PushFilter{Tags: []string{"v*"}})
+	assertNoError(t, err)
+
+	var filter string
+	if err := json.Unmarshal(got.Interceptors[1].Params[0].Value.Raw, &filter); err != nil {
+		t.Fatal(err)
+	}
+	want := "((header.match('X-Gitlab-Event','Push Hook') || header.match('X-Gitlab-Event','Tag Push Hook')) && body.project.path_with_namespace == 'org/test') && (body.ref.matches('^refs/tags/(v.*)$'))"
+	if diff := cmp.Diff(want, filter); diff != "" {
+		t.Fatalf("CreateFilteredPushTrigger() failed:\n%s", diff)
+	}
+}
+
 func TestNewGitlabRepository(t *testing.T) {
 	tests := []struct {
 		url      string
@@ -168,3 +187,94 @@ func TestNewGitlabRepository(t *testing.T) {
 		})
 	}
 }
+
+func TestCreatePRBindingForGitlab(t *testing.T) {
+	repo, err := NewRepository("http://gitlab.com/org/test")
+	assertNoError(t, err)
+	want := triggersv1.TriggerBinding{
+		TypeMeta: triggers.TriggerBindingTypeMeta,
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "gitlab-pr-binding",
+			Namespace: "testns",
+		},
+		Spec: triggersv1.TriggerBindingSpec{
+			Params: []triggersv1.Param{
+				{Name: "gitrepositoryurl", Value: "$(body.object_attributes.source.git_http_url)"},
+				{Name: "fullname", Value: "$(body.project.path_with_namespace)"},
+				{Name: triggers.GitRef, Value: "$(body.object_attributes.source_branch)"},
+				{Name: triggers.GitCommitID, Value: "$(body.object_attributes.last_commit.id)"},
+				{Name: triggers.GitCommitDate, Value: "$(body.object_attributes.last_commit.timestamp)"},
+				{Name: triggers.GitCommitMessage, Value: "$(body.object_attributes.last_commit.message)"},
+				{Name: triggers.GitCommitAuthor, Value: "$(body.object_attributes.last_commit.author.name)"},
+				{Name: triggers.PullRequestNumber, Value: "$(body.object_attributes.iid)"},
+				{Name: triggers.PullRequestBaseRef, Value: "$(body.object_attributes.target_branch)"},
+			},
+		},
+	}
+	got, name := repo.CreatePRBinding("testns")
+	if name != "gitlab-pr-binding" {
+		t.Fatalf("CreatePRBinding() returned a wrong binding: want %v got %v", "gitlab-pr-binding", name)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("CreatePRBinding() failed:\n%s", diff)
+	}
+}
+
+func TestCreatePRTriggerForGitlab(t *testing.T) {
+	repo, err := NewRepository("http://gitlab.com/org/test")
+	assertNoError(t, err)
+	rawSecret, err := secretParam("secret", "webhook-secret-key")
+	assertNoError(t, err)
+	rawFilter, _, err := celParams(gitlabPREventFilters, "org/test", nil)
+	assertNoError(t, err)
+	name := "test-template"
+	want := triggersv1.EventListenerTrigger{
+		Name: "test",
+		Bindings: []*triggersv1.EventListenerBinding{
+			{Ref: "test-binding"},
+		},
+		Template: &triggersv1.EventListenerTemplate{Ref: &name},
+		Interceptors: []*triggersv1.EventInterceptor{
+			{
+				Ref: triggersv1.InterceptorRef{Name: "gitlab"},
+				Params: []triggersv1.InterceptorParams{
+					{Name: "secretRef", Value: apiextensionsv1.JSON{Raw: rawSecret}},
+				},
+			},
+			{
+				Ref: triggersv1.InterceptorRef{Name: "cel"},
+				Params: []triggersv1.InterceptorParams{
+					{Name: "filter", Value: apiextensionsv1.JSON{Raw: rawFilter}},
+				},
+			},
+		},
+	}
+	got, err := repo.CreatePRTrigger("test", "secret", "ns", "test-template", []string{"test-binding"})
+	assertNoError(t, err)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("CreatePRTrigger() failed:\n%s", diff)
+	}
+}
+
+func TestCreatePushEventForGitlab(t *testing.T) {
+	repo, err := NewRepository("https://gitlab.com/org/test.git")
+	assertNoError(t, err)
+	event := PushEvent{Ref: "refs/tags/v1.0", SHA: "abc123", Message: "Release", Author: "Test User", Date: "2021-01-01T00:00:00Z"}
+
+	headers, body, err := repo.CreatePushEvent(event, "secret")
+	assertNoError(t, err)
+
+	wantBody := `{"after":"abc123","commits":[{"added":[],"author":{"name":"Test User"},"id":"abc123","message":"Release","modified":[],"removed":[],"timestamp":"2021-01-01T00:00:00Z"}],` +
+		`"object_kind":"tag_push","project":{"git_http_url":"https://gitlab.com/org/test.git","path_with_namespace":"org/test"},"ref":"refs/tags/v1.0"}`
+	if diff := cmp.Diff(wantBody, string(body)); diff != "" {
+		t.Fatalf("CreatePushEvent() body failed:\n%s", diff)
+	}
+	wantHeaders := http.Header{
+		"X-Gitlab-Event": []string{"Tag Push Hook"},
+		"X-Gitlab-Token": []string{"secret"},
+		"Content-Type":   []string{"application/json"},
+	}
+	if diff := cmp.Diff(wantHeaders, headers); diff != "" {
+		t.Fatalf("CreatePushEvent() headers failed:\n%s", diff)
+	}
+}
@@ -1,6 +1,8 @@
 package scm
 
 import (
+	"net/http"
+
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
 )
 
@@ -16,6 +18,55 @@ type Repository interface {
 	// Create an eventlistener trigger for Push event
 	CreatePushTrigger(name, secretName, secretNs, template string, bindings []string) (triggersv1.EventListenerTrigger, error)
 
+	// Create an eventlistener trigger for Push events to the branches and
+	// tags, and changes to the files, matched by the filter
+	CreateFilteredPushTrigger(name, secretName, secretNs, template string, bindings []string, filter PushFilter) (triggersv1.EventListenerTrigger, error)
+
+	// Get Pull Request TriggerBinding name for this repository provider
+	PRBindingName() string
+
+	// Create a TriggerBinding for Pull Request hooks
+	CreatePRBinding(namespace string) (triggersv1.TriggerBinding, string)
+
+	// Create an eventlistener trigger for Pull Request events
+	CreatePRTrigger(name, secretName, secretNs, template string, bindings []string) (triggersv1.EventListenerTrigger, error)
+
+	// Create the headers and body of a push event for the commit, signed
+	// with the webhook secret, that the push trigger and binding accept
+	CreatePushEvent(event PushEvent, secret string) (http.Header, []byte, error)
+
+	// Get the URL that the Git host sends the events for the listener to,
+	// which has the webhook secret if the host can't sign the events
+	WebhookURL(listenerURL, secret string) (string, error)
+
 	// Git Repository URL
 	URL() string
 }
+
+// PushEvent is the commit that a push event is created for, Ref is the full
+// name of the branch or tag, e.g. refs/heads/main.
+//
+// Modified lists the files that are reported as modified by the commit.
+type PushEvent struct {
+	Ref      string
+	SHA      string
+	Message  string
+	Author   string
+	Date     string
+	Modified []string
+}
+
+// PushFilter restricts the pushes that trigger a pipeline to the branches and
+// tags that match one of the patterns, a "*" in a pattern matches any
+// characters.
+//
+// If there are no patterns, pushes to all branches and tags are matched.
+//
+// If ContextDir is set, pushes to branches are only matched if a commit adds,
+// modifies or removes a file in the directory, so that services that share a
+// repository are only rebuilt when they change.
+type PushFilter struct {
+	Branches   []string
+	Tags       []string
+	ContextDir string
+}
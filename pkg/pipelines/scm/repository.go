@@ -1,6 +1,10 @@
 package scm
 
 import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/giturl"
 	"github.com/redhat-developer/kam/pkg/pipelines/meta"
 	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
@@ -23,8 +27,26 @@ type repository struct {
 type triggerSpec interface {
 	pushBindingParams() []triggersv1.Param
 	pushEventFilters() string
+	tagPushEventFilters() string
 	eventInterceptor(secretNamespace, secretName string) (*triggersv1.EventInterceptor, error)
 	pushBindingName() string
+	prBindingParams() []triggersv1.Param
+	prEventFilters() string
+	prBindingName() string
+	pushEventBody(url, path string, event PushEvent) interface{}
+	pushEventHeaders(event PushEvent, body []byte, secret string) http.Header
+	// pushRef is the CEL expression with the full name of the pushed branch
+	// or tag, e.g. refs/heads/main.
+	pushRef() string
+	// listsChangedFiles is true if the push events list the files that the
+	// commits add and modify.
+	listsChangedFiles() bool
+	// pushOverlays and prOverlays are the overlays that add the values that
+	// the bindings need, and can't be selected from the events, to the
+	// extensions.
+	pushOverlays() []triggersv1.CELOverlay
+	prOverlays() []triggersv1.CELOverlay
+	webhookURL(listenerURL, secret string) (string, error)
 }
 
 // NewRepository returns a suitable Repository instance
@@ -56,13 +78,70 @@ func (r *repository) CreatePushBinding(ns string) (triggersv1.TriggerBinding, st
 
 // CreatePushTrigger implements the Repository interface.
 func (r *repository) CreatePushTrigger(name, secretName, secretNS, template string, bindings []string) (triggersv1.EventListenerTrigger, error) {
+	return r.CreateFilteredPushTrigger(name, secretName, secretNS, template, bindings, PushFilter{})
+}
+
+// CreateFilteredPushTrigger implements the Repository interface.
+func (r *repository) CreateFilteredPushTrigger(name, secretName, secretNS, template string, bindings []string, filter PushFilter) (triggersv1.EventListenerTrigger, error) {
+	eventInterceptorForCEL, err := r.spec.eventInterceptor(secretNS, secretName)
+	if err != nil {
+		return triggersv1.EventListenerTrigger{}, err
+	}
+	filters := r.spec.pushEventFilters()
+	if len(filter.Tags) > 0 {
+		filters = r.spec.tagPushEventFilters()
+	}
+	ref := r.spec.pushRef()
+	return r.createTrigger(name, pushEventFilters(ref, filters, filter, r.spec.listsChangedFiles()),
+		template, bindings,
+		eventInterceptorForCEL, append(refOverlays(ref), r.spec.pushOverlays()...))
+}
+
+// CreatePRBinding implements the Repository interface.
+func (r *repository) CreatePRBinding(ns string) (triggersv1.TriggerBinding, string) {
+	return triggersv1.TriggerBinding{
+		TypeMeta:   triggers.TriggerBindingTypeMeta,
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(ns, r.spec.prBindingName())),
+		Spec: triggersv1.TriggerBindingSpec{
+			Params: r.spec.prBindingParams(),
+		},
+	}, r.spec.prBindingName()
+}
+
+// CreatePRTrigger implements the Repository interface.
+func (r *repository) CreatePRTrigger(name, secretName, secretNS, template string, bindings []string) (triggersv1.EventListenerTrigger, error) {
 	eventInterceptorForCEL, err := r.spec.eventInterceptor(secretNS, secretName)
 	if err != nil {
 		return triggersv1.EventListenerTrigger{}, err
 	}
-	return r.createTrigger(name, r.spec.pushEventFilters(),
+	return r.createTrigger(name, r.spec.prEventFilters(),
 		template, bindings,
-		eventInterceptorForCEL)
+		eventInterceptorForCEL, r.spec.prOverlays())
+}
+
+// CreatePushEvent implements the Repository interface.
+func (r *repository) CreatePushEvent(event PushEvent, secret string) (http.Header, []byte, error) {
+	if event.Modified == nil {
+		event.Modified = []string{}
+	}
+	// The events have the HTTP(S) clone URL, even if the repository is
+	// configured with its SSH URL.
+	u, err := giturl.Parse(r.url)
+	if err != nil {
+		return nil, nil, err
+	}
+	body, err := json.Marshal(r.spec.pushEventBody(u.HTTPURL(), r.path, event))
+	if err != nil {
+		return nil, nil, err
+	}
+	headers := r.spec.pushEventHeaders(event, body, secret)
+	headers.Set("Content-Type", "application/json")
+	return headers, body, nil
+}
+
+// WebhookURL implements the Repository interface.
+func (r *repository) WebhookURL(listenerURL, secret string) (string, error) {
+	return r.spec.webhookURL(listenerURL, secret)
 }
 
 // URL implements the Repository interface.
@@ -75,8 +154,13 @@ func (r *repository) PushBindingName() string {
 	return r.spec.pushBindingName()
 }
 
-func (r *repository) createTrigger(name, filters, template string, bindings []string, interceptor *triggersv1.EventInterceptor) (triggersv1.EventListenerTrigger, error) {
-	eventInterceptor, err := createEventInterceptor(filters, r.path)
+// PRBindingName returns the name of the pull request binding.
+func (r *repository) PRBindingName() string {
+	return r.spec.prBindingName()
+}
+
+func (r *repository) createTrigger(name, filters, template string, bindings []string, interceptor *triggersv1.EventInterceptor, overlays []triggersv1.CELOverlay) (triggersv1.EventListenerTrigger, error) {
+	eventInterceptor, err := createEventInterceptor(filters, r.path, overlays)
 	if err != nil {
 		return triggersv1.EventListenerTrigger{}, err
 	}
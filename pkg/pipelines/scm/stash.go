@@ -0,0 +1,189 @@
+package scm
+
+import (
+	"crypto/sha256"
+	"net/http"
+	"strings"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/giturl"
+	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+)
+
+// The project keys are upper case in the events, and usually lower case in
+// the clone URLs, so the full names are compared in lower case.
+const (
+	stashPushEventFilters = "header.match('X-Event-Key', 'repo:refs_changed') && (body.repository.project.key + '/' + body.repository.slug).lowerAscii() == '%s'"
+	stashPREventFilters   = "(header.match('X-Event-Key', 'pr:opened') || header.match('X-Event-Key', 'pr:from_ref_updated')) && (body.pullRequest.toRef.repository.project.key + '/' + body.pullRequest.toRef.repository.slug).lowerAscii() == '%s'"
+	stashType             = "stash"
+
+	// Bitbucket Server reports the pushed branches and tags in the changes.
+	stashPushRef = "body.changes[0].refId"
+
+	// stashCloneURLKey is the extension with the HTTP clone URL, the
+	// repositories have a list of clone URLs, one for each protocol.
+	stashCloneURLKey = "clone_url"
+)
+
+type stashSpec struct {
+	pushBinding string
+	prBinding   string
+}
+
+func init() {
+	gits[stashType] = newStash
+}
+
+func newStash(rawURL string) (Repository, error) {
+	path, err := processRawURL(rawURL, proccessStashPath)
+	if err != nil {
+		return nil, err
+	}
+	return &repository{url: rawURL, path: path, spec: &stashSpec{pushBinding: "stash-push-binding", prBinding: "stash-pr-binding"}}, nil
+}
+
+// The clone URLs are of the form https://bitbucket.example.com/scm/proj/repo.git
+// where the server can be in a sub-path of the host, the SSH clone URLs are of
+// the form ssh://git@bitbucket.example.com:7999/proj/repo.git.
+func proccessStashPath(parsedURL *giturl.URL) (string, error) {
+	components, err := splitRepositoryPath(parsedURL)
+	if err != nil {
+		return "", err
+	}
+	n := len(components)
+	if parsedURL.IsSSH() && n == 2 {
+		return strings.ToLower(strings.Join(components, "/")), nil
+	}
+	if n < 3 || components[n-3] != "scm" {
+		return "", invalidRepoPathError(stashType, parsedURL.Path)
+	}
+	return strings.ToLower(strings.Join(components[n-2:], "/")), nil
+}
+
+func (r *stashSpec) pushBindingName() string {
+	return r.pushBinding
+}
+
+// A push can update several branches and tags, only the first is built.
+//
+// Bitbucket Server push events don't have the commit message, or the author
+// of the commit, so the message is empty, and the author is the user that
+// pushed the commit.
+func (r *stashSpec) pushBindingParams() []triggersv1.Param {
+	return []triggersv1.Param{
+		createBindingParam("gitrepositoryurl", "$(extensions."+stashCloneURLKey+")"),
+		createBindingParam("fullname", "$(body.repository.project.key)/$(body.repository.slug)"),
+		createBindingParam(triggers.GitRef, "$(extensions.ref)"),
+		createBindingParam(triggers.GitCommitID, "$(body.changes[0].toHash)"),
+		createBindingParam(triggers.GitCommitDate, "$(body.date)"),
+		createBindingParam(triggers.GitCommitMessage, ""),
+		createBindingParam(triggers.GitCommitAuthor, "$(body.actor.displayName)"),
+	}
+}
+
+func (r *stashSpec) pushEventFilters() string {
+	return stashPushEventFilters
+}
+
+// Bitbucket Server sends push events for tags and branches.
+func (r *stashSpec) tagPushEventFilters() string {
+	return stashPushEventFilters
+}
+
+func (r *stashSpec) prBindingName() string {
+	return r.prBinding
+}
+
+// The commit status is reported against the head of the pull request, in the
+// repository that the pull request was opened against.
+func (r *stashSpec) prBindingParams() []triggersv1.Param {
+	return []triggersv1.Param{
+		createBindingParam("gitrepositoryurl", "$(extensions."+stashCloneURLKey+")"),
+		createBindingParam("fullname", "$(body.pullRequest.toRef.repository.project.key)/$(body.pullRequest.toRef.repository.slug)"),
+		createBindingParam(triggers.GitRef, "$(body.pullRequest.fromRef.displayId)"),
+		createBindingParam(triggers.GitCommitID, "$(body.pullRequest.fromRef.latestCommit)"),
+		createBindingParam(triggers.GitCommitDate, "$(body.date)"),
+		createBindingParam(triggers.GitCommitMessage, "$(body.pullRequest.title)"),
+		createBindingParam(triggers.GitCommitAuthor, "$(body.pullRequest.author.user.displayName)"),
+		createBindingParam(triggers.PullRequestNumber, "$(body.pullRequest.id)"),
+		createBindingParam(triggers.PullRequestBaseRef, "$(body.pullRequest.toRef.displayId)"),
+	}
+}
+
+func (r *stashSpec) prEventFilters() string {
+	return stashPREventFilters
+}
+
+// The Triggers Bitbucket interceptor verifies the signature of Bitbucket
+// Server events.
+func (r *stashSpec) eventInterceptor(secretNamespace, secretName string) (*triggersv1.EventInterceptor, error) {
+	raw, err := secretParam(secretName, webhookSecretKey)
+	if err != nil {
+		return nil, err
+	}
+	return eventInterceptorWithSecret(bitbucketType, raw), nil
+}
+
+// The body has the fields that are used by the push binding and filters.
+func (r *stashSpec) pushEventBody(url, path string, event PushEvent) interface{} {
+	kind, name := "BRANCH", strings.TrimPrefix(event.Ref, "refs/heads/")
+	if strings.HasPrefix(event.Ref, "refs/tags/") {
+		kind, name = "TAG", strings.TrimPrefix(event.Ref, "refs/tags/")
+	}
+	parts := strings.SplitN(path, "/", 2)
+	return map[string]interface{}{
+		"date":  event.Date,
+		"actor": map[string]string{"displayName": event.Author},
+		"changes": []interface{}{
+			map[string]interface{}{
+				"ref":    map[string]string{"id": event.Ref, "displayId": name, "type": kind},
+				"refId":  event.Ref,
+				"toHash": event.SHA,
+				"type":   "UPDATE",
+			},
+		},
+		"repository": map[string]interface{}{
+			"slug":    parts[1],
+			"project": map[string]string{"key": strings.ToUpper(parts[0])},
+			"links": map[string]interface{}{
+				"clone": []interface{}{
+					map[string]string{"name": "http", "href": url},
+				},
+			},
+		},
+	}
+}
+
+// Bitbucket Server signs the body with a SHA-256 HMAC.
+func (r *stashSpec) pushEventHeaders(event PushEvent, body []byte, secret string) http.Header {
+	headers := http.Header{}
+	headers.Set("X-Event-Key", "repo:refs_changed")
+	headers.Set("X-Hub-Signature", "sha256="+signBody(sha256.New, body, secret))
+	return headers
+}
+
+func (r *stashSpec) pushRef() string {
+	return stashPushRef
+}
+
+// Bitbucket Server doesn't list the changed files in push events.
+func (r *stashSpec) listsChangedFiles() bool {
+	return false
+}
+
+// Bitbucket Server signs the events with the secret, so it isn't in the URL.
+func (r *stashSpec) webhookURL(listenerURL, secret string) (string, error) {
+	return listenerURL, nil
+}
+
+func (r *stashSpec) pushOverlays() []triggersv1.CELOverlay {
+	return []triggersv1.CELOverlay{
+		{Key: stashCloneURLKey, Expression: "body.repository.links.clone.filter(l, l.name == 'http')[0].href"},
+	}
+}
+
+func (r *stashSpec) prOverlays() []triggersv1.CELOverlay {
+	return []triggersv1.CELOverlay{
+		{Key: stashCloneURLKey, Expression: "body.pullRequest.fromRef.repository.links.clone.filter(l, l.name == 'http')[0].href"},
+	}
+}
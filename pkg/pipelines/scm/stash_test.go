@@ -0,0 +1,180 @@
+package scm
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCreatePushBindingForStash(t *testing.T) {
+	repo, err := newStash("https://bitbucket.example.com/scm/proj/test.git")
+	assertNoError(t, err)
+	want := triggersv1.TriggerBinding{
+		TypeMeta: triggers.TriggerBindingTypeMeta,
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "stash-push-binding",
+			Namespace: "testns",
+		},
+		Spec: triggersv1.TriggerBindingSpec{
+			Params: []triggersv1.Param{
+				{Name: "gitrepositoryurl", Value: "$(extensions.clone_url)"},
+				{Name: "fullname", Value: "$(body.repository.project.key)/$(body.repository.slug)"},
+				{Name: triggers.GitRef, Value: "$(extensions.ref)"},
+				{Name: triggers.GitCommitID, Value: "$(body.changes[0].toHash)"},
+				{Name: triggers.GitCommitDate, Value: "$(body.date)"},
+				{Name: triggers.GitCommitMessage, Value: ""},
+				{Name: triggers.GitCommitAuthor, Value: "$(body.actor.displayName)"},
+			},
+		},
+	}
+	got, name := repo.CreatePushBinding("testns")
+	if name != "stash-push-binding" {
+		t.Fatalf("CreatePushBinding() returned a wrong binding: want %v got %v", "stash-push-binding", name)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("CreatePushBinding() failed:\n%s", diff)
+	}
+}
+
+func TestCreatePushTriggerForStash(t *testing.T) {
+	repo, err := newStash("https://bitbucket.example.com/scm/proj/test.git")
+	assertNoError(t, err)
+	rawSecret, err := secretParam("secret", "webhook-secret-key")
+	assertNoError(t, err)
+	overlays := append(refOverlays(stashPushRef), triggersv1.CELOverlay{Key: "clone_url", Expression: "body.repository.links.clone.filter(l, l.name == 'http')[0].href"})
+	rawFilter, rawOverlays, err := celParams(stashPushEventFilters, "proj/test", overlays)
+	assertNoError(t, err)
+	name := "test-template"
+	want := triggersv1.EventListenerTrigger{
+		Name: "test",
+		Bindings: []*triggersv1.EventListenerBinding{
+			{Ref: "test-binding"},
+		},
+		Template: &triggersv1.EventListenerTemplate{Ref: &name},
+		Interceptors: []*triggersv1.EventInterceptor{
+			{
+				Ref: triggersv1.InterceptorRef{Name: "bitbucket"},
+				Params: []triggersv1.InterceptorParams{
+					{Name: "secretRef", Value: apiextensionsv1.JSON{Raw: rawSecret}},
+				},
+			},
+			{
+				Ref: triggersv1.InterceptorRef{Name: "cel"},
+				Params: []triggersv1.InterceptorParams{
+					{Name: "filter", Value: apiextensionsv1.JSON{Raw: rawFilter}},
+					{Name: "overlays", Value: apiextensionsv1.JSON{Raw: rawOverlays}},
+				},
+			},
+		},
+	}
+	got, err := repo.CreatePushTrigger("test", "secret", "ns", "test-template", []string{"test-binding"})
+	assertNoError(t, err)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("CreatePushTrigger() failed:\n%s", diff)
+	}
+}
+
+func TestNewStashRepository(t *testing.T) {
+	tests := []struct {
+		url      string
+		repoPath string
+		errMsg   string
+	}{
+		{
+			"https://bitbucket.example.com",
+			"",
+			"invalid repository URL https://bitbucket.example.com: path is empty",
+		},
+		{
+			"https://bitbucket.example.com/proj/test.git",
+			"",
+			"invalid repository path for stash: /proj/test.git",
+		},
+		{
+			"https://bitbucket.example.com/scm/PROJ/test.git",
+			"proj/test",
+			"",
+		},
+		{
+			"https://example.com/bitbucket/scm/proj/test.git",
+			"proj/test",
+			"",
+		},
+		{
+			"ssh://git@bitbucket.example.com:7999/PROJ/test.git",
+			"proj/test",
+			"",
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("Test %d", i), func(rt *testing.T) {
+			repo, err := newStash(tt.url)
+			if err != nil {
+				if diff := cmp.Diff(tt.errMsg, err.Error()); diff != "" {
+					rt.Fatalf("repo path errMsg mismatch: \n%s", diff)
+				}
+			}
+			if repo != nil {
+				if diff := cmp.Diff(tt.repoPath, repo.(*repository).path); diff != "" {
+					rt.Fatalf("repo path mismatch: got\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestCreatePRTriggerForStash(t *testing.T) {
+	repo, err := newStash("https://bitbucket.example.com/scm/proj/test.git")
+	assertNoError(t, err)
+
+	got, err := repo.CreatePRTrigger("test", "secret", "ns", "test-template", []string{"test-binding"})
+	assertNoError(t, err)
+
+	var overlays []triggersv1.CELOverlay
+	if err := json.Unmarshal(got.Interceptors[1].Params[1].Value.Raw, &overlays); err != nil {
+		t.Fatal(err)
+	}
+	want := []triggersv1.CELOverlay{
+		{Key: "clone_url", Expression: "body.pullRequest.fromRef.repository.links.clone.filter(l, l.name == 'http')[0].href"},
+	}
+	if diff := cmp.Diff(want, overlays); diff != "" {
+		t.Fatalf("CreatePRTrigger() failed:\n%s", diff)
+	}
+}
+
+func TestCreatePushEventForStash(t *testing.T) {
+	repo, err := newStash("https://bitbucket.example.com/scm/proj/test.git")
+	assertNoError(t, err)
+	event := PushEvent{Ref: "refs/heads/main", SHA: "abc123", Message: "Fix it", Author: "Test User", Date: "2021-01-01T00:00:00Z"}
+
+	headers, body, err := repo.CreatePushEvent(event, "secret")
+	assertNoError(t, err)
+
+	wantBody := `{"actor":{"displayName":"Test User"},"changes":[{"ref":{"displayId":"main","id":"refs/heads/main","type":"BRANCH"},"refId":"refs/heads/main","toHash":"abc123","type":"UPDATE"}],"date":"2021-01-01T00:00:00Z",` +
+		`"repository":{"links":{"clone":[{"href":"https://bitbucket.example.com/scm/proj/test.git","name":"http"}]},"project":{"key":"PROJ"},"slug":"test"}}`
+	if diff := cmp.Diff(wantBody, string(body)); diff != "" {
+		t.Fatalf("CreatePushEvent() body failed:\n%s", diff)
+	}
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	wantHeaders := map[string]string{
+		"X-Event-Key":     "repo:refs_changed",
+		"X-Hub-Signature": "sha256=" + hex.EncodeToString(mac.Sum(nil)),
+		"Content-Type":    "application/json",
+	}
+	for k, v := range wantHeaders {
+		if got := headers.Get(k); got != v {
+			t.Errorf("CreatePushEvent() header %s got %q, want %q", k, got, v)
+		}
+	}
+}
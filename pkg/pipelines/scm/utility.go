@@ -4,19 +4,101 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"path"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/jenkins-x/go-scm/scm/factory"
+	"github.com/redhat-developer/kam/pkg/pipelines/giturl"
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
 	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 )
 
+const (
+	// bodyRef is the full name of the pushed branch or tag in the push events
+	// of GitHub and GitLab.
+	bodyRef = "body.ref"
+)
+
 var (
-	branchRefOverlay = []triggersv1.CELOverlay{
-		{Key: "ref", Expression: "body.ref.split('/')[2]"},
-	}
+	branchRefOverlay = refOverlays(bodyRef)
 )
 
+// refOverlays returns the overlays that add the name of the branch or tag,
+// which can contain "/", from the CEL expression with its full name.
+func refOverlays(ref string) []triggersv1.CELOverlay {
+	return []triggersv1.CELOverlay{
+		{Key: "ref", Expression: fmt.Sprintf("%[1]s.startsWith('refs/tags/') ? %[1]s.substring(10) : %[1]s.substring(11)", ref)},
+	}
+}
+
+// ListsChangedFiles returns true if the push events of the driver of the
+// repository list the changed files, so that pushes can be filtered by the
+// directory that they change.
+func ListsChangedFiles(rawURL string) (bool, error) {
+	repo, err := NewRepository(rawURL)
+	if err != nil {
+		return false, err
+	}
+	return repo.(*repository).spec.listsChangedFiles(), nil
+}
+
+// pushEventFilters adds the matching of the branch and tag patterns, and the
+// changed files in the filter to the push event filters, ref is the CEL
+// expression with the full name of the pushed branch or tag.
+//
+// The context directory of the filter is only matched if the push events list
+// the changed files, the manifest validation rejects context directories for
+// the other drivers.
+func pushEventFilters(ref, filters string, filter PushFilter, listsChangedFiles bool) string {
+	matches := []string{}
+	refs := []string{}
+	if len(filter.Branches) > 0 {
+		refs = append(refs, refMatch(ref, "refs/heads/", filter.Branches))
+	}
+	if len(filter.Tags) > 0 {
+		refs = append(refs, refMatch(ref, "refs/tags/", filter.Tags))
+	}
+	if len(refs) > 0 {
+		matches = append(matches, strings.Join(refs, " || "))
+	}
+	if changed := changedFilesMatch(ref, filter.ContextDir); changed != "" && listsChangedFiles {
+		matches = append(matches, changed)
+	}
+	if len(matches) == 0 {
+		return filters
+	}
+	// The filters are formatted with the repository name.
+	return fmt.Sprintf("(%s) && (%s)", filters, strings.ReplaceAll(strings.Join(matches, ") && ("), "%", "%%"))
+}
+
+// refMatch returns a CEL expression that matches refs with the prefix, and a
+// name that matches one of the patterns.
+func refMatch(ref, prefix string, patterns []string) string {
+	quoted := []string{}
+	for _, p := range patterns {
+		quoted = append(quoted, strings.ReplaceAll(regexp.QuoteMeta(p), `\*`, ".*"))
+	}
+	expr := fmt.Sprintf("^%s(%s)$", regexp.QuoteMeta(prefix), strings.Join(quoted, "|"))
+	expr = strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(expr)
+	return fmt.Sprintf("%s.matches('%s')", ref, expr)
+}
+
+// changedFilesMatch returns a CEL expression that matches pushes with a commit
+// that adds, modifies or removes a file in dir, GitHub and GitLab both list the changed
+// files of the commits in the push.
+//
+// Tag pushes don't list the commits, and are always matched.
+func changedFilesMatch(ref, dir string) string {
+	dir = path.Clean(strings.Trim(dir, "/"))
+	if dir == "." {
+		return ""
+	}
+	prefix := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(dir + "/")
+	return fmt.Sprintf("%[2]s.startsWith('refs/tags/') || body.commits.exists(c, c.added.exists(f, f.startsWith('%[1]s')) || c.modified.exists(f, f.startsWith('%[1]s')) || c.removed.exists(f, f.startsWith('%[1]s')))", prefix, ref)
+}
+
 func invalidRepoPathError(gitType, path string) error {
 	return fmt.Errorf("invalid repository path for %s: %s", gitType, path)
 }
@@ -29,29 +111,32 @@ func invalidRepoURLError(repoURL, reason string) error {
 	return fmt.Errorf("invalid repository URL %s: %s", repoURL, reason)
 }
 
-func createEventInterceptor(filter, repoName string) (*triggersv1.EventInterceptor, error) {
-	rawFilter, rawOverlays, err := celParams(filter, repoName)
+func createEventInterceptor(filter, repoName string, overlays []triggersv1.CELOverlay) (*triggersv1.EventInterceptor, error) {
+	rawFilter, rawOverlays, err := celParams(filter, repoName, overlays)
 	if err != nil {
 		return nil, err
 	}
+	params := []triggersv1.InterceptorParams{
+		{
+			Name: "filter",
+			Value: v1.JSON{
+				Raw: rawFilter,
+			},
+		},
+	}
+	if rawOverlays != nil {
+		params = append(params, triggersv1.InterceptorParams{
+			Name: "overlays",
+			Value: v1.JSON{
+				Raw: rawOverlays,
+			},
+		})
+	}
 	return &triggersv1.EventInterceptor{
 		Ref: triggersv1.InterceptorRef{
 			Name: "cel",
 		},
-		Params: []triggersv1.InterceptorParams{
-			{
-				Name: "filter",
-				Value: v1.JSON{
-					Raw: rawFilter,
-				},
-			},
-			{
-				Name: "overlays",
-				Value: v1.JSON{
-					Raw: rawOverlays,
-				},
-			},
-		},
+		Params: params,
 	}, nil
 }
 
@@ -82,8 +167,8 @@ func createBindingParam(name, value string) triggersv1.Param {
 	}
 }
 
-func processRawURL(rawURL string, processPath func(*url.URL) (string, error)) (string, error) {
-	parsedURL, err := url.Parse(rawURL)
+func processRawURL(rawURL string, processPath func(*giturl.URL) (string, error)) (string, error) {
+	parsedURL, err := giturl.Parse(rawURL)
 	if err != nil {
 		return "", err
 	}
@@ -94,7 +179,7 @@ func processRawURL(rawURL string, processPath func(*url.URL) (string, error)) (s
 	return path, nil
 }
 
-func splitRepositoryPath(parsedURL *url.URL) ([]string, error) {
+func splitRepositoryPath(parsedURL *giturl.URL) ([]string, error) {
 	var components []string
 	for _, s := range strings.Split(parsedURL.Path, "/") {
 		if s != "" {
@@ -118,13 +203,79 @@ func GetDriverName(rawURL string) (string, error) {
 	return factory.DefaultIdentifier.Identify(host)
 }
 
-// HostnameFromURL returns the host from a URL.
+// HostnameFromURL returns the host from a repository URL, SSH URLs have the
+// host without the SSH port.
 func HostnameFromURL(rawURL string) (string, error) {
-	u, err := url.Parse(rawURL)
+	u, err := giturl.Parse(rawURL)
 	if err != nil {
 		return "", err
 	}
-	return strings.ToLower(u.Host), nil
+	return u.Host, nil
+}
+
+// APIURL returns the base URL of the REST API for the host of the repository,
+// self-hosted GitHub Enterprise servers serve the API under /api/v3, and
+// Bitbucket Cloud serves it from its own host.
+//
+// Bitbucket Server serves several APIs under /rest, e.g. /rest/api/1.0 and
+// /rest/build-status/1.0, so the base URL is /rest.
+//
+// The API of repositories with SSH URLs is accessed with HTTPS.
+func APIURL(rawURL, driver string) (string, error) {
+	parsed, err := giturl.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(parsed.HTTPURL())
+	if err != nil {
+		return "", err
+	}
+	host := strings.ToLower(u.Host)
+	switch driver {
+	case githubType:
+		if host == "github.com" {
+			return "https://api.github.com", nil
+		}
+		return fmt.Sprintf("%s://%s/api/v3", u.Scheme, host), nil
+	case gitlabType:
+		return fmt.Sprintf("%s://%s/api/v4", u.Scheme, host), nil
+	case bitbucketType:
+		return "https://api.bitbucket.org/2.0", nil
+	case stashType:
+		prefix := ""
+		if i := strings.LastIndex(u.Path, "/scm/"); i > 0 {
+			prefix = u.Path[:i]
+		}
+		return fmt.Sprintf("%s://%s%s/rest", u.Scheme, host, prefix), nil
+	case giteaType:
+		return fmt.Sprintf("%s://%s/api/v1", u.Scheme, host), nil
+	}
+	return "", unsupportedGitTypeError(driver)
+}
+
+// HTTPCloneURL returns the HTTP(S) clone URL of the repository, for the
+// pipelines that authenticate to the host with an access token, SSH URLs are
+// converted to HTTPS URLs, with the /scm prefix of Bitbucket Server.
+func HTTPCloneURL(rawURL, driver string) (string, error) {
+	u, err := giturl.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	if driver == stashType && u.IsSSH() {
+		return fmt.Sprintf("https://%s/scm%s", u.Host, u.Path), nil
+	}
+	return u.HTTPURL(), nil
+}
+
+// SupportedDrivers returns the sorted names of the drivers that repositories
+// can be created for.
+func SupportedDrivers() []string {
+	names := []string{}
+	for k := range gits {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
 }
 
 func secretParam(name, key string) ([]byte, error) {
@@ -134,12 +285,17 @@ func secretParam(name, key string) ([]byte, error) {
 	})
 }
 
-func celParams(filter, repoName string) ([]byte, []byte, error) {
+// celParams returns the encoded CEL filter and overlays, the overlays are nil
+// if none are provided.
+func celParams(filter, repoName string, overlays []triggersv1.CELOverlay) ([]byte, []byte, error) {
 	rawFilter, err := json.Marshal(fmt.Sprintf(filter, repoName))
 	if err != nil {
 		return nil, nil, err
 	}
-	rawOverlays, err := json.Marshal(branchRefOverlay)
+	if len(overlays) == 0 {
+		return rawFilter, nil, nil
+	}
+	rawOverlays, err := json.Marshal(overlays)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -33,7 +33,7 @@ func TestCreateListenerTemplate(t *testing.T) {
 func TestCreateEventInterceptor(t *testing.T) {
 	filter := "sampleFilter %s"
 	repo := "sample"
-	rawFilter, rawOverlays, err := celParams(filter, repo)
+	rawFilter, rawOverlays, err := celParams(filter, repo, branchRefOverlay)
 	assertNoError(t, err)
 	validEventInterceptor := triggersv1.EventInterceptor{
 		Ref: triggersv1.InterceptorRef{
@@ -54,13 +54,87 @@ func TestCreateEventInterceptor(t *testing.T) {
 			},
 		},
 	}
-	eventInterceptor, err := createEventInterceptor("sampleFilter %s", "sample")
+	eventInterceptor, err := createEventInterceptor("sampleFilter %s", "sample", branchRefOverlay)
 	assertNoError(t, err)
 	if diff := cmp.Diff(validEventInterceptor, *eventInterceptor); diff != "" {
 		t.Fatalf("createEventInterceptor() failed:\n%s", diff)
 	}
 }
 
+func TestCreateEventInterceptorWithoutOverlays(t *testing.T) {
+	rawFilter, rawOverlays, err := celParams("sampleFilter %s", "sample", nil)
+	assertNoError(t, err)
+	if rawOverlays != nil {
+		t.Fatalf("celParams() got overlays %s", rawOverlays)
+	}
+	want := triggersv1.EventInterceptor{
+		Ref: triggersv1.InterceptorRef{
+			Name: "cel",
+		},
+		Params: []triggersv1.InterceptorParams{
+			{
+				Name: "filter",
+				Value: v1.JSON{
+					Raw: rawFilter,
+				},
+			},
+		},
+	}
+	eventInterceptor, err := createEventInterceptor("sampleFilter %s", "sample", nil)
+	assertNoError(t, err)
+	if diff := cmp.Diff(want, *eventInterceptor); diff != "" {
+		t.Fatalf("createEventInterceptor() failed:\n%s", diff)
+	}
+}
+
+func TestPushEventFilters(t *testing.T) {
+	filterTests := []struct {
+		name   string
+		filter PushFilter
+		want   string
+	}{
+		{"no patterns", PushFilter{}, "push %s"},
+		{
+			"branches",
+			PushFilter{Branches: []string{"main", "release-*"}},
+			`(push %s) && (body.ref.matches('^refs/heads/(main|release-.*)$'))`,
+		},
+		{
+			"tags",
+			PushFilter{Tags: []string{"v1.*"}},
+			`(push %s) && (body.ref.matches('^refs/tags/(v1\\..*)$'))`,
+		},
+		{
+			"branches and tags",
+			PushFilter{Branches: []string{"main"}, Tags: []string{"*"}},
+			`(push %s) && (body.ref.matches('^refs/heads/(main)$') || body.ref.matches('^refs/tags/(.*)$'))`,
+		},
+		{
+			"context dir",
+			PushFilter{ContextDir: "./services/api/"},
+			`(push %s) && (body.ref.startsWith('refs/tags/') || body.commits.exists(c, c.added.exists(f, f.startsWith('services/api/')) || c.modified.exists(f, f.startsWith('services/api/')) || c.removed.exists(f, f.startsWith('services/api/'))))`,
+		},
+		{
+			"root context dir",
+			PushFilter{ContextDir: "."},
+			"push %s",
+		},
+		{
+			"branches and context dir",
+			PushFilter{Branches: []string{"main"}, ContextDir: "api"},
+			`(push %s) && (body.ref.matches('^refs/heads/(main)$')) && (body.ref.startsWith('refs/tags/') || body.commits.exists(c, c.added.exists(f, f.startsWith('api/')) || c.modified.exists(f, f.startsWith('api/')) || c.removed.exists(f, f.startsWith('api/'))))`,
+		},
+	}
+
+	for _, tt := range filterTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			if diff := cmp.Diff(tt.want, pushEventFilters(bodyRef, "push %s", tt.filter, true)); diff != "" {
+				rt.Fatalf("pushEventFilters() failed:\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestHostnameFromURL(t *testing.T) {
 	hostTests := []struct {
 		repoURL  string
@@ -71,6 +145,8 @@ func TestHostnameFromURL(t *testing.T) {
 		{"https://example.com/example/example.git", "example.com", ""},
 		{"https:/%/", "", "parse \"https:/%/\": invalid URL escape \"%/\""},
 		{"https://GITHUB.COM/test/test.git", "github.com", ""},
+		{"git@GitHub.com:test/test.git", "github.com", ""},
+		{"ssh://git@bitbucket.example.com:7999/proj/test.git", "bitbucket.example.com", ""},
 	}
 
 	for _, tt := range hostTests {
@@ -87,3 +163,65 @@ func TestHostnameFromURL(t *testing.T) {
 		}
 	}
 }
+
+func TestAPIURL(t *testing.T) {
+	urlTests := []struct {
+		repoURL string
+		driver  string
+		want    string
+		wantErr string
+	}{
+		{"https://github.com/example/example.git", "github", "https://api.github.com", ""},
+		{"https://GHE.example.com/example/example.git", "github", "https://ghe.example.com/api/v3", ""},
+		{"https://gitlab.com/example/example.git", "gitlab", "https://gitlab.com/api/v4", ""},
+		{"http://gitlab.example.com:8080/group/example.git", "gitlab", "http://gitlab.example.com:8080/api/v4", ""},
+		{"https://bitbucket.org/example/example.git", "bitbucket", "https://api.bitbucket.org/2.0", ""},
+		{"git@ghe.example.com:example/example.git", "github", "https://ghe.example.com/api/v3", ""},
+		{"git@gitlab.com:group/subgroup/example.git", "gitlab", "https://gitlab.com/api/v4", ""},
+		{"https://bitbucket.example.com/scm/proj/example.git", "stash", "https://bitbucket.example.com/rest", ""},
+		{"ssh://git@bitbucket.example.com:7999/proj/example.git", "stash", "https://bitbucket.example.com/rest", ""},
+		{"https://example.com/bitbucket/scm/proj/example.git", "stash", "https://example.com/bitbucket/rest", ""},
+		{"https://gitea.example.com/example/example.git", "gitea", "https://gitea.example.com/api/v1", ""},
+		{"https://example.com/example/example.git", "gogs", "", "unsupported Git repository type: gogs"},
+	}
+
+	for _, tt := range urlTests {
+		t.Run(tt.repoURL, func(rt *testing.T) {
+			got, err := APIURL(tt.repoURL, tt.driver)
+			if tt.wantErr == "" && err != nil {
+				rt.Fatalf("got an error %q", err)
+			}
+			if tt.wantErr != "" && (err == nil || tt.wantErr != err.Error()) {
+				rt.Fatalf("error failed: got %v, want %q", err, tt.wantErr)
+			}
+			if got != tt.want {
+				rt.Fatalf("APIURL() got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPCloneURL(t *testing.T) {
+	urlTests := []struct {
+		repoURL string
+		driver  string
+		want    string
+	}{
+		{"https://github.com/example/example.git", "github", "https://github.com/example/example.git"},
+		{"git@github.com:example/example.git", "github", "https://github.com/example/example.git"},
+		{"ssh://git@gitlab.example.com:2222/group/subgroup/example.git", "gitlab", "https://gitlab.example.com/group/subgroup/example.git"},
+		{"ssh://git@bitbucket.example.com:7999/proj/example.git", "stash", "https://bitbucket.example.com/scm/proj/example.git"},
+	}
+
+	for _, tt := range urlTests {
+		t.Run(tt.repoURL, func(rt *testing.T) {
+			got, err := HTTPCloneURL(tt.repoURL, tt.driver)
+			if err != nil {
+				rt.Fatal(err)
+			}
+			if got != tt.want {
+				rt.Fatalf("HTTPCloneURL() got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
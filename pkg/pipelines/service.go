@@ -133,11 +133,7 @@ func serviceResources(m *config.Manifest, appFs afero.Fs, o *AddServiceOptions)
 			}
 
 			files = res.Merge(resources, files)
-			svc.Pipelines = &config.Pipelines{
-				Integration: &config.TemplateBinding{
-					Bindings: append([]string{bindingName}, env.Pipelines.Integration.Bindings...),
-				},
-			}
+			svc.Pipelines = servicePipelines(env.Pipelines, bindingName)
 		}
 	}
 
@@ -228,7 +224,8 @@ func createSvcImageBinding(cfg *config.PipelinesConfig, env *config.Environment,
 	name := makeSvcImageBindingName(env.Name, appName, svcName)
 	filename := makeSvcImageBindingFilename(name)
 	resourceFilePath := makeImageBindingPath(cfg, filename)
-	return name, filename, res.Resources{resourceFilePath: triggers.CreateImageRepoBinding(cfg.Name, name, imageRepo, strconv.FormatBool(isTLSVerify))}
+	overlayPath := filepath.ToSlash(filepath.Join(config.PathForEnvironment(env), "apps", appName, "services", svcName, "overlays"))
+	return name, filename, res.Resources{resourceFilePath: triggers.CreateImageRepoBinding(cfg.Name, name, imageRepo, strconv.FormatBool(isTLSVerify), overlayPath, svcName)}
 }
 
 func getConfigFolder(m *config.Manifest, appFs afero.Fs, o *AddServiceOptions) (res.Resources, error) {
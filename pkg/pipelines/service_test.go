@@ -80,6 +80,7 @@ func TestServiceResourcesWithCICD(t *testing.T) {
 									},
 									Pipelines: &config.Pipelines{
 										Integration: &config.TemplateBinding{Bindings: []string{"test-dev-test-app-test-binding", "github-push-binding"}},
+										PullRequest: &config.TemplateBinding{Bindings: []string{"test-dev-test-app-test-binding", "github-pr-binding"}},
 									},
 								},
 							},
@@ -87,6 +88,7 @@ func TestServiceResourcesWithCICD(t *testing.T) {
 					},
 					Pipelines: &config.Pipelines{
 						Integration: &config.TemplateBinding{Template: "app-ci-template", Bindings: []string{"github-push-binding"}},
+						PullRequest: &config.TemplateBinding{Template: "app-ci-pr-template", Bindings: []string{"github-pr-binding"}},
 					},
 				},
 			},
@@ -436,6 +438,7 @@ func TestServiceWithArgoCD(t *testing.T) {
 									},
 									Pipelines: &config.Pipelines{
 										Integration: &config.TemplateBinding{Bindings: []string{"test-dev-test-app-test-binding", "github-push-binding"}},
+										PullRequest: &config.TemplateBinding{Bindings: []string{"test-dev-test-app-test-binding", "github-pr-binding"}},
 									},
 								},
 							},
@@ -443,6 +446,7 @@ func TestServiceWithArgoCD(t *testing.T) {
 					},
 					Pipelines: &config.Pipelines{
 						Integration: &config.TemplateBinding{Template: "app-ci-template", Bindings: []string{"github-push-binding"}},
+						PullRequest: &config.TemplateBinding{Template: "app-ci-pr-template", Bindings: []string{"github-pr-binding"}},
 					},
 				},
 			},
@@ -531,7 +535,7 @@ func TestAddServiceWithoutImage(t *testing.T) {
 	assertNoError(t, err)
 
 	files := res.Resources{
-		"config/cicd/base/05-bindings/staging-new-app-test-binding.yaml": triggers.CreateImageRepoBinding("cicd", "staging-new-app-test-binding", "image-registry.openshift-image-registry.svc:5000/cicd/test", "false"),
+		"config/cicd/base/05-bindings/staging-new-app-test-binding.yaml": triggers.CreateImageRepoBinding("cicd", "staging-new-app-test-binding", "image-registry.openshift-image-registry.svc:5000/cicd/test", "false", "environments/staging/apps/new-app/services/test/overlays", "test"),
 	}
 
 	for path, resource := range files {
@@ -696,6 +700,14 @@ func TestCreateSvcImageBinding(t *testing.T) {
 					Name:  "tlsVerify",
 					Value: "false",
 				},
+				{
+					Name:  "overlayPath",
+					Value: "environments/new-env/apps/newapp/services/new-svc/overlays",
+				},
+				{
+					Name:  "serviceName",
+					Value: "new-svc",
+				},
 			},
 		},
 	}
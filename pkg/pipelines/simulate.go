@@ -0,0 +1,65 @@
+package pipelines
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/triggersim"
+)
+
+// SimulateTriggers evaluates the triggers of the EventListener in the
+// pipelines configuration in outputPath for the event, with the
+// TriggerBindings in the pipelines configuration, the secrets that the
+// triggers compare have the webhookSecret.
+//
+// The v1alpha1 and v1beta1 Triggers resources have the same fields for
+// triggers and bindings, so both are read as v1alpha1.
+func SimulateTriggers(fs afero.Fs, outputPath string, m *config.Manifest, event *triggersim.Event, webhookSecret string) ([]*triggersim.Result, error) {
+	if m.GetPipelinesConfig() == nil {
+		return nil, errors.New("failed to find the pipelines configuration in the manifest")
+	}
+	root := filepath.Join(outputPath, pipelinesPath(m.Config))
+	filename := filepath.Join(root, eventListenerPath)
+	data, err := afero.ReadFile(fs, filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the EventListener: %w", err)
+	}
+	el := &triggersv1.EventListener{}
+	if err := yaml.Unmarshal(data, el); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+	bindings, err := readTriggerBindings(fs, root)
+	if err != nil {
+		return nil, err
+	}
+	return triggersim.Simulate(el, bindings, event, triggersim.NewWebhookSecretLister(webhookSecret))
+}
+
+// readTriggerBindings returns the TriggerBindings in the files in root,
+// including any that were added to the generated resources.
+func readTriggerBindings(fs afero.Fs, root string) (map[string]*triggersv1.TriggerBinding, error) {
+	bindings := map[string]*triggersv1.TriggerBinding{}
+	err := afero.Walk(fs, root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || filepath.Ext(path) != ".yaml" {
+			return err
+		}
+		data, err := afero.ReadFile(fs, path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		tb := &triggersv1.TriggerBinding{}
+		if err := yaml.Unmarshal(data, tb); err != nil || tb.Kind != "TriggerBinding" {
+			return nil
+		}
+		bindings[tb.Name] = tb
+		return nil
+	})
+	return bindings, err
+}
@@ -0,0 +1,64 @@
+package pipelines
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"sigs.k8s.io/yaml"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/eventlisteners"
+	"github.com/redhat-developer/kam/pkg/pipelines/scm"
+	"github.com/redhat-developer/kam/pkg/pipelines/triggersim"
+)
+
+func TestSimulateTriggers(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	m := &config.Manifest{Config: &config.Config{Pipelines: &config.PipelinesConfig{Name: "cicd"}}}
+	repo, err := scm.NewRepository("https://github.com/org/gitops.git")
+	assertNoError(t, err)
+	el, err := eventlisteners.Generate(repo, "cicd", saName, eventlisteners.GitOpsWebhookSecret)
+	assertNoError(t, err)
+	pushBinding, pushBindingName := repo.CreatePushBinding("cicd")
+	base := filepath.Join("/output", pipelinesPath(m.Config))
+	for path, r := range map[string]interface{}{
+		eventListenerPath: el,
+		filepath.Join("05-bindings", pushBindingName+".yaml"): pushBinding,
+	} {
+		b, err := yaml.Marshal(r)
+		assertNoError(t, err)
+		assertNoError(t, afero.WriteFile(fs, filepath.Join(base, path), b, 0644))
+	}
+	header, body, err := repo.CreatePushEvent(scm.PushEvent{Ref: "refs/heads/main", SHA: "6dcb09b5b57875f334f61aebed695e2e4193db5e"}, "secret")
+	assertNoError(t, err)
+	event, err := triggersim.NewEvent(body, header, "")
+	assertNoError(t, err)
+
+	results, err := SimulateTriggers(fs, "/output", m, event, "secret")
+	assertNoError(t, err)
+
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	if r := results[0]; r.Trigger != "ci-dryrun-from-push" || !r.Fired {
+		t.Fatalf("push trigger %s did not fire: %s", r.Trigger, r.Reason)
+	}
+	if r := results[1]; r.Trigger != "ci-dryrun-from-pr" || r.Fired {
+		t.Fatalf("pull request trigger %s fired for a push event", r.Trigger)
+	}
+	if b := results[0].Bindings[0]; b.Name != pushBindingName || b.Error != "" || len(b.Params) == 0 {
+		t.Fatalf("push binding was not resolved: %#v", b)
+	}
+}
+
+func TestSimulateTriggersWithoutEventListener(t *testing.T) {
+	m := &config.Manifest{Config: &config.Config{Pipelines: &config.PipelinesConfig{Name: "cicd"}}}
+	event, err := triggersim.NewEvent(nil, nil, "")
+	assertNoError(t, err)
+
+	_, err = SimulateTriggers(afero.NewMemMapFs(), "/output", m, event, "")
+	if err == nil {
+		t.Fatal("expected an error when the EventListener does not exist")
+	}
+}
@@ -0,0 +1,153 @@
+package status
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/argocd"
+	argoappv1 "github.com/redhat-developer/kam/pkg/pipelines/argocd/v1alpha1"
+	"github.com/redhat-developer/kam/pkg/pipelines/clientconfig"
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+)
+
+// NotFound is reported as the sync and health status of Applications that
+// are defined in the manifest but do not exist in the cluster.
+const NotFound = "NotFound"
+
+// EnvironmentStatus is the live state of the ArgoCD Applications generated for
+// an environment.
+type EnvironmentStatus struct {
+	Name         string               `json:"name"`
+	Applications []*ApplicationStatus `json:"applications"`
+}
+
+// ApplicationStatus is the live state of a single ArgoCD Application.
+type ApplicationStatus struct {
+	// Name is the name of the application in the manifest, this is empty for
+	// the Application that deploys the environment configuration.
+	Name       string      `json:"name,omitempty"`
+	ArgoCDApp  string      `json:"argocdApp"`
+	Sync       string      `json:"sync"`
+	Health     string      `json:"health"`
+	Revision   string      `json:"revision,omitempty"`
+	Operation  string      `json:"operation,omitempty"`
+	Conditions []Condition `json:"conditions,omitempty"`
+}
+
+// Condition is a reported condition of an ArgoCD Application.
+type Condition struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// NewClient creates a dynamic client from the current kubeconfig.
+func NewClient() (dynamic.Interface, error) {
+	cfg, err := clientconfig.GetRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	return dynamic.NewForConfig(cfg)
+}
+
+// Get fetches the ArgoCD Applications that are generated for the manifest
+// from the argoNS namespace, and returns their sync and health per
+// environment.
+func Get(client dynamic.Interface, argoNS string, m *config.Manifest) ([]*EnvironmentStatus, error) {
+	if m.GetArgoCDConfig() == nil {
+		return nil, fmt.Errorf("no ArgoCD configuration found in the manifest")
+	}
+	sv := &statusVisitor{client: client, argoNS: argoNS, envs: map[string]*EnvironmentStatus{}}
+	if err := m.Walk(sv); err != nil {
+		return nil, err
+	}
+	return sv.ordered, nil
+}
+
+// GetApplication fetches a named ArgoCD Application from the cluster.
+func GetApplication(client dynamic.Interface, ns, name string) (*argoappv1.Application, error) {
+	u, err := client.Resource(argocd.ApplicationsResource).Namespace(ns).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	app := &argoappv1.Application{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, app); err != nil {
+		return nil, fmt.Errorf("failed to convert Application %s: %w", name, err)
+	}
+	return app, nil
+}
+
+type statusVisitor struct {
+	client  dynamic.Interface
+	argoNS  string
+	envs    map[string]*EnvironmentStatus
+	ordered []*EnvironmentStatus
+}
+
+func (sv *statusVisitor) Application(env *config.Environment, app *config.Application) error {
+	s, err := sv.applicationStatus(argocd.AppName(env, app))
+	if err != nil {
+		return err
+	}
+	s.Name = app.Name
+	es := sv.environment(env)
+	es.Applications = append(es.Applications, s)
+	return nil
+}
+
+func (sv *statusVisitor) Environment(env *config.Environment) error {
+	s, err := sv.applicationStatus(argocd.EnvAppName(env))
+	if err != nil {
+		return err
+	}
+	es := sv.environment(env)
+	es.Applications = append([]*ApplicationStatus{s}, es.Applications...)
+	return nil
+}
+
+func (sv *statusVisitor) environment(env *config.Environment) *EnvironmentStatus {
+	es, ok := sv.envs[env.Name]
+	if !ok {
+		es = &EnvironmentStatus{Name: env.Name, Applications: []*ApplicationStatus{}}
+		sv.envs[env.Name] = es
+		sv.ordered = append(sv.ordered, es)
+	}
+	return es
+}
+
+func (sv *statusVisitor) applicationStatus(name string) (*ApplicationStatus, error) {
+	app, err := GetApplication(sv.client, sv.argoNS, name)
+	if apierrors.IsNotFound(err) {
+		return &ApplicationStatus{ArgoCDApp: name, Sync: NotFound, Health: NotFound}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Application %s: %w", name, err)
+	}
+	return makeApplicationStatus(app), nil
+}
+
+func makeApplicationStatus(app *argoappv1.Application) *ApplicationStatus {
+	s := &ApplicationStatus{
+		ArgoCDApp: app.Name,
+		Sync:      string(app.Status.Sync.Status),
+		Health:    app.Status.Health.Status,
+		Revision:  app.Status.Sync.Revision,
+	}
+	if s.Sync == "" {
+		s.Sync = string(argoappv1.SyncStatusCodeUnknown)
+	}
+	if s.Health == "" {
+		s.Health = argoappv1.HealthStatusUnknown
+	}
+	if op := app.Status.OperationState; op != nil {
+		s.Operation = string(op.Phase)
+	}
+	for _, c := range app.Status.Conditions {
+		s.Conditions = append(s.Conditions, Condition{Type: c.Type, Message: c.Message})
+	}
+	return s
+}
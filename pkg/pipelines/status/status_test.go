@@ -0,0 +1,117 @@
+package status
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/argocd"
+	argoappv1 "github.com/redhat-developer/kam/pkg/pipelines/argocd/v1alpha1"
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+)
+
+const testArgoNS = "openshift-gitops"
+
+func TestGet(t *testing.T) {
+	m := &config.Manifest{
+		Config: &config.Config{
+			ArgoCD: &config.ArgoCDConfig{Namespace: testArgoNS},
+		},
+		Environments: []*config.Environment{
+			{
+				Name: "stage",
+			},
+			{
+				Name: "dev",
+				Apps: []*config.Application{
+					{Name: "taxi"},
+					{Name: "bus"},
+				},
+			},
+		},
+	}
+	client := newFakeClient(t,
+		makeApplication("dev-env", argoappv1.SyncStatusCodeSynced, argoappv1.HealthStatusHealthy, "abc123"),
+		makeApplication("dev-taxi", argoappv1.SyncStatusCodeOutOfSync, argoappv1.HealthStatusDegraded, "def456",
+			argoappv1.ApplicationCondition{Type: argoappv1.ApplicationConditionSyncError, Message: "failed to sync"}),
+		makeApplication("stage-env", argoappv1.SyncStatusCodeSynced, argoappv1.HealthStatusProgressing, "abc123"),
+	)
+
+	got, err := Get(client, testArgoNS, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*EnvironmentStatus{
+		{
+			Name: "dev",
+			Applications: []*ApplicationStatus{
+				{ArgoCDApp: "dev-env", Sync: "Synced", Health: "Healthy", Revision: "abc123", Operation: "Succeeded"},
+				{Name: "taxi", ArgoCDApp: "dev-taxi", Sync: "OutOfSync", Health: "Degraded", Revision: "def456", Operation: "Succeeded",
+					Conditions: []Condition{{Type: "SyncError", Message: "failed to sync"}}},
+				{Name: "bus", ArgoCDApp: "dev-bus", Sync: NotFound, Health: NotFound},
+			},
+		},
+		{
+			Name: "stage",
+			Applications: []*ApplicationStatus{
+				{ArgoCDApp: "stage-env", Sync: "Synced", Health: "Progressing", Revision: "abc123", Operation: "Succeeded"},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("Get() failed:\n%s", diff)
+	}
+}
+
+func TestGetWithNoArgoCDConfig(t *testing.T) {
+	m := &config.Manifest{
+		Environments: []*config.Environment{{Name: "dev"}},
+	}
+
+	_, err := Get(newFakeClient(t), testArgoNS, m)
+	if err == nil || err.Error() != "no ArgoCD configuration found in the manifest" {
+		t.Fatalf("Get() got error %v", err)
+	}
+}
+
+func TestMakeApplicationStatusDefaultsToUnknown(t *testing.T) {
+	app := &argoappv1.Application{ObjectMeta: meta.ObjectMeta(meta.NamespacedName(testArgoNS, "dev-env"))}
+
+	want := &ApplicationStatus{ArgoCDApp: "dev-env", Sync: "Unknown", Health: "Unknown"}
+	if diff := cmp.Diff(want, makeApplicationStatus(app)); diff != "" {
+		t.Fatalf("makeApplicationStatus() failed:\n%s", diff)
+	}
+}
+
+func makeApplication(name string, sync argoappv1.SyncStatusCode, health argoappv1.HealthStatusCode, revision string, conditions ...argoappv1.ApplicationCondition) *argoappv1.Application {
+	return &argoappv1.Application{
+		TypeMeta:   meta.TypeMeta("Application", "argoproj.io/v1alpha1"),
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(testArgoNS, name)),
+		Status: argoappv1.ApplicationStatus{
+			Sync:           argoappv1.SyncStatus{Status: sync, Revision: revision},
+			Health:         argoappv1.HealthStatus{Status: health},
+			Conditions:     conditions,
+			OperationState: &argoappv1.OperationState{Phase: argoappv1.OperationSucceeded},
+		},
+	}
+}
+
+func newFakeClient(t *testing.T, apps ...*argoappv1.Application) *fake.FakeDynamicClient {
+	t.Helper()
+	objs := []runtime.Object{}
+	for _, app := range apps {
+		raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(app)
+		if err != nil {
+			t.Fatal(err)
+		}
+		objs = append(objs, &unstructured.Unstructured{Object: raw})
+	}
+	return fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{argocd.ApplicationsResource: "ApplicationList"}, objs...)
+}
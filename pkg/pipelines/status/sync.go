@@ -0,0 +1,132 @@
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/argocd"
+	argoappv1 "github.com/redhat-developer/kam/pkg/pipelines/argocd/v1alpha1"
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+)
+
+const (
+	refreshAnnotation = "argocd.argoproj.io/refresh"
+	syncInitiator     = "kam"
+)
+
+// ApplicationNames returns the names of the ArgoCD Applications that are
+// generated for an environment, or for a single application within the
+// environment if appName is not empty.
+func ApplicationNames(m *config.Manifest, envName, appName string) ([]string, error) {
+	env := m.GetEnvironment(envName)
+	if env == nil {
+		return nil, fmt.Errorf("environment %s does not exist", envName)
+	}
+	if appName != "" {
+		app := m.GetApplication(envName, appName)
+		if app == nil {
+			return nil, fmt.Errorf("application %s does not exist in environment %s", appName, envName)
+		}
+		return []string{argocd.AppName(env, app)}, nil
+	}
+	names := []string{argocd.EnvAppName(env)}
+	for _, app := range env.Apps {
+		names = append(names, argocd.AppName(env, app))
+	}
+	return names, nil
+}
+
+// Sync requests a hard refresh and a sync of the named ArgoCD Application.
+func Sync(client dynamic.Interface, ns, name string) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{refreshAnnotation: "hard"},
+		},
+		"operation": &argoappv1.Operation{
+			Sync:        &argoappv1.SyncOperation{Prune: true},
+			InitiatedBy: argoappv1.OperationInitiator{Username: syncInitiator},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = client.Resource(argocd.ApplicationsResource).Namespace(ns).Patch(context.Background(), name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to sync Application %s: %w", name, err)
+	}
+	return nil
+}
+
+// Wait polls the named ArgoCD Applications until their sync operations have
+// completed and they are healthy.
+//
+// An error is returned if any of the operations fail, an Application becomes
+// degraded, or they are not all complete within the timeout.
+func Wait(client dynamic.Interface, ns string, names []string, interval, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for _, name := range names {
+		if err := waitForApplication(client, ns, name, interval, deadline, timeout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func waitForApplication(client dynamic.Interface, ns, name string, interval time.Duration, deadline time.Time, timeout time.Duration) error {
+	var last *ApplicationStatus
+	check := func() (bool, error) {
+		app, err := GetApplication(client, ns, name)
+		if err != nil {
+			return false, err
+		}
+		last = makeApplicationStatus(app)
+		return syncComplete(app)
+	}
+	var err error
+	if remaining := time.Until(deadline); remaining > 0 {
+		err = wait.PollImmediate(interval, remaining, check)
+	} else {
+		// The timeout was used up by the earlier Applications, but this one may
+		// have completed in the meantime.
+		var done bool
+		done, err = check()
+		if err == nil && !done {
+			err = wait.ErrWaitTimeout
+		}
+	}
+	if errors.Is(err, wait.ErrWaitTimeout) {
+		return fmt.Errorf("timed out after %s waiting for Application %s, sync: %s, health: %s", timeout, name, last.Sync, last.Health)
+	}
+	return err
+}
+
+// syncComplete returns true when the requested operation has been carried out
+// and the Application is healthy.
+func syncComplete(app *argoappv1.Application) (bool, error) {
+	// The controller removes the operation once it has finished with it.
+	if app.Operation != nil || app.Status.OperationState == nil {
+		return false, nil
+	}
+	switch op := app.Status.OperationState; op.Phase {
+	case argoappv1.OperationFailed, argoappv1.OperationError:
+		return false, fmt.Errorf("sync of Application %s failed: %s", app.Name, op.Message)
+	case argoappv1.OperationSucceeded:
+	default:
+		return false, nil
+	}
+	switch app.Status.Health.Status {
+	case argoappv1.HealthStatusHealthy:
+		return true, nil
+	case argoappv1.HealthStatusDegraded:
+		return false, fmt.Errorf("health of Application %s is degraded: %s", app.Name, app.Status.Health.Message)
+	}
+	return false, nil
+}
@@ -0,0 +1,141 @@
+package status
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/argocd"
+
+	argoappv1 "github.com/redhat-developer/kam/pkg/pipelines/argocd/v1alpha1"
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/test"
+)
+
+func TestApplicationNames(t *testing.T) {
+	m := &config.Manifest{
+		Environments: []*config.Environment{
+			{
+				Name: "dev",
+				Apps: []*config.Application{
+					{Name: "taxi"},
+					{Name: "bus"},
+				},
+			},
+		},
+	}
+
+	namesTests := []struct {
+		envName string
+		appName string
+		want    []string
+		wantErr string
+	}{
+		{"dev", "", []string{"dev-env", "dev-taxi", "dev-bus"}, ""},
+		{"dev", "bus", []string{"dev-bus"}, ""},
+		{"stage", "", nil, "environment stage does not exist"},
+		{"dev", "train", nil, "application train does not exist in environment dev"},
+	}
+
+	for _, tt := range namesTests {
+		got, err := ApplicationNames(m, tt.envName, tt.appName)
+		if !test.ErrorMatch(t, tt.wantErr, err) {
+			t.Errorf("ApplicationNames(%q, %q) got error %v, want %q", tt.envName, tt.appName, err, tt.wantErr)
+		}
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("ApplicationNames(%q, %q) failed:\n%s", tt.envName, tt.appName, diff)
+		}
+	}
+}
+
+func TestSync(t *testing.T) {
+	client := newFakeClient(t, makeApplication("dev-env", argoappv1.SyncStatusCodeOutOfSync, argoappv1.HealthStatusHealthy, "abc123"))
+
+	if err := Sync(client, testArgoNS, "dev-env"); err != nil {
+		t.Fatal(err)
+	}
+
+	app, err := GetApplication(client, testArgoNS, "dev-env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := app.Annotations[refreshAnnotation]; v != "hard" {
+		t.Errorf("got refresh annotation %q, want %q", v, "hard")
+	}
+	want := &argoappv1.Operation{
+		Sync:        &argoappv1.SyncOperation{Prune: true},
+		InitiatedBy: argoappv1.OperationInitiator{Username: "kam"},
+	}
+	if diff := cmp.Diff(want, app.Operation); diff != "" {
+		t.Fatalf("sync operation incorrect:\n%s", diff)
+	}
+}
+
+func TestSyncMissingApplication(t *testing.T) {
+	err := Sync(newFakeClient(t), testArgoNS, "dev-env")
+	test.AssertErrorMatch(t, "failed to sync Application dev-env", err)
+}
+
+func TestWait(t *testing.T) {
+	failed := makeApplication("dev-taxi", argoappv1.SyncStatusCodeOutOfSync, argoappv1.HealthStatusHealthy, "abc123")
+	failed.Status.OperationState = &argoappv1.OperationState{Phase: argoappv1.OperationFailed, Message: "one or more objects failed to apply"}
+	pending := makeApplication("dev-bus", argoappv1.SyncStatusCodeOutOfSync, argoappv1.HealthStatusHealthy, "abc123")
+	pending.Operation = &argoappv1.Operation{Sync: &argoappv1.SyncOperation{}}
+
+	client := newFakeClient(t,
+		makeApplication("dev-env", argoappv1.SyncStatusCodeSynced, argoappv1.HealthStatusHealthy, "abc123"),
+		makeApplication("dev-walk", argoappv1.SyncStatusCodeSynced, argoappv1.HealthStatusDegraded, "abc123"),
+		failed, pending,
+	)
+
+	waitTests := []struct {
+		names   []string
+		wantErr string
+	}{
+		{[]string{"dev-env"}, ""},
+		{[]string{"dev-env", "dev-taxi"}, "sync of Application dev-taxi failed: one or more objects failed to apply"},
+		{[]string{"dev-walk"}, "health of Application dev-walk is degraded"},
+		{[]string{"dev-bus"}, "timed out after 50ms waiting for Application dev-bus, sync: OutOfSync, health: Healthy"},
+		{[]string{"dev-train"}, `applications.argoproj.io "dev-train" not found`},
+	}
+
+	for _, tt := range waitTests {
+		err := Wait(client, testArgoNS, tt.names, 10*time.Millisecond, 50*time.Millisecond)
+		if !test.ErrorMatch(t, tt.wantErr, err) {
+			t.Errorf("Wait(%v) got error %v, want %q", tt.names, err, tt.wantErr)
+		}
+	}
+}
+
+func TestWaitSharesTimeoutBetweenApplications(t *testing.T) {
+	slow := makeApplication("dev-taxi", argoappv1.SyncStatusCodeOutOfSync, argoappv1.HealthStatusHealthy, "abc123")
+	slow.Operation = &argoappv1.Operation{Sync: &argoappv1.SyncOperation{}}
+	pending := makeApplication("dev-bus", argoappv1.SyncStatusCodeOutOfSync, argoappv1.HealthStatusHealthy, "abc123")
+	pending.Operation = &argoappv1.Operation{Sync: &argoappv1.SyncOperation{}}
+	client := newFakeClient(t, slow, pending)
+
+	synced, err := runtime.DefaultUnstructuredConverter.ToUnstructured(
+		makeApplication("dev-taxi", argoappv1.SyncStatusCodeSynced, argoappv1.HealthStatusHealthy, "abc123"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	timer := time.AfterFunc(150*time.Millisecond, func() {
+		_, err := client.Resource(argocd.ApplicationsResource).Namespace(testArgoNS).Update(context.Background(), &unstructured.Unstructured{Object: synced}, metav1.UpdateOptions{})
+		if err != nil {
+			t.Error(err)
+		}
+	})
+	defer timer.Stop()
+
+	start := time.Now()
+	err = Wait(client, testArgoNS, []string{"dev-taxi", "dev-bus"}, 10*time.Millisecond, 200*time.Millisecond)
+	test.AssertErrorMatch(t, "timed out after 200ms waiting for Application dev-bus", err)
+	if elapsed := time.Since(start); elapsed > 300*time.Millisecond {
+		t.Fatalf("Wait() took %s, want less than the 200ms timeout plus polling", elapsed)
+	}
+}
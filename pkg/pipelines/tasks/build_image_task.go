@@ -0,0 +1,64 @@
+package tasks
+
+import (
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+)
+
+const (
+	// BuildImageTaskName is the name of the task that builds an image
+	// without pushing it.
+	BuildImageTaskName = "build-image-without-push"
+
+	buildahImage          = "quay.io/buildah/stable:v1.17.0"
+	containerStorageMount = "/var/lib/containers"
+)
+
+// CreateBuildImageTask creates a Task that builds the image from a cloned
+// source workspace, but does not push it, this is used to check pull requests.
+func CreateBuildImageTask(ns string) pipelinev1.Task {
+	privileged := true
+	return pipelinev1.Task{
+		TypeMeta:   taskTypeMeta,
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(ns, BuildImageTaskName)),
+		Spec: pipelinev1.TaskSpec{
+			Params: []pipelinev1.ParamSpec{
+				createTaskParam("IMAGE", "Reference of the image that is built.", pipelinev1.ParamTypeString),
+				createTaskParamWithDefault("DOCKERFILE", "Path to the Dockerfile to build.", pipelinev1.ParamTypeString, "./Dockerfile"),
+				createTaskParamWithDefault("CONTEXT", "Path to the directory to use as context.", pipelinev1.ParamTypeString, "."),
+				createTaskParamWithDefault("TLSVERIFY", "Verify the TLS on the registry endpoint.", pipelinev1.ParamTypeString, "true"),
+				createTaskParamWithDefault("BUILD_EXTRA_ARGS", "Extra parameters passed for the build command.", pipelinev1.ParamTypeString, ""),
+			},
+			Workspaces: []pipelinev1.WorkspaceDeclaration{
+				{Name: "source"},
+			},
+			Steps: []pipelinev1.Step{
+				{
+					Container: corev1.Container{
+						Name:       "build",
+						Image:      buildahImage,
+						WorkingDir: "$(workspaces.source.path)",
+						Command:    []string{"buildah", "bud"},
+						Args: []string{
+							"--tls-verify=$(params.TLSVERIFY)",
+							"--layers",
+							"$(params.BUILD_EXTRA_ARGS)",
+							"-f", "$(params.DOCKERFILE)",
+							"-t", "$(params.IMAGE)",
+							"$(params.CONTEXT)",
+						},
+						VolumeMounts: []corev1.VolumeMount{
+							{Name: "varlibcontainers", MountPath: containerStorageMount},
+						},
+						SecurityContext: &corev1.SecurityContext{Privileged: &privileged},
+					},
+				},
+			},
+			Volumes: []corev1.Volume{
+				{Name: "varlibcontainers", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+			},
+		},
+	}
+}
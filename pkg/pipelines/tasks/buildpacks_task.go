@@ -0,0 +1,101 @@
+package tasks
+
+import (
+	"fmt"
+
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+)
+
+const (
+	// BuildpacksTaskName is the name of the task that builds and pushes an
+	// image with Cloud Native Buildpacks.
+	BuildpacksTaskName = "buildpacks"
+
+	bashImage = "docker.io/library/bash:5.1.4"
+
+	// The user and group of the builder images from the Cloud Native
+	// Buildpacks project.
+	buildpacksUserID  = 1000
+	buildpacksGroupID = 1000
+)
+
+// The lifecycle in the builder image runs as the builder's user, which must own
+// the source, the layers and cache, and the home directory with the registry
+// credentials.
+var prepareBuildpacksScript = fmt.Sprintf(`#!/usr/bin/env bash
+set -e
+chown -R "%d:%d" /tekton/home /layers /cache "$(workspaces.source.path)"
+`, buildpacksUserID, buildpacksGroupID)
+
+// CreateBuildpacksTask creates a Task that builds an image from a cloned
+// source workspace with the lifecycle of a Cloud Native Buildpacks builder
+// image, and pushes it, this is based on the buildpacks Task in the Tekton
+// catalog.
+func CreateBuildpacksTask(ns string) pipelinev1.Task {
+	uid, gid := int64(buildpacksUserID), int64(buildpacksGroupID)
+	return pipelinev1.Task{
+		TypeMeta:   taskTypeMeta,
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(ns, BuildpacksTaskName)),
+		Spec: pipelinev1.TaskSpec{
+			Params: []pipelinev1.ParamSpec{
+				createTaskParam("APP_IMAGE", "Reference of the image that is built.", pipelinev1.ParamTypeString),
+				createTaskParam("BUILDER_IMAGE", "The Cloud Native Buildpacks builder image.", pipelinev1.ParamTypeString),
+				createTaskParamWithDefault("SOURCE_SUBPATH", "The directory of the source to build.", pipelinev1.ParamTypeString, ""),
+				createTaskParamWithDefault("PROCESS_TYPE", "The default process type of the image.", pipelinev1.ParamTypeString, "web"),
+				createTaskParamWithDefault("RUN_IMAGE", "The run image, if not the builder's default.", pipelinev1.ParamTypeString, ""),
+				createTaskParamWithDefault("CACHE_IMAGE", "The image to cache the layers in, if any.", pipelinev1.ParamTypeString, ""),
+			},
+			Workspaces: []pipelinev1.WorkspaceDeclaration{
+				{Name: "source"},
+			},
+			Steps: []pipelinev1.Step{
+				{
+					Container: corev1.Container{
+						Name:  "prepare",
+						Image: bashImage,
+						VolumeMounts: []corev1.VolumeMount{
+							{Name: "layers-dir", MountPath: "/layers"},
+							{Name: "cache-dir", MountPath: "/cache"},
+						},
+					},
+					Script: prepareBuildpacksScript,
+				},
+				{
+					Container: corev1.Container{
+						Name:            "create",
+						Image:           "$(params.BUILDER_IMAGE)",
+						ImagePullPolicy: corev1.PullAlways,
+						Command:         []string{"/cnb/lifecycle/creator"},
+						Args: []string{
+							"-app=$(workspaces.source.path)/$(params.SOURCE_SUBPATH)",
+							"-cache-dir=/cache",
+							"-cache-image=$(params.CACHE_IMAGE)",
+							fmt.Sprintf("-uid=%d", buildpacksUserID),
+							fmt.Sprintf("-gid=%d", buildpacksGroupID),
+							"-layers=/layers",
+							"-platform=/platform",
+							"-process-type=$(params.PROCESS_TYPE)",
+							"-previous-image=$(params.APP_IMAGE)",
+							"-run-image=$(params.RUN_IMAGE)",
+							"$(params.APP_IMAGE)",
+						},
+						VolumeMounts: []corev1.VolumeMount{
+							{Name: "layers-dir", MountPath: "/layers"},
+							{Name: "cache-dir", MountPath: "/cache"},
+							{Name: "platform-dir", MountPath: "/platform"},
+						},
+						SecurityContext: &corev1.SecurityContext{RunAsUser: &uid, RunAsGroup: &gid},
+					},
+				},
+			},
+			Volumes: []corev1.Volume{
+				{Name: "layers-dir", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+				{Name: "cache-dir", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+				{Name: "platform-dir", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+			},
+		},
+	}
+}
@@ -6,15 +6,30 @@ import (
 	"github.com/redhat-developer/kam/pkg/pipelines/meta"
 )
 
+const (
+	// SummaryFile is the file in the source workspace that the deploy script
+	// writes the summary of the applied environments to.
+	//
+	// The summary isn't a result of the task, as the finally tasks that refer
+	// to the results of a failed task are skipped, and the summary is most
+	// useful when the dry-run fails.
+	SummaryFile = ".kam-summary.md"
+
+	// SummaryPath is the path the deploy script writes the summary to.
+	SummaryPath = "$(workspaces.source.path)/" + SummaryFile
+)
+
 // CreateDeployFromSourceTask creates DeployFromSourceTask
 func CreateDeployFromSourceTask(ns, script string) pipelinev1.Task {
 	task := pipelinev1.Task{
 		TypeMeta:   taskTypeMeta,
 		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(ns, "deploy-from-source-task")),
 		Spec: pipelinev1.TaskSpec{
-			Params:    paramsForDeploymentFromSourceTask(),
-			Resources: createResourcesForDeployFromSourceTask(),
-			Steps:     createStepsForDeployFromSourceTask(script),
+			Params: paramsForDeploymentFromSourceTask(),
+			Workspaces: []pipelinev1.WorkspaceDeclaration{
+				{Name: "source", Description: "The cloned GitOps repository."},
+			},
+			Steps: createStepsForDeployFromSourceTask(script),
 		},
 	}
 	return task
@@ -26,7 +41,7 @@ func createStepsForDeployFromSourceTask(script string) []pipelinev1.Step {
 			Container: createContainer(
 				"run-kubectl",
 				"quay.io/redhat-developer/k8s-kubectl",
-				"/workspace/source",
+				"$(workspaces.source.path)",
 				nil,
 				nil,
 			),
@@ -45,11 +60,3 @@ func paramsForDeploymentFromSourceTask() []pipelinev1.ParamSpec {
 		),
 	}
 }
-
-func createResourcesForDeployFromSourceTask() *pipelinev1.TaskResources {
-	return &pipelinev1.TaskResources{
-		Inputs: []pipelinev1.TaskResource{
-			createTaskResource("source", "git"),
-		},
-	}
-}
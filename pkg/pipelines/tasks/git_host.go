@@ -0,0 +1,101 @@
+package tasks
+
+import (
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// GitHostCAConfigMap is the default name of the ConfigMap in the CI/CD
+	// namespace with the CA certificate of a self-hosted Git host, in the
+	// ca.crt key.
+	GitHostCAConfigMap = "git-host-ca"
+
+	gitHostCAPath = "/etc/git-host-ca"
+	pythonImage   = "registry.access.redhat.com/ubi8/python-38"
+)
+
+// GitHost is the Git host that the tasks call the API of, APIURL is the base
+// URL of the REST API, e.g. https://github.example.com/api/v3.
+type GitHost struct {
+	Driver string
+	APIURL string
+}
+
+// gitHostScript is the start of the Python scripts that call the API of the
+// Git host, the CA certificate is trusted in addition to the system CAs if
+// the ConfigMap exists.
+const gitHostScript = `#!/usr/bin/env python3
+import json
+import os
+import ssl
+import urllib.parse
+import urllib.request
+
+driver = "$(params.GIT_DRIVER)"
+api_url = "$(params.GIT_API_URL)".rstrip("/")
+token = os.environ.get("GITHOSTACCESSTOKEN", "")
+
+context = ssl.create_default_context()
+if os.path.exists("` + gitHostCAPath + `/ca.crt"):
+    context.load_verify_locations("` + gitHostCAPath + `/ca.crt")
+
+def api_request(path, body, method="POST"):
+    if driver == "gitlab":
+        headers = {"PRIVATE-TOKEN": token}
+    elif driver in ("bitbucket", "stash"):
+        headers = {"Authorization": "Bearer " + token}
+    else:
+        headers = {"Authorization": "token " + token}
+    headers["Content-Type"] = "application/json"
+    data = json.dumps(body).encode()
+    req = urllib.request.Request(api_url + path, data=data, headers=headers, method=method)
+    return urllib.request.urlopen(req, context=context)
+`
+
+func gitHostParams(host GitHost) []pipelinev1.ParamSpec {
+	return []pipelinev1.ParamSpec{
+		createTaskParamWithDefault("GIT_DRIVER", "The driver of the git host, one of github, gitlab, bitbucket, stash or gitea.", pipelinev1.ParamTypeString, host.Driver),
+		createTaskParamWithDefault("GIT_API_URL", "The base URL of the REST API of the git host.", pipelinev1.ParamTypeString, host.APIURL),
+		createTaskParamWithDefault("GIT_CA_CONFIGMAP", "The ConfigMap with the CA certificate of the git host in the ca.crt key, the system CAs are used if it doesn't exist.", pipelinev1.ParamTypeString, GitHostCAConfigMap),
+	}
+}
+
+func gitHostVolumes() []corev1.Volume {
+	optional := true
+	return []corev1.Volume{
+		{
+			Name: "git-host-ca",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "$(params.GIT_CA_CONFIGMAP)"},
+					Optional:             &optional,
+				},
+			},
+		},
+	}
+}
+
+func gitHostVolumeMounts() []corev1.VolumeMount {
+	return []corev1.VolumeMount{
+		{Name: "git-host-ca", MountPath: gitHostCAPath, ReadOnly: true},
+	}
+}
+
+// gitHostTokenEnv is the access token for the API of the Git host, from the
+// secret in the params.
+func gitHostTokenEnv(optional bool) corev1.EnvVar {
+	selector := &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{
+			Name: "$(params.GIT_TOKEN_SECRET_NAME)",
+		},
+		Key: "$(params.GIT_TOKEN_SECRET_KEY)",
+	}
+	if optional {
+		selector.Optional = &optional
+	}
+	return corev1.EnvVar{
+		Name:      "GITHOSTACCESSTOKEN",
+		ValueFrom: &corev1.EnvVarSource{SecretKeyRef: selector},
+	}
+}
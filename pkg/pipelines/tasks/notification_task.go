@@ -0,0 +1,107 @@
+package tasks
+
+import (
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+)
+
+// NotificationTaskName is the name of the task that posts a message about a
+// finished PipelineRun to a notification endpoint.
+const NotificationTaskName = "send-notification"
+
+// The params are read from the environment rather than substituted into the
+// script, as the commit author and service may contain quotes.
+//
+// The "text" field is a summary for chat integrations that only show it, the
+// other fields are for endpoints that format their own messages.
+//
+// A failure to send the message is logged, but doesn't fail the task, so that
+// the status of the PipelineRun is the status of the pipeline.
+const notificationScript = `#!/usr/bin/env python3
+import json
+import os
+import sys
+import urllib.request
+
+status = os.environ["STATUS"]
+commit = os.environ["COMMIT_SHA"]
+message = {
+    "service": os.environ["SERVICE"] or os.environ["REPO"],
+    "commit": commit,
+    "author": os.environ["COMMIT_AUTHOR"],
+    "status": status,
+    "pipelineRun": {
+        "name": os.environ["PIPELINE_RUN"],
+        "namespace": os.environ["PIPELINE_RUN_NAMESPACE"],
+        "url": os.environ["PIPELINE_RUN_URL"],
+    },
+}
+text = "%s: pipeline %s for %s" % (message["service"], status.lower(), commit[:7])
+if message["author"]:
+    text += " by " + message["author"]
+text += " (%s)" % (message["pipelineRun"]["url"] or message["pipelineRun"]["name"])
+message["text"] = text
+
+req = urllib.request.Request(
+    os.environ["NOTIFICATION_URL"],
+    data=json.dumps(message).encode(),
+    headers={"Content-Type": "application/json"},
+    method="POST")
+try:
+    urllib.request.urlopen(req, timeout=30)
+except Exception as e:
+    print("failed to send the notification: %s" % e, file=sys.stderr)
+`
+
+// CreateNotificationTask creates a Task that posts a JSON message with the
+// status of a PipelineRun to the URL in a secret.
+func CreateNotificationTask(ns string) *pipelinev1.Task {
+	return &pipelinev1.Task{
+		TypeMeta:   taskTypeMeta,
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(ns, NotificationTaskName)),
+		Spec: pipelinev1.TaskSpec{
+			Params: []pipelinev1.ParamSpec{
+				createTaskParam("URL_SECRET_NAME", "The secret with the URL of the notification endpoint.", pipelinev1.ParamTypeString),
+				createTaskParamWithDefault("URL_SECRET_KEY", "The key of the secret with the URL.", pipelinev1.ParamTypeString, "url"),
+				createTaskParam("SERVICE", "The service, or repository, that the pipeline ran for.", pipelinev1.ParamTypeString),
+				createTaskParamWithDefault("REPO", "The repository that the pipeline ran for, if the SERVICE is empty.", pipelinev1.ParamTypeString, ""),
+				createTaskParam("COMMIT_SHA", "The commit that the pipeline ran for.", pipelinev1.ParamTypeString),
+				createTaskParamWithDefault("COMMIT_AUTHOR", "The author of the commit.", pipelinev1.ParamTypeString, ""),
+				createTaskParam("STATUS", "The status of the pipeline, e.g. Succeeded or Failed.", pipelinev1.ParamTypeString),
+				createTaskParam("PIPELINE_RUN", "The name of the PipelineRun.", pipelinev1.ParamTypeString),
+				createTaskParam("PIPELINE_RUN_NAMESPACE", "The namespace of the PipelineRun.", pipelinev1.ParamTypeString),
+				createTaskParamWithDefault("PIPELINE_RUN_URL", "The link to the PipelineRun in the console.", pipelinev1.ParamTypeString, ""),
+			},
+			Steps: []pipelinev1.Step{
+				{
+					Container: corev1.Container{
+						Name:  "send-notification",
+						Image: pythonImage,
+						Env: []corev1.EnvVar{
+							{Name: "SERVICE", Value: "$(params.SERVICE)"},
+							{Name: "REPO", Value: "$(params.REPO)"},
+							{Name: "COMMIT_SHA", Value: "$(params.COMMIT_SHA)"},
+							{Name: "COMMIT_AUTHOR", Value: "$(params.COMMIT_AUTHOR)"},
+							{Name: "STATUS", Value: "$(params.STATUS)"},
+							{Name: "PIPELINE_RUN", Value: "$(params.PIPELINE_RUN)"},
+							{Name: "PIPELINE_RUN_NAMESPACE", Value: "$(params.PIPELINE_RUN_NAMESPACE)"},
+							{Name: "PIPELINE_RUN_URL", Value: "$(params.PIPELINE_RUN_URL)"},
+							{
+								Name: "NOTIFICATION_URL",
+								ValueFrom: &corev1.EnvVarSource{
+									SecretKeyRef: &corev1.SecretKeySelector{
+										LocalObjectReference: corev1.LocalObjectReference{Name: "$(params.URL_SECRET_NAME)"},
+										Key:                  "$(params.URL_SECRET_KEY)",
+									},
+								},
+							},
+						},
+					},
+					Script: notificationScript,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,81 @@
+package tasks
+
+import (
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+)
+
+// PRCommentTaskName is the name of the task that comments on pull requests.
+const PRCommentTaskName = "post-pr-comment"
+
+const prCommentScript = gitHostScript + `
+repo = "$(params.REPO)"
+number = "$(params.PULL_REQUEST)"
+comment = os.environ["COMMENT"]
+
+comment_file = "$(params.COMMENT_FILE)"
+if comment_file:
+    comment_path = os.path.join("$(workspaces.source.path)", comment_file)
+    if os.path.exists(comment_path):
+        with open(comment_path) as f:
+            comment += "\n\n" + f.read()
+
+if driver == "gitlab":
+    path = "/projects/%s/merge_requests/%s/notes" % (urllib.parse.quote(repo, safe=""), number)
+    api_request(path, {"body": comment})
+elif driver == "bitbucket":
+    path = "/repositories/%s/pullrequests/%s/comments" % (repo, number)
+    api_request(path, {"content": {"raw": comment}})
+elif driver == "stash":
+    project, slug = repo.split("/")
+    path = "/api/1.0/projects/%s/repos/%s/pull-requests/%s/comments" % (project, slug, number)
+    api_request(path, {"text": comment})
+else:
+    path = "/repos/%s/issues/%s/comments" % (repo, number)
+    api_request(path, {"body": comment})
+`
+
+// CreatePRCommentTask creates a Task that adds a comment to a GitHub pull
+// request, GitLab merge request, or Bitbucket or Gitea pull request, with the
+// API of the host as the default.
+//
+// If the COMMENT_FILE is provided, the contents of the file in the optional
+// source workspace are appended to the comment, if the file exists.
+func CreatePRCommentTask(ns string, host GitHost) *pipelinev1.Task {
+	params := []pipelinev1.ParamSpec{
+		createTaskParam("GIT_REPO", "The clone URL of the repository.", pipelinev1.ParamTypeString),
+		createTaskParam("REPO", "The full name of the repository, e.g. org/repo.", pipelinev1.ParamTypeString),
+		createTaskParam("PULL_REQUEST", "The number of the pull request to comment on.", pipelinev1.ParamTypeString),
+		createTaskParam("COMMENT", "The body of the comment.", pipelinev1.ParamTypeString),
+		createTaskParamWithDefault("COMMENT_FILE", "The path of a file in the source workspace to append to the comment.", pipelinev1.ParamTypeString, ""),
+		createTaskParamWithDefault("GIT_TOKEN_SECRET_NAME", "", pipelinev1.ParamTypeString, "git-host-access-token"),
+		createTaskParamWithDefault("GIT_TOKEN_SECRET_KEY", "", pipelinev1.ParamTypeString, "token"),
+	}
+	return &pipelinev1.Task{
+		TypeMeta:   taskTypeMeta,
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(ns, PRCommentTaskName)),
+		Spec: pipelinev1.TaskSpec{
+			Params: append(params, gitHostParams(host)...),
+			Workspaces: []pipelinev1.WorkspaceDeclaration{
+				{Name: "source", Description: "The workspace with the COMMENT_FILE.", Optional: true},
+			},
+			Steps: []pipelinev1.Step{
+				{
+					Container: corev1.Container{
+						Name:  "post-comment",
+						Image: pythonImage,
+						Env: []corev1.EnvVar{
+							{Name: "COMMENT", Value: "$(params.COMMENT)"},
+							gitHostTokenEnv(false),
+						},
+						VolumeMounts: gitHostVolumeMounts(),
+					},
+					Script: prCommentScript,
+				},
+			},
+			Volumes: gitHostVolumes(),
+		},
+	}
+}
@@ -1,51 +1,75 @@
 package tasks
 
 import (
-	"github.com/redhat-developer/kam/pkg/pipelines/meta"
-	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
-	v1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
 )
 
-// CreateCommitStatusTask creates a task to add commit status
-func CreateCommitStatusTask(namespace string) *pipelinev1.Task {
+// The STATE is either pending, or the status of a pipeline task, which is one
+// of Succeeded, Failed or None.
+const commitStatusScript = gitHostScript + `
+states = {"Succeeded": "success", "Failed": "failure", "None": "error"}
+state = states.get("$(params.STATE)", "$(params.STATE)")
+repo = "$(params.REPO)"
+sha = "$(params.COMMIT_SHA)"
+description = os.environ["DESCRIPTION"]
+
+if driver == "gitlab":
+    gitlab_states = {"failure": "failed", "error": "canceled"}
+    path = "/projects/%s/statuses/%s" % (urllib.parse.quote(repo, safe=""), sha)
+    api_request(path, {"state": gitlab_states.get(state, state), "name": "$(params.CONTEXT)", "description": description})
+elif driver == "bitbucket":
+    bitbucket_states = {"pending": "INPROGRESS", "success": "SUCCESSFUL", "failure": "FAILED", "error": "STOPPED"}
+    path = "/repositories/%s/commit/%s/statuses/build" % (repo, sha)
+    api_request(path, {"state": bitbucket_states.get(state, state), "key": "$(params.CONTEXT)", "description": description, "url": "$(params.GIT_REPO)"})
+elif driver == "stash":
+    stash_states = {"pending": "INPROGRESS", "success": "SUCCESSFUL", "failure": "FAILED", "error": "FAILED"}
+    path = "/build-status/1.0/commits/%s" % sha
+    api_request(path, {"state": stash_states.get(state, state), "key": "$(params.CONTEXT)", "description": description, "url": "$(params.GIT_REPO)"})
+else:
+    path = "/repos/%s/statuses/%s" % (repo, sha)
+    api_request(path, {"state": state, "context": "$(params.CONTEXT)", "description": description})
+`
+
+// CommitStatusTaskName is the name of the task that sets the status of a
+// commit.
+const CommitStatusTaskName = "set-commit-status"
+
+// CreateCommitStatusTask creates a task to add commit status, with the API of
+// the host as the default.
+func CreateCommitStatusTask(namespace string, host GitHost) *pipelinev1.Task {
+	params := []pipelinev1.ParamSpec{
+		createTaskParam("GIT_REPO", "", pipelinev1.ParamTypeString),
+		createTaskParam("REPO", "", pipelinev1.ParamTypeString),
+		createTaskParamWithDefault("GIT_TOKEN_SECRET_NAME", "", pipelinev1.ParamTypeString, "git-host-access-token"),
+		createTaskParamWithDefault("GIT_TOKEN_SECRET_KEY", "", pipelinev1.ParamTypeString, "token"),
+		createTaskParam("COMMIT_SHA", "", pipelinev1.ParamTypeString),
+		createTaskParam("DESCRIPTION", "", pipelinev1.ParamTypeString),
+		createTaskParamWithDefault("CONTEXT", "", pipelinev1.ParamTypeString, "continous-integration/tekton"),
+		createTaskParam("STATE", "", pipelinev1.ParamTypeString),
+	}
 	return &pipelinev1.Task{
 		TypeMeta:   taskTypeMeta,
-		ObjectMeta: meta.ObjectMeta(types.NamespacedName{Name: "set-commit-status", Namespace: namespace}),
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(namespace, CommitStatusTaskName)),
 		Spec: pipelinev1.TaskSpec{
-			Params: []v1beta1.ParamSpec{
-				createTaskParam("GIT_REPO", "", pipelinev1.ParamTypeString),
-				createTaskParam("REPO", "", pipelinev1.ParamTypeString),
-				createTaskParamWithDefault("GIT_TOKEN_SECRET_NAME", "", pipelinev1.ParamTypeString, "git-host-access-token"),
-				createTaskParamWithDefault("GIT_TOKEN_SECRET_KEY", "", pipelinev1.ParamTypeString, "token"),
-				createTaskParam("COMMIT_SHA", "", pipelinev1.ParamTypeString),
-				createTaskParam("DESCRIPTION", "", pipelinev1.ParamTypeString),
-				createTaskParamWithDefault("CONTEXT", "", pipelinev1.ParamTypeString, "continous-integration/tekton"),
-				createTaskParam("STATE", "", pipelinev1.ParamTypeString),
-			},
-			Steps: []v1beta1.Step{
+			Params: append(params, gitHostParams(host)...),
+			Steps: []pipelinev1.Step{
 				{
-					Container: v1.Container{
+					Container: corev1.Container{
 						Name:  "set-commit-status",
-						Image: "quay.io/redhat-developer/gitops-commit-status@sha256:b28e5d207f70fc37f5cbf79fabcfa2708459316d09e9def9647c06807d9464cd",
-						Env: []v1.EnvVar{
-							{
-								Name: "GITHOSTACCESSTOKEN",
-								ValueFrom: &v1.EnvVarSource{
-									SecretKeyRef: &v1.SecretKeySelector{
-										LocalObjectReference: v1.LocalObjectReference{
-											Name: "$(params.GIT_TOKEN_SECRET_NAME)",
-										},
-										Key: "$(params.GIT_TOKEN_SECRET_KEY)",
-									},
-								},
-							},
+						Image: pythonImage,
+						Env: []corev1.EnvVar{
+							{Name: "DESCRIPTION", Value: "$(params.DESCRIPTION)"},
+							gitHostTokenEnv(false),
 						},
+						VolumeMounts: gitHostVolumeMounts(),
 					},
-					Script: "gitops-commit-status --url $(params.GIT_REPO) --path $(params.REPO) --sha $(params.COMMIT_SHA) --context $(params.CONTEXT) --status $(params.STATE)",
+					Script: commitStatusScript,
 				},
 			},
+			Volumes: gitHostVolumes(),
 		},
 	}
 }
@@ -11,15 +11,6 @@ var (
 	taskTypeMeta = meta.TypeMeta("Task", "tekton.dev/v1beta1")
 )
 
-func createTaskResource(name, resourceType string) pipelinev1.TaskResource {
-	return pipelinev1.TaskResource{
-		ResourceDeclaration: pipelinev1.ResourceDeclaration{
-			Name: name,
-			Type: resourceType,
-		},
-	}
-}
-
 func createTaskParam(name, description string, paramType pipelinev1.ParamType) pipelinev1.ParamSpec {
 	return pipelinev1.ParamSpec{
 		Name:        name,
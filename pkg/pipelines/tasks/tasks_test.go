@@ -1,6 +1,14 @@
 package tasks
 
 import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -19,14 +27,16 @@ func TestDeployFromSourceTask(t *testing.T) {
 			Namespace: testNS,
 		},
 		Spec: pipelinev1.TaskSpec{
-			Params:    paramsForDeploymentFromSourceTask(),
-			Resources: createResourcesForDeployFromSourceTask(),
+			Params: paramsForDeploymentFromSourceTask(),
+			Workspaces: []pipelinev1.WorkspaceDeclaration{
+				{Name: "source", Description: "The cloned GitOps repository."},
+			},
 			Steps: []pipelinev1.Step{
 				{
 					Container: corev1.Container{
 						Name:       "run-kubectl",
 						Image:      "quay.io/redhat-developer/k8s-kubectl",
-						WorkingDir: "/workspace/source",
+						WorkingDir: "$(workspaces.source.path)",
 					},
 					Script: "test",
 				},
@@ -39,6 +49,333 @@ func TestDeployFromSourceTask(t *testing.T) {
 	}
 }
 
+func TestCreateBuildImageTask(t *testing.T) {
+	task := CreateBuildImageTask(testNS)
+
+	if task.Name != BuildImageTaskName || task.Namespace != testNS {
+		t.Fatalf("got task %s/%s, want %s/%s", task.Namespace, task.Name, testNS, BuildImageTaskName)
+	}
+	wantWorkspaces := []pipelinev1.WorkspaceDeclaration{{Name: "source"}}
+	if diff := cmp.Diff(wantWorkspaces, task.Spec.Workspaces); diff != "" {
+		t.Fatalf("workspaces failed:\n%s", diff)
+	}
+	wantArgs := []string{
+		"--tls-verify=$(params.TLSVERIFY)",
+		"--layers",
+		"$(params.BUILD_EXTRA_ARGS)",
+		"-f", "$(params.DOCKERFILE)",
+		"-t", "$(params.IMAGE)",
+		"$(params.CONTEXT)",
+	}
+	step := task.Spec.Steps[0]
+	if diff := cmp.Diff([]string{"buildah", "bud"}, step.Command); diff != "" {
+		t.Fatalf("command failed:\n%s", diff)
+	}
+	if diff := cmp.Diff(wantArgs, step.Args); diff != "" {
+		t.Fatalf("args failed:\n%s", diff)
+	}
+}
+
+func TestCreateBuildpacksTask(t *testing.T) {
+	task := CreateBuildpacksTask(testNS)
+
+	if task.Name != BuildpacksTaskName || task.Namespace != testNS {
+		t.Fatalf("got task %s/%s, want %s/%s", task.Namespace, task.Name, testNS, BuildpacksTaskName)
+	}
+	wantWorkspaces := []pipelinev1.WorkspaceDeclaration{{Name: "source"}}
+	if diff := cmp.Diff(wantWorkspaces, task.Spec.Workspaces); diff != "" {
+		t.Fatalf("workspaces failed:\n%s", diff)
+	}
+	step := task.Spec.Steps[len(task.Spec.Steps)-1]
+	if step.Image != "$(params.BUILDER_IMAGE)" {
+		t.Fatalf("got image %q, want the builder image", step.Image)
+	}
+	wantArgs := []string{
+		"-app=$(workspaces.source.path)/$(params.SOURCE_SUBPATH)",
+		"-cache-dir=/cache",
+		"-cache-image=$(params.CACHE_IMAGE)",
+		"-uid=1000",
+		"-gid=1000",
+		"-layers=/layers",
+		"-platform=/platform",
+		"-process-type=$(params.PROCESS_TYPE)",
+		"-previous-image=$(params.APP_IMAGE)",
+		"-run-image=$(params.RUN_IMAGE)",
+		"$(params.APP_IMAGE)",
+	}
+	if diff := cmp.Diff(wantArgs, step.Args); diff != "" {
+		t.Fatalf("args failed:\n%s", diff)
+	}
+}
+
+func TestCreateUpdateImageTask(t *testing.T) {
+	task := CreateUpdateImageTask(testNS, GitHost{Driver: "github", APIURL: "https://api.github.com"})
+
+	if task.Name != UpdateImageTaskName || task.Namespace != testNS {
+		t.Fatalf("got task %s/%s, want %s/%s", task.Namespace, task.Name, testNS, UpdateImageTaskName)
+	}
+	steps := []string{}
+	for _, s := range task.Spec.Steps {
+		steps = append(steps, s.Name)
+	}
+	if diff := cmp.Diff([]string{"clone", "set-image", "push", "open-pull-request"}, steps); diff != "" {
+		t.Fatalf("steps failed:\n%s", diff)
+	}
+}
+
+func TestCreateCommitStatusTask(t *testing.T) {
+	host := GitHost{Driver: "gitlab", APIURL: "https://gitlab.example.com/api/v4"}
+	task := CreateCommitStatusTask(testNS, host)
+
+	defaults := map[string]string{}
+	for _, p := range task.Spec.Params {
+		if p.Default != nil {
+			defaults[p.Name] = p.Default.StringVal
+		}
+	}
+	want := map[string]string{
+		"GIT_TOKEN_SECRET_NAME": "git-host-access-token",
+		"GIT_TOKEN_SECRET_KEY":  "token",
+		"CONTEXT":               "continous-integration/tekton",
+		"GIT_DRIVER":            "gitlab",
+		"GIT_API_URL":           "https://gitlab.example.com/api/v4",
+		"GIT_CA_CONFIGMAP":      GitHostCAConfigMap,
+	}
+	if diff := cmp.Diff(want, defaults); diff != "" {
+		t.Fatalf("param defaults failed:\n%s", diff)
+	}
+	if diff := cmp.Diff(gitHostVolumes(), task.Spec.Volumes); diff != "" {
+		t.Fatalf("volumes failed:\n%s", diff)
+	}
+	if diff := cmp.Diff(gitHostVolumeMounts(), task.Spec.Steps[0].VolumeMounts); diff != "" {
+		t.Fatalf("volume mounts failed:\n%s", diff)
+	}
+}
+
+func TestGitHostTasksUseTheGitHostParams(t *testing.T) {
+	host := GitHost{Driver: "github", APIURL: "https://github.example.com/api/v3"}
+	for _, task := range []*pipelinev1.Task{CreateCommitStatusTask(testNS, host), CreatePRCommentTask(testNS, host), CreateUpdateImageTask(testNS, host)} {
+		names := map[string]bool{}
+		for _, p := range task.Spec.Params {
+			names[p.Name] = true
+		}
+		for _, n := range []string{"GIT_DRIVER", "GIT_API_URL", "GIT_CA_CONFIGMAP"} {
+			if !names[n] {
+				t.Errorf("task %s is missing param %s", task.Name, n)
+			}
+		}
+		if len(task.Spec.Volumes) != 1 || task.Spec.Volumes[0].ConfigMap.Name != "$(params.GIT_CA_CONFIGMAP)" {
+			t.Errorf("task %s does not mount the CA ConfigMap: %#v", task.Name, task.Spec.Volumes)
+		}
+	}
+}
+
+// The script is run against a local server that stands in for the
+// notification endpoint.
+func TestCreateNotificationTask(t *testing.T) {
+	python, err := exec.LookPath("python3")
+	if err != nil {
+		t.Skip("python3 is not available")
+	}
+	received := make(chan map[string]interface{}, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("got Content-Type %q, want application/json", ct)
+		}
+		msg := map[string]interface{}{}
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			t.Errorf("failed to decode the message: %s", err)
+		}
+		received <- msg
+	}))
+	defer ts.Close()
+
+	task := CreateNotificationTask(testNS)
+	params := map[string]string{
+		"SERVICE":                "taxi",
+		"COMMIT_SHA":             "6dcb09b5b57875f334f61aebed695e2e4193db5e",
+		"COMMIT_AUTHOR":          "Jane O'Brien",
+		"STATUS":                 "Failed",
+		"PIPELINE_RUN":           "app-ci-pipeline-run-abcde",
+		"PIPELINE_RUN_NAMESPACE": "cicd",
+		"PIPELINE_RUN_URL":       "",
+	}
+	env := []string{"PATH=" + os.Getenv("PATH"), "NOTIFICATION_URL=" + ts.URL}
+	for _, e := range task.Spec.Steps[0].Env {
+		if e.ValueFrom != nil {
+			continue
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(e.Value, "$(params."), ")")
+		env = append(env, e.Name+"="+params[name])
+	}
+	dir, err := ioutil.TempDir("", "notification")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	script := filepath.Join(dir, "notify.py")
+	if err := ioutil.WriteFile(script, []byte(task.Spec.Steps[0].Script), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(python, script)
+	cmd.Env = env
+	if out, err := cmd.CombinedOutput(); err != nil || len(out) > 0 {
+		t.Fatalf("script failed: %v\n%s", err, out)
+	}
+
+	want := map[string]interface{}{
+		"service": "taxi",
+		"commit":  "6dcb09b5b57875f334f61aebed695e2e4193db5e",
+		"author":  "Jane O'Brien",
+		"status":  "Failed",
+		"pipelineRun": map[string]interface{}{
+			"name":      "app-ci-pipeline-run-abcde",
+			"namespace": "cicd",
+			"url":       "",
+		},
+		"text": "taxi: pipeline failed for 6dcb09b by Jane O'Brien (app-ci-pipeline-run-abcde)",
+	}
+	if diff := cmp.Diff(want, <-received); diff != "" {
+		t.Fatalf("notification failed:\n%s", diff)
+	}
+}
+
+// The script is run against a local server that stands in for the Bitbucket
+// API, with the params substituted as Tekton would.
+func TestCreateCommitStatusTaskForBitbucket(t *testing.T) {
+	python, err := exec.LookPath("python3")
+	if err != nil {
+		t.Skip("python3 is not available")
+	}
+	type request struct {
+		path, auth string
+		body       map[string]interface{}
+	}
+	received := make(chan request, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]interface{}{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failed to decode the body: %s", err)
+		}
+		received <- request{path: r.URL.Path, auth: r.Header.Get("Authorization"), body: body}
+	}))
+	defer ts.Close()
+
+	task := CreateCommitStatusTask(testNS, GitHost{Driver: "bitbucket", APIURL: ts.URL + "/2.0"})
+	params := map[string]string{
+		"GIT_REPO":    "https://bitbucket.org/example/taxi",
+		"REPO":        "example/taxi",
+		"COMMIT_SHA":  "6dcb09b5b57875f334f61aebed695e2e4193db5e",
+		"STATE":       "Failed",
+		"CONTEXT":     "continous-integration/tekton",
+		"GIT_DRIVER":  "bitbucket",
+		"GIT_API_URL": ts.URL + "/2.0",
+	}
+	script := task.Spec.Steps[0].Script
+	for k, v := range params {
+		script = strings.ReplaceAll(script, "$(params."+k+")", v)
+	}
+	dir, err := ioutil.TempDir("", "status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "status.py")
+	if err := ioutil.WriteFile(path, []byte(script), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(python, path)
+	cmd.Env = []string{"PATH=" + os.Getenv("PATH"), "DESCRIPTION=The build failed", "GITHOSTACCESSTOKEN=secret-token"}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("script failed: %v\n%s", err, out)
+	}
+
+	want := request{
+		path: "/2.0/repositories/example/taxi/commit/6dcb09b5b57875f334f61aebed695e2e4193db5e/statuses/build",
+		auth: "Bearer secret-token",
+		body: map[string]interface{}{
+			"state":       "FAILED",
+			"key":         "continous-integration/tekton",
+			"description": "The build failed",
+			"url":         "https://bitbucket.org/example/taxi",
+		},
+	}
+	if diff := cmp.Diff(want, <-received, cmp.AllowUnexported(request{})); diff != "" {
+		t.Fatalf("commit status failed:\n%s", diff)
+	}
+}
+
+// Bitbucket Server pull requests are opened with the project key and slug of
+// the repository, from the path of the clone URL after the /scm prefix.
+func TestCreateUpdateImageTaskForStash(t *testing.T) {
+	python, err := exec.LookPath("python3")
+	if err != nil {
+		t.Skip("python3 is not available")
+	}
+	type request struct {
+		path, auth string
+		body       map[string]interface{}
+	}
+	received := make(chan request, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]interface{}{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failed to decode the body: %s", err)
+		}
+		received <- request{path: r.URL.Path, auth: r.Header.Get("Authorization"), body: body}
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "update-image")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	branchPath := filepath.Join(dir, "pr-branch")
+	if err := ioutil.WriteFile(branchPath, []byte("kam-update-image-abc123"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	task := CreateUpdateImageTask(testNS, GitHost{Driver: "stash", APIURL: ts.URL + "/bitbucket/rest"})
+	params := map[string]string{
+		"GIT_DRIVER":  "stash",
+		"GIT_API_URL": ts.URL + "/bitbucket/rest",
+	}
+	steps := task.Spec.Steps
+	script := strings.ReplaceAll(steps[len(steps)-1].Script, "/workspace/pr-branch", branchPath)
+	for k, v := range params {
+		script = strings.ReplaceAll(script, "$(params."+k+")", v)
+	}
+	path := filepath.Join(dir, "open-pr.py")
+	if err := ioutil.WriteFile(path, []byte(script), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(python, path)
+	cmd.Env = []string{
+		"PATH=" + os.Getenv("PATH"),
+		"GITHOSTACCESSTOKEN=secret-token",
+		"GITOPS_REPO=https://example.com/bitbucket/scm/proj/gitops.git",
+		"GITOPS_BRANCH=main",
+		`IMAGE=quay.io/example/taxi:fix-"quotes"`,
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("script failed: %v\n%s", err, out)
+	}
+
+	want := request{
+		path: "/bitbucket/rest/api/1.0/projects/proj/repos/gitops/pull-requests",
+		auth: "Bearer secret-token",
+		body: map[string]interface{}{
+			"fromRef": map[string]interface{}{"id": "refs/heads/kam-update-image-abc123"},
+			"toRef":   map[string]interface{}{"id": "refs/heads/main"},
+			"title":   `Update image to quay.io/example/taxi:fix-"quotes"`,
+		},
+	}
+	if diff := cmp.Diff(want, <-received, cmp.AllowUnexported(request{})); diff != "" {
+		t.Fatalf("open pull request failed:\n%s", diff)
+	}
+}
+
 func TestCreateTaskParamWithDefault(t *testing.T) {
 	validTaskParam := pipelinev1.ParamSpec{
 		Name:        "sample",
@@ -80,16 +417,3 @@ func TestCreateContainer(t *testing.T) {
 		t.Fatalf("createContainer() failed:\n%s", diff)
 	}
 }
-
-func TestCreateTaskResource(t *testing.T) {
-	validTaskResource := pipelinev1.TaskResource{
-		ResourceDeclaration: pipelinev1.ResourceDeclaration{
-			Name: "sample",
-			Type: "git",
-		},
-	}
-	taskResource := createTaskResource("sample", "git")
-	if diff := cmp.Diff(validTaskResource, taskResource); diff != "" {
-		t.Fatalf("createTaskResource() failed:\n%s", diff)
-	}
-}
@@ -0,0 +1,151 @@
+package tasks
+
+import (
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+)
+
+const (
+	// UpdateImageTaskName is the name of the task that updates the image of a
+	// service in the GitOps repository.
+	UpdateImageTaskName = "update-gitops-image"
+
+	// UpdateModePush pushes the updated image directly to the branch of the
+	// GitOps repository.
+	UpdateModePush = "push"
+
+	// UpdateModePullRequest pushes the updated image to a new branch, and
+	// opens a pull request against the branch of the GitOps repository.
+	UpdateModePullRequest = "pull-request"
+
+	gitImage       = "alpine/git:v2.26.2"
+	kustomizeImage = "k8s.gcr.io/kustomize/kustomize:v3.8.7"
+	gitOpsCheckout = "/workspace/gitops"
+)
+
+// The params are read from the environment rather than substituted into the
+// scripts, as the image is tagged with the branch name, which may contain
+// quotes.
+const cloneGitOpsScript = `#!/bin/sh
+set -e
+git clone --depth 1 --branch "${GITOPS_BRANCH}" "${GITOPS_REPO}" ` + gitOpsCheckout + `
+`
+
+// The image is matched by name, without the tag, so the deployments in the
+// service's base must reference the image repository.
+const setImageScript = `#!/bin/sh
+set -e
+image="${IMAGE}"
+cd "${OVERLAY_PATH}"
+kustomize edit set image "${image%:*}=${image}"
+`
+
+const pushImageScript = `#!/bin/sh
+set -e
+git config user.name "kam"
+git config user.email "kam@users.noreply.github.com"
+git add "${OVERLAY_PATH}"
+if git diff --cached --quiet; then
+  echo "The image is already up to date"
+  exit 0
+fi
+git commit -m "Update image to ${IMAGE}"
+if [ "$(params.UPDATE_MODE)" = "` + UpdateModePullRequest + `" ]; then
+  branch="kam-update-image-$(git rev-parse --short HEAD)"
+  git push origin "HEAD:refs/heads/${branch}"
+  printf "%s" "${branch}" > /workspace/pr-branch
+else
+  git push origin "HEAD:refs/heads/${GITOPS_BRANCH}"
+fi
+`
+
+const openPRScript = gitHostScript + `
+import sys
+
+if not os.path.exists("/workspace/pr-branch"):
+    sys.exit(0)
+with open("/workspace/pr-branch") as f:
+    branch = f.read()
+base = os.environ["GITOPS_BRANCH"]
+
+repo = urllib.parse.urlparse(os.environ["GITOPS_REPO"]).path.strip("/")
+if repo.endswith(".git"):
+    repo = repo[:-len(".git")]
+if "/scm/" in "/" + repo:
+    repo = ("/" + repo).rsplit("/scm/", 1)[1]
+title = "Update image to " + os.environ["IMAGE"]
+
+if driver == "gitlab":
+    path = "/projects/%s/merge_requests" % urllib.parse.quote(repo, safe="")
+    body = {"source_branch": branch, "target_branch": base, "title": title}
+elif driver == "bitbucket":
+    path = "/repositories/%s/pullrequests" % repo
+    body = {"source": {"branch": {"name": branch}}, "destination": {"branch": {"name": base}}, "title": title}
+elif driver == "stash":
+    project, slug = repo.split("/")
+    path = "/api/1.0/projects/%s/repos/%s/pull-requests" % (project, slug)
+    body = {"fromRef": {"id": "refs/heads/" + branch}, "toRef": {"id": "refs/heads/" + base}, "title": title}
+else:
+    path = "/repos/%s/pulls" % repo
+    body = {"head": branch, "base": base, "title": title}
+api_request(path, body)
+`
+
+// CreateUpdateImageTask creates a Task that updates the image of a service in
+// the GitOps repository, with a kustomize images entry in the service's
+// overlay, and either pushes the change, or opens a pull request with it, with
+// the API of the host as the default.
+func CreateUpdateImageTask(ns string, host GitHost) *pipelinev1.Task {
+	params := []pipelinev1.ParamSpec{
+		createTaskParam("GITOPS_REPO", "The clone URL of the GitOps repository.", pipelinev1.ParamTypeString),
+		createTaskParamWithDefault("GITOPS_BRANCH", "The branch of the GitOps repository to update.", pipelinev1.ParamTypeString, "main"),
+		createTaskParam("OVERLAY_PATH", "The path to the service's overlay in the GitOps repository.", pipelinev1.ParamTypeString),
+		createTaskParam("IMAGE", "The image to deploy.", pipelinev1.ParamTypeString),
+		createTaskParamWithDefault("UPDATE_MODE", "Either push the change to the branch, or open a pull-request with it.", pipelinev1.ParamTypeString, UpdateModePush),
+		createTaskParamWithDefault("GIT_TOKEN_SECRET_NAME", "", pipelinev1.ParamTypeString, "git-host-access-token"),
+		createTaskParamWithDefault("GIT_TOKEN_SECRET_KEY", "", pipelinev1.ParamTypeString, "token"),
+	}
+	return &pipelinev1.Task{
+		TypeMeta:   taskTypeMeta,
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(ns, UpdateImageTaskName)),
+		Spec: pipelinev1.TaskSpec{
+			Params: append(params, gitHostParams(host)...),
+			Steps: []pipelinev1.Step{
+				{
+					Container: corev1.Container{Name: "clone", Image: gitImage, Env: updateImageEnv()},
+					Script:    cloneGitOpsScript,
+				},
+				{
+					Container: corev1.Container{Name: "set-image", Image: kustomizeImage, WorkingDir: gitOpsCheckout, Env: updateImageEnv()},
+					Script:    setImageScript,
+				},
+				{
+					Container: corev1.Container{Name: "push", Image: gitImage, WorkingDir: gitOpsCheckout, Env: updateImageEnv()},
+					Script:    pushImageScript,
+				},
+				{
+					Container: corev1.Container{
+						Name:  "open-pull-request",
+						Image: pythonImage,
+						// The access token is only needed to open pull requests.
+						Env:          append(updateImageEnv(), gitHostTokenEnv(true)),
+						VolumeMounts: gitHostVolumeMounts(),
+					},
+					Script: openPRScript,
+				},
+			},
+			Volumes: gitHostVolumes(),
+		},
+	}
+}
+
+func updateImageEnv() []corev1.EnvVar {
+	return []corev1.EnvVar{
+		{Name: "GITOPS_REPO", Value: "$(params.GITOPS_REPO)"},
+		{Name: "GITOPS_BRANCH", Value: "$(params.GITOPS_BRANCH)"},
+		{Name: "OVERLAY_PATH", Value: "$(params.OVERLAY_PATH)"},
+		{Name: "IMAGE", Value: "$(params.IMAGE)"},
+	}
+}
@@ -6,14 +6,20 @@ import (
 
 	"github.com/redhat-developer/kam/pkg/pipelines/config"
 	"github.com/redhat-developer/kam/pkg/pipelines/eventlisteners"
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+	"github.com/redhat-developer/kam/pkg/pipelines/pipelines"
 	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
 	"github.com/redhat-developer/kam/pkg/pipelines/scm"
+	"github.com/redhat-developer/kam/pkg/pipelines/tasks"
+	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
 )
 
 type tektonBuilder struct {
 	files      res.Resources
 	gitOpsRepo string
+	manifest   *config.Manifest
 	triggers   []v1alpha1.EventListenerTrigger
 }
 
@@ -26,12 +32,18 @@ func buildEventListenerResources(gitOpsRepo string, m *config.Manifest) (res.Res
 		return nil, nil
 	}
 	files := make(res.Resources)
-	tb := &tektonBuilder{files: files, gitOpsRepo: gitOpsRepo}
+	tb := &tektonBuilder{files: files, gitOpsRepo: gitOpsRepo, manifest: m}
 	triggers, err := createTriggersForCICD(tb.gitOpsRepo, cfg)
 	if err != nil {
 		return nil, err
 	}
 	tb.triggers = append(tb.triggers, triggers...)
+	if err := tb.buildGitHostTasks(); err != nil {
+		return nil, err
+	}
+	if err := tb.buildNotificationResources(); err != nil {
+		return nil, err
+	}
 	err = m.Walk(tb)
 	if err != nil {
 		return nil, err
@@ -49,15 +61,175 @@ func (tb *tektonBuilder) Service(app *config.Application, env *config.Environmen
 	if err != nil {
 		return err
 	}
-	pipelines := getPipelines(env, svc, repo)
-	ciTrigger, err := repo.CreatePushTrigger(triggerName(svc.Name), svc.Webhook.Secret.Name, svc.Webhook.Secret.Namespace, pipelines.Integration.Template, pipelines.Integration.Bindings)
+	svcPipelines := getPipelines(env, svc, repo)
+	test := svc.Test
+	if test == nil {
+		test = env.Test
+	}
+	host, err := tb.repositoryGitHost(svc.SourceURL)
+	if err != nil {
+		return err
+	}
+	gitOpsHost, err := tb.repositoryGitHost(tb.gitOpsRepo)
+	if err != nil {
+		return err
+	}
+	// The shared pipelines report the commit status to the GitOps repository's
+	// host, so services on other hosts have their own pipelines.
+	if svc.Build != nil || test != nil || svc.ContextDir != "" || host != gitOpsHost {
+		template, prTemplate, err := tb.buildServiceCIResources(svc, test, host)
+		if err != nil {
+			return err
+		}
+		if svc.Pipelines == nil || svc.Pipelines.Integration == nil || svc.Pipelines.Integration.Template == "" {
+			svcPipelines.Integration.Template = template
+		}
+		if svcPipelines.PullRequest != nil && (svc.Pipelines == nil || svc.Pipelines.PullRequest == nil || svc.Pipelines.PullRequest.Template == "") {
+			svcPipelines.PullRequest.Template = prTemplate
+		}
+	}
+	filter := scm.PushFilter{Branches: svcPipelines.Branches, Tags: svcPipelines.Tags, ContextDir: svc.ContextDir}
+	ciTrigger, err := repo.CreateFilteredPushTrigger(triggers.ServicePushTriggerName(svc.Name), svc.Webhook.Secret.Name, svc.Webhook.Secret.Namespace, svcPipelines.Integration.Template, svcPipelines.Integration.Bindings, filter)
 	if err != nil {
 		return err
 	}
 	tb.triggers = append(tb.triggers, ciTrigger)
+	if svcPipelines.PullRequest != nil {
+		prTrigger, err := repo.CreatePRTrigger(triggers.ServicePRTriggerName(svc.Name), svc.Webhook.Secret.Name, svc.Webhook.Secret.Namespace, svcPipelines.PullRequest.Template, svcPipelines.PullRequest.Bindings)
+		if err != nil {
+			return err
+		}
+		tb.triggers = append(tb.triggers, prTrigger)
+	}
+	return nil
+}
+
+// buildServiceCIResources generates the integration and pull request
+// Pipelines and TriggerTemplates for a service that configures how its image
+// is built or tested, that is in a directory of its source repository, or
+// whose repository is on another host than the GitOps repository, and returns
+// the names of the TriggerTemplates.
+func (tb *tektonBuilder) buildServiceCIResources(svc *config.Service, test *config.Test, host tasks.GitHost) (string, string, error) {
+	cfg := tb.manifest.GetPipelinesConfig()
+	driver, err := scm.GetDriverName(tb.gitOpsRepo)
+	if err != nil {
+		return "", "", err
+	}
+	gitOpsCloneURL, err := scm.HTTPCloneURL(tb.gitOpsRepo, driver)
+	if err != nil {
+		return "", "", err
+	}
+	pipelineName := fmt.Sprintf("app-ci-pipeline-%s", svc.Name)
+	templateName := fmt.Sprintf("app-ci-template-%s", svc.Name)
+	prPipelineName := fmt.Sprintf("app-ci-pr-pipeline-%s", svc.Name)
+	prTemplateName := fmt.Sprintf("app-ci-pr-template-%s", svc.Name)
+	imageUpdate := &pipelines.ImageUpdate{GitOpsRepoURL: gitOpsCloneURL, Driver: driver, PullRequest: cfg.ImageUpdatePullRequest}
+	pipeline := pipelines.CreateAppCIPipeline(meta.NamespacedName(cfg.Name, pipelineName), imageUpdate, svc.Build, test, svc.ContextDir)
+	pipelines.AddGitHost(pipeline, host)
+	pipelines.AddNotifications(pipeline, svc.Name, cfg)
+	basePath := filepath.Join(config.PathForPipelines(cfg), "base")
+	if svc.Build.GetStrategy() == config.BuildStrategyBuildpacks {
+		tb.files[filepath.ToSlash(filepath.Join(basePath, buildpacksTaskPath))] = tasks.CreateBuildpacksTask(cfg.Name)
+	}
+	tb.files[filepath.ToSlash(filepath.Join(basePath, "04-pipelines", pipelineName+".yaml"))] = pipeline
+	tb.files[filepath.ToSlash(filepath.Join(basePath, "06-templates", templateName+".yaml"))] = triggers.CreateServiceCITemplate(cfg.Name, saName, templateName, pipelineName)
+	prPipeline := pipelines.CreateServiceCIPRPipeline(meta.NamespacedName(cfg.Name, prPipelineName), svc.Build, test, svc.ContextDir)
+	pipelines.AddGitHost(prPipeline, host)
+	tb.files[filepath.ToSlash(filepath.Join(basePath, "04-pipelines", prPipelineName+".yaml"))] = prPipeline
+	tb.files[filepath.ToSlash(filepath.Join(basePath, "06-templates", prTemplateName+".yaml"))] = triggers.CreateServiceCIPRTemplate(cfg.Name, saName, prTemplateName, prPipelineName)
+	return templateName, prTemplateName, nil
+}
+
+// buildGitHostTasks generates the tasks that call the API of the GitOps
+// repository's host, if a driver is configured for the host, so that the
+// tasks use the API of a self-hosted server.
+func (tb *tektonBuilder) buildGitHostTasks() error {
+	driver, err := tb.privateDriver(tb.gitOpsRepo)
+	if err != nil || driver == "" {
+		return err
+	}
+	host, err := gitHost(tb.gitOpsRepo, driver)
+	if err != nil {
+		return err
+	}
+	cfg := tb.manifest.GetPipelinesConfig()
+	basePath := filepath.Join(config.PathForPipelines(cfg), "base")
+	tb.files[filepath.ToSlash(filepath.Join(basePath, commitStatusTaskPath))] = tasks.CreateCommitStatusTask(cfg.Name, host)
+	tb.files[filepath.ToSlash(filepath.Join(basePath, prCommentTaskPath))] = tasks.CreatePRCommentTask(cfg.Name, host)
+	tb.files[filepath.ToSlash(filepath.Join(basePath, updateImageTaskPath))] = tasks.CreateUpdateImageTask(cfg.Name, host)
+	return nil
+}
+
+// buildNotificationResources regenerates the shared integration pipeline and
+// the dry-run pipelines of the GitOps repository with the notifications, if
+// any are configured, and the TriggerTemplates of the dry-run pipelines, so
+// that they provide the commit author.
+func (tb *tektonBuilder) buildNotificationResources() error {
+	cfg := tb.manifest.GetPipelinesConfig()
+	if len(cfg.Notifications) == 0 {
+		return nil
+	}
+	driver, err := scm.GetDriverName(tb.gitOpsRepo)
+	if err != nil {
+		return err
+	}
+	gitOpsCloneURL, err := scm.HTTPCloneURL(tb.gitOpsRepo, driver)
+	if err != nil {
+		return err
+	}
+	imageUpdate := &pipelines.ImageUpdate{GitOpsRepoURL: gitOpsCloneURL, Driver: driver, PullRequest: cfg.ImageUpdatePullRequest}
+	generated := map[string]*pipelinev1.Pipeline{
+		appCiPipelinesPath: pipelines.CreateAppCIPipeline(meta.NamespacedName(cfg.Name, "app-ci-pipeline"), imageUpdate, nil, nil, ""),
+		ciPipelinesPath:    pipelines.CreateCIPipeline(meta.NamespacedName(cfg.Name, "ci-dryrun-from-push-pipeline"), cfg.Name),
+		ciPRPipelinesPath:  pipelines.CreateCIPRPipeline(meta.NamespacedName(cfg.Name, "ci-dryrun-from-pr-pipeline"), driver),
+	}
+	basePath := filepath.Join(config.PathForPipelines(cfg), "base")
+	// The shared integration pipeline has the service name from the service's
+	// binding, the dry-run pipelines are for the GitOps repository.
+	services := map[string]string{appCiPipelinesPath: "$(params." + pipelines.ServiceParam + ")"}
+	for path, p := range generated {
+		service, ok := services[path]
+		if !ok {
+			service = "$(params.REPO)"
+		}
+		pipelines.AddNotifications(p, service, cfg)
+		tb.files[filepath.ToSlash(filepath.Join(basePath, path))] = p
+	}
+	tb.files[filepath.ToSlash(filepath.Join(basePath, pushTemplatePath))] = triggers.CreateCIDryRunTemplate(cfg.Name, saName)
+	tb.files[filepath.ToSlash(filepath.Join(basePath, prTemplatePath))] = triggers.CreateCIDryRunPRTemplate(cfg.Name, saName)
+	tb.files[filepath.ToSlash(filepath.Join(basePath, notificationTaskPath))] = tasks.CreateNotificationTask(cfg.Name)
 	return nil
 }
 
+// privateDriver returns the driver configured for the repository's host, if
+// any.
+func (tb *tektonBuilder) privateDriver(repoURL string) (string, error) {
+	if tb.manifest.Config == nil || tb.manifest.Config.Git == nil {
+		return "", nil
+	}
+	host, err := scm.HostnameFromURL(repoURL)
+	if err != nil {
+		return "", err
+	}
+	return tb.manifest.Config.Git.Drivers[host], nil
+}
+
+// repositoryGitHost returns the driver and the API of the repository's host,
+// the driver configured for the host takes precedence.
+func (tb *tektonBuilder) repositoryGitHost(repoURL string) (tasks.GitHost, error) {
+	driver, err := tb.privateDriver(repoURL)
+	if err != nil {
+		return tasks.GitHost{}, err
+	}
+	if driver == "" {
+		driver, err = scm.GetDriverName(repoURL)
+		if err != nil {
+			return tasks.GitHost{}, err
+		}
+	}
+	return gitHost(repoURL, driver)
+}
+
 func getEventListenerPath(cicdPath string) string {
 	return filepath.ToSlash(filepath.Join(cicdPath, "base", eventListenerPath))
 }
@@ -73,34 +245,73 @@ func createTriggersForCICD(gitOpsRepo string, cfg *config.PipelinesConfig) ([]v1
 		return []v1alpha1.EventListenerTrigger{}, err
 	}
 	triggers = append(triggers, ciTrigger)
+	prTrigger, err := repo.CreatePRTrigger("ci-dryrun-from-pr", eventlisteners.GitOpsWebhookSecret, cfg.Name, "ci-dryrun-from-pr-template", []string{repo.PRBindingName()})
+	if err != nil {
+		return []v1alpha1.EventListenerTrigger{}, err
+	}
+	triggers = append(triggers, prTrigger)
 	return triggers, nil
 }
 
 func getPipelines(env *config.Environment, svc *config.Service, r scm.Repository) *config.Pipelines {
 	pipelines := defaultPipelines(r)
 	if env.Pipelines != nil {
+		defaults := pipelines
 		pipelines = clonePipelines(env.Pipelines)
+		// Only the branches and tags are configured for the environment.
+		if pipelines.Integration == nil {
+			pipelines.Integration = defaults.Integration
+			if pipelines.PullRequest == nil {
+				pipelines.PullRequest = defaults.PullRequest
+			}
+		}
 	}
 	if svc.Pipelines != nil {
-		if len(svc.Pipelines.Integration.Bindings) > 0 {
-			pipelines.Integration.Bindings = svc.Pipelines.Integration.Bindings
+		if svc.Pipelines.Integration != nil {
+			if len(svc.Pipelines.Integration.Bindings) > 0 {
+				pipelines.Integration.Bindings = svc.Pipelines.Integration.Bindings
+			}
+			if svc.Pipelines.Integration.Template != "" {
+				pipelines.Integration.Template = svc.Pipelines.Integration.Template
+			}
+		}
+		if len(svc.Pipelines.Branches) > 0 || len(svc.Pipelines.Tags) > 0 {
+			pipelines.Branches = svc.Pipelines.Branches
+			pipelines.Tags = svc.Pipelines.Tags
 		}
-		if svc.Pipelines.Integration.Template != "" {
-			pipelines.Integration.Template = svc.Pipelines.Integration.Template
+		if svc.Pipelines.PullRequest != nil {
+			// Environments written before the pull request pipelines only
+			// configure the integration pipeline.
+			if pipelines.PullRequest == nil {
+				pipelines.PullRequest = defaultPipelines(r).PullRequest
+			}
+			if len(svc.Pipelines.PullRequest.Bindings) > 0 {
+				pipelines.PullRequest.Bindings = svc.Pipelines.PullRequest.Bindings
+			}
+			if svc.Pipelines.PullRequest.Template != "" {
+				pipelines.PullRequest.Template = svc.Pipelines.PullRequest.Template
+			}
 		}
 	}
 	return pipelines
 }
 
 func clonePipelines(p *config.Pipelines) *config.Pipelines {
-	return &config.Pipelines{
-		Integration: &config.TemplateBinding{
+	cloned := &config.Pipelines{
+		Branches: p.Branches,
+		Tags:     p.Tags,
+	}
+	if p.Integration != nil {
+		cloned.Integration = &config.TemplateBinding{
 			Bindings: p.Integration.Bindings,
 			Template: p.Integration.Template,
-		},
+		}
 	}
-}
-
-func triggerName(svc string) string {
-	return fmt.Sprintf("app-ci-build-from-push-%s", svc)
+	if p.PullRequest != nil {
+		cloned.PullRequest = &config.TemplateBinding{
+			Bindings: p.PullRequest.Bindings,
+			Template: p.PullRequest.Template,
+		}
+	}
+	return cloned
 }
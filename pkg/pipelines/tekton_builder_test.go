@@ -6,10 +6,17 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/jenkins-x/go-scm/scm/factory"
 	"github.com/redhat-developer/kam/pkg/pipelines/config"
 	"github.com/redhat-developer/kam/pkg/pipelines/eventlisteners"
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+	"github.com/redhat-developer/kam/pkg/pipelines/pipelines"
 	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
 	"github.com/redhat-developer/kam/pkg/pipelines/scm"
+	"github.com/redhat-developer/kam/pkg/pipelines/tasks"
+	"github.com/redhat-developer/kam/pkg/pipelines/triggers"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
 )
 
@@ -63,6 +70,257 @@ func TestBuildEventListenerWithServiceWithNoURL(t *testing.T) {
 	}
 }
 
+func TestBuildEventListenerWithPullRequests(t *testing.T) {
+	env := testEnv(testService(), "dev")
+	env.Pipelines.PullRequest = &config.TemplateBinding{
+		Template: "test-pr-template",
+		Bindings: []string{"test-pr-binding"},
+	}
+	m := &config.Manifest{
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{
+				Name: "test-cicd",
+			},
+		},
+		Environments: []*config.Environment{env},
+		GitOpsURL:    testRepoName,
+	}
+	got, err := buildEventListenerResources(testRepoName, m)
+	assertNoError(t, err)
+
+	repo, err := scm.NewRepository(testService().SourceURL)
+	assertNoError(t, err)
+	prTrigger, err := repo.CreatePRTrigger("app-ci-build-from-pr-test-svc", "webhook-secret", "webhook-ns", "test-pr-template", []string{"test-pr-binding"})
+	assertNoError(t, err)
+	want := append(fakeTriggers(t, m, testRepoName), prTrigger)
+	cicdPath := filepath.ToSlash(filepath.Join("config", "test-cicd"))
+	if diff := cmp.Diff(eventlisteners.CreateELFromTriggers("test-cicd", saName, want), got[getEventListenerPath(cicdPath)]); diff != "" {
+		t.Fatalf("event listener didn't match:%s\n", diff)
+	}
+}
+
+func TestBuildEventListenerWithBuild(t *testing.T) {
+	svc := testService()
+	svc.Build = &config.Build{Strategy: config.BuildStrategyS2I, BuilderImage: "registry.access.redhat.com/ubi8/nodejs-14"}
+	m := &config.Manifest{
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{
+				Name:                   "test-cicd",
+				ImageUpdatePullRequest: true,
+			},
+		},
+		Environments: []*config.Environment{testEnv(svc, "dev")},
+		GitOpsURL:    testRepoName,
+	}
+	got, err := buildEventListenerResources(testRepoName, m)
+	assertNoError(t, err)
+
+	repo, err := scm.NewRepository(svc.SourceURL)
+	assertNoError(t, err)
+	cicdTriggers, err := createTriggersForCICD(testRepoName, m.GetPipelinesConfig())
+	assertNoError(t, err)
+	ciTrigger, err := repo.CreatePushTrigger("app-ci-build-from-push-test-svc", "webhook-secret", "webhook-ns", "app-ci-template-test-svc", []string{"test-ci-binding"})
+	assertNoError(t, err)
+	imageUpdate := &pipelines.ImageUpdate{GitOpsRepoURL: testRepoName, Driver: "github", PullRequest: true}
+	cicdPath := filepath.ToSlash(filepath.Join("config", "test-cicd"))
+	want := res.Resources{
+		"config/test-cicd/base/04-pipelines/app-ci-pipeline-test-svc.yaml":    withGitHost(pipelines.CreateAppCIPipeline(meta.NamespacedName("test-cicd", "app-ci-pipeline-test-svc"), imageUpdate, svc.Build, nil, "")),
+		"config/test-cicd/base/06-templates/app-ci-template-test-svc.yaml":    triggers.CreateServiceCITemplate("test-cicd", saName, "app-ci-template-test-svc", "app-ci-pipeline-test-svc"),
+		"config/test-cicd/base/04-pipelines/app-ci-pr-pipeline-test-svc.yaml": withGitHost(pipelines.CreateServiceCIPRPipeline(meta.NamespacedName("test-cicd", "app-ci-pr-pipeline-test-svc"), svc.Build, nil, "")),
+		"config/test-cicd/base/06-templates/app-ci-pr-template-test-svc.yaml": triggers.CreateServiceCIPRTemplate("test-cicd", saName, "app-ci-pr-template-test-svc", "app-ci-pr-pipeline-test-svc"),
+		getEventListenerPath(cicdPath):                                        eventlisteners.CreateELFromTriggers("test-cicd", saName, append(cicdTriggers, ciTrigger)),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("resources didn't match:%s\n", diff)
+	}
+}
+
+func TestBuildEventListenerWithBuildpacks(t *testing.T) {
+	svc := testService()
+	svc.Build = &config.Build{Strategy: config.BuildStrategyBuildpacks, BuilderImage: "paketobuildpacks/builder:base"}
+	m := &config.Manifest{
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{Name: "test-cicd"},
+		},
+		Environments: []*config.Environment{testEnv(svc, "dev")},
+		GitOpsURL:    testRepoName,
+	}
+	got, err := buildEventListenerResources(testRepoName, m)
+	assertNoError(t, err)
+
+	want := tasks.CreateBuildpacksTask("test-cicd")
+	if diff := cmp.Diff(want, got["config/test-cicd/base/03-tasks/buildpacks-task.yaml"]); diff != "" {
+		t.Fatalf("task didn't match:%s\n", diff)
+	}
+}
+
+func TestBuildEventListenerWithEnvironmentTest(t *testing.T) {
+	env := testEnv(testService(), "dev")
+	env.Test = &config.Test{Task: "go-test"}
+	m := &config.Manifest{
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{Name: "test-cicd"},
+		},
+		Environments: []*config.Environment{env},
+		GitOpsURL:    testRepoName,
+	}
+	got, err := buildEventListenerResources(testRepoName, m)
+	assertNoError(t, err)
+
+	imageUpdate := &pipelines.ImageUpdate{GitOpsRepoURL: testRepoName, Driver: "github"}
+	want := withGitHost(pipelines.CreateAppCIPipeline(meta.NamespacedName("test-cicd", "app-ci-pipeline-test-svc"), imageUpdate, nil, env.Test, ""))
+	if diff := cmp.Diff(want, got["config/test-cicd/base/04-pipelines/app-ci-pipeline-test-svc.yaml"]); diff != "" {
+		t.Fatalf("pipeline didn't match:%s\n", diff)
+	}
+}
+
+func TestBuildEventListenerWithContextDir(t *testing.T) {
+	svc := testService()
+	svc.ContextDir = "services/test-svc"
+	env := testEnv(svc, "dev")
+	env.Pipelines.PullRequest = &config.TemplateBinding{
+		Template: "test-pr-template",
+		Bindings: []string{"test-pr-binding"},
+	}
+	m := &config.Manifest{
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{Name: "test-cicd"},
+		},
+		Environments: []*config.Environment{env},
+		GitOpsURL:    testRepoName,
+	}
+	got, err := buildEventListenerResources(testRepoName, m)
+	assertNoError(t, err)
+
+	repo, err := scm.NewRepository(svc.SourceURL)
+	assertNoError(t, err)
+	cicdTriggers, err := createTriggersForCICD(testRepoName, m.GetPipelinesConfig())
+	assertNoError(t, err)
+	ciTrigger, err := repo.CreateFilteredPushTrigger("app-ci-build-from-push-test-svc", "webhook-secret", "webhook-ns", "app-ci-template-test-svc", []string{"test-ci-binding"}, scm.PushFilter{ContextDir: "services/test-svc"})
+	assertNoError(t, err)
+	prTrigger, err := repo.CreatePRTrigger("app-ci-build-from-pr-test-svc", "webhook-secret", "webhook-ns", "app-ci-pr-template-test-svc", []string{"test-pr-binding"})
+	assertNoError(t, err)
+	imageUpdate := &pipelines.ImageUpdate{GitOpsRepoURL: testRepoName, Driver: "github"}
+	cicdPath := filepath.ToSlash(filepath.Join("config", "test-cicd"))
+	want := res.Resources{
+		"config/test-cicd/base/04-pipelines/app-ci-pipeline-test-svc.yaml":    withGitHost(pipelines.CreateAppCIPipeline(meta.NamespacedName("test-cicd", "app-ci-pipeline-test-svc"), imageUpdate, nil, nil, "services/test-svc")),
+		"config/test-cicd/base/06-templates/app-ci-template-test-svc.yaml":    triggers.CreateServiceCITemplate("test-cicd", saName, "app-ci-template-test-svc", "app-ci-pipeline-test-svc"),
+		"config/test-cicd/base/04-pipelines/app-ci-pr-pipeline-test-svc.yaml": withGitHost(pipelines.CreateServiceCIPRPipeline(meta.NamespacedName("test-cicd", "app-ci-pr-pipeline-test-svc"), nil, nil, "services/test-svc")),
+		"config/test-cicd/base/06-templates/app-ci-pr-template-test-svc.yaml": triggers.CreateServiceCIPRTemplate("test-cicd", saName, "app-ci-pr-template-test-svc", "app-ci-pr-pipeline-test-svc"),
+		getEventListenerPath(cicdPath):                                        eventlisteners.CreateELFromTriggers("test-cicd", saName, append(cicdTriggers, ciTrigger, prTrigger)),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("resources didn't match:%s\n", diff)
+	}
+}
+
+func TestBuildEventListenerWithServiceOnAnotherHost(t *testing.T) {
+	defer func(id factory.HostDriverIdentifier) { factory.DefaultIdentifier = id }(factory.DefaultIdentifier)
+	factory.DefaultIdentifier = factory.NewDriverIdentifier(factory.Mapping("gitlab.example.com", "gitlab"))
+	gitOpsRepo := "https://gitlab.example.com/org/gitops.git"
+	svc := testService()
+	m := &config.Manifest{
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{Name: "test-cicd"},
+			Git:       &config.GitConfig{Drivers: map[string]string{"gitlab.example.com": "gitlab"}},
+		},
+		Environments: []*config.Environment{testEnv(svc, "dev")},
+		GitOpsURL:    gitOpsRepo,
+	}
+	got, err := buildEventListenerResources(gitOpsRepo, m)
+	assertNoError(t, err)
+
+	imageUpdate := &pipelines.ImageUpdate{GitOpsRepoURL: gitOpsRepo, Driver: "gitlab"}
+	gitOpsHost := tasks.GitHost{Driver: "gitlab", APIURL: "https://gitlab.example.com/api/v4"}
+	want := res.Resources{
+		"config/test-cicd/base/03-tasks/set-commit-status-task.yaml":          tasks.CreateCommitStatusTask("test-cicd", gitOpsHost),
+		"config/test-cicd/base/04-pipelines/app-ci-pipeline-test-svc.yaml":    withGitHost(pipelines.CreateAppCIPipeline(meta.NamespacedName("test-cicd", "app-ci-pipeline-test-svc"), imageUpdate, nil, nil, "")),
+		"config/test-cicd/base/04-pipelines/app-ci-pr-pipeline-test-svc.yaml": withGitHost(pipelines.CreateServiceCIPRPipeline(meta.NamespacedName("test-cicd", "app-ci-pr-pipeline-test-svc"), nil, nil, "")),
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreMapEntries(func(k string, v interface{}) bool {
+		_, ok := want[k]
+		return !ok
+	})); diff != "" {
+		t.Fatalf("resources didn't match:%s\n", diff)
+	}
+	pipeline := got["config/test-cicd/base/04-pipelines/app-ci-pipeline-test-svc.yaml"].(*pipelinev1.Pipeline)
+	wantParams := []pipelinev1.Param{
+		{Name: "GIT_DRIVER", Value: *pipelinev1.NewArrayOrString("github")},
+		{Name: "GIT_API_URL", Value: *pipelinev1.NewArrayOrString("https://api.github.com")},
+	}
+	params := pipeline.Spec.Finally[0].Params
+	if diff := cmp.Diff(wantParams, params[len(params)-2:]); diff != "" {
+		t.Fatalf("commit status params didn't match:%s\n", diff)
+	}
+}
+
+func TestBuildEventListenerWithPrivateDriver(t *testing.T) {
+	defer func(id factory.HostDriverIdentifier) { factory.DefaultIdentifier = id }(factory.DefaultIdentifier)
+	factory.DefaultIdentifier = factory.NewDriverIdentifier(factory.Mapping("ghe.example.com", "github"))
+	gitOpsRepo := "https://ghe.example.com/org/gitops.git"
+	m := &config.Manifest{
+		Config: &config.Config{
+			Pipelines: &config.PipelinesConfig{Name: "test-cicd"},
+			Git:       &config.GitConfig{Drivers: map[string]string{"ghe.example.com": "github"}},
+		},
+		GitOpsURL: gitOpsRepo,
+	}
+	got, err := buildEventListenerResources(gitOpsRepo, m)
+	assertNoError(t, err)
+
+	host := tasks.GitHost{Driver: "github", APIURL: "https://ghe.example.com/api/v3"}
+	want := res.Resources{
+		"config/test-cicd/base/03-tasks/set-commit-status-task.yaml":   tasks.CreateCommitStatusTask("test-cicd", host),
+		"config/test-cicd/base/03-tasks/post-pr-comment-task.yaml":     tasks.CreatePRCommentTask("test-cicd", host),
+		"config/test-cicd/base/03-tasks/update-gitops-image-task.yaml": tasks.CreateUpdateImageTask("test-cicd", host),
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreMapEntries(func(k string, v interface{}) bool {
+		_, ok := want[k]
+		return !ok
+	})); diff != "" {
+		t.Fatalf("resources didn't match:%s\n", diff)
+	}
+}
+
+func TestBuildEventListenerWithNotifications(t *testing.T) {
+	gitOpsRepo := "https://github.com/org/gitops.git"
+	cfg := &config.PipelinesConfig{
+		Name:          "test-cicd",
+		Notifications: []*config.Notification{{Name: "chat", SecretName: "chat-webhook"}},
+		ConsoleURL:    "https://console.example.com",
+	}
+	m := &config.Manifest{
+		Config: &config.Config{
+			Pipelines: cfg,
+		},
+		GitOpsURL: gitOpsRepo,
+	}
+	got, err := buildEventListenerResources(gitOpsRepo, m)
+	assertNoError(t, err)
+
+	appCI := pipelines.CreateAppCIPipeline(meta.NamespacedName("test-cicd", "app-ci-pipeline"), &pipelines.ImageUpdate{GitOpsRepoURL: gitOpsRepo, Driver: "github"}, nil, nil, "")
+	pushCI := pipelines.CreateCIPipeline(meta.NamespacedName("test-cicd", "ci-dryrun-from-push-pipeline"), "test-cicd")
+	prCI := pipelines.CreateCIPRPipeline(meta.NamespacedName("test-cicd", "ci-dryrun-from-pr-pipeline"), "github")
+	pipelines.AddNotifications(appCI, "$(params.SERVICE)", cfg)
+	for _, p := range []*pipelinev1.Pipeline{pushCI, prCI} {
+		pipelines.AddNotifications(p, "$(params.REPO)", cfg)
+	}
+	want := res.Resources{
+		"config/test-cicd/base/03-tasks/send-notification-task.yaml":           tasks.CreateNotificationTask("test-cicd"),
+		"config/test-cicd/base/04-pipelines/app-ci-pipeline.yaml":              appCI,
+		"config/test-cicd/base/04-pipelines/ci-dryrun-from-push-pipeline.yaml": pushCI,
+		"config/test-cicd/base/04-pipelines/ci-dryrun-from-pr-pipeline.yaml":   prCI,
+		"config/test-cicd/base/06-templates/ci-dryrun-from-push-template.yaml": triggers.CreateCIDryRunTemplate("test-cicd", saName),
+		"config/test-cicd/base/06-templates/ci-dryrun-from-pr-template.yaml":   triggers.CreateCIDryRunPRTemplate("test-cicd", saName),
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreMapEntries(func(k string, v interface{}) bool {
+		_, ok := want[k]
+		return !ok
+	})); diff != "" {
+		t.Fatalf("resources didn't match:%s\n", diff)
+	}
+}
+
 func TestBuildEventListenerWithNoGitOpsURL(t *testing.T) {
 	m := &config.Manifest{
 		Environments: []*config.Environment{
@@ -108,7 +366,16 @@ func TestGetPipelines(t *testing.T) {
 				Name:      "test-service",
 				Pipelines: testPipelines("svc"),
 			},
-			testPipelines("svc"),
+			&config.Pipelines{
+				Integration: &config.TemplateBinding{
+					Template: "svc-ci-template",
+					Bindings: []string{"svc-ci-binding"},
+				},
+				PullRequest: &config.TemplateBinding{
+					Template: "app-ci-pr-template",
+					Bindings: []string{"github-pr-binding"},
+				},
+			},
 		},
 		{
 			"Default pipelines are used",
@@ -123,6 +390,10 @@ func TestGetPipelines(t *testing.T) {
 					Template: "app-ci-template",
 					Bindings: []string{"github-push-binding"},
 				},
+				PullRequest: &config.TemplateBinding{
+					Template: "app-ci-pr-template",
+					Bindings: []string{"github-pr-binding"},
+				},
 			},
 		},
 		{
@@ -167,6 +438,111 @@ func TestGetPipelines(t *testing.T) {
 				},
 			},
 		},
+		{
+			"Override the branches and tags in the service",
+			&config.Environment{
+				Name: "test-env",
+				Pipelines: &config.Pipelines{
+					Integration: &config.TemplateBinding{Template: "env-ci-template", Bindings: []string{"env-ci-binding"}},
+					Branches:    []string{"develop"},
+				},
+			},
+			&config.Service{
+				Name: "test-service",
+				Pipelines: &config.Pipelines{
+					Integration: &config.TemplateBinding{},
+					Tags:        []string{"v*"},
+				},
+			},
+			&config.Pipelines{
+				Integration: &config.TemplateBinding{
+					Template: "env-ci-template",
+					Bindings: []string{"env-ci-binding"},
+				},
+				Tags: []string{"v*"},
+			},
+		},
+		{
+			"Only the branches in the environment and tags in the service",
+			&config.Environment{
+				Name: "test-env",
+				Pipelines: &config.Pipelines{
+					Branches: []string{"main"},
+				},
+			},
+			&config.Service{
+				Name: "test-service",
+				Pipelines: &config.Pipelines{
+					Tags: []string{"v*"},
+				},
+			},
+			&config.Pipelines{
+				Integration: &config.TemplateBinding{
+					Template: "app-ci-template",
+					Bindings: []string{"github-push-binding"},
+				},
+				PullRequest: &config.TemplateBinding{
+					Template: "app-ci-pr-template",
+					Bindings: []string{"github-pr-binding"},
+				},
+				Tags: []string{"v*"},
+			},
+		},
+		{
+			"Override the pull request bindings in the service",
+			&config.Environment{
+				Name: "test-env",
+				Pipelines: &config.Pipelines{
+					Integration: &config.TemplateBinding{Template: "env-ci-template", Bindings: []string{"env-ci-binding"}},
+					PullRequest: &config.TemplateBinding{Template: "env-pr-template", Bindings: []string{"env-pr-binding"}},
+				},
+			},
+			&config.Service{
+				Name: "test-service",
+				Pipelines: &config.Pipelines{
+					Integration: &config.TemplateBinding{},
+					PullRequest: &config.TemplateBinding{
+						Bindings: []string{"svc-pr-binding"},
+					},
+				},
+			},
+			&config.Pipelines{
+				Integration: &config.TemplateBinding{
+					Template: "env-ci-template",
+					Bindings: []string{"env-ci-binding"},
+				},
+				PullRequest: &config.TemplateBinding{
+					Template: "env-pr-template",
+					Bindings: []string{"svc-pr-binding"},
+				},
+			},
+		},
+		{
+			"Pull request in the service and not in the environment",
+			&config.Environment{
+				Name:      "test-env",
+				Pipelines: testPipelines("env"),
+			},
+			&config.Service{
+				Name: "test-service",
+				Pipelines: &config.Pipelines{
+					Integration: &config.TemplateBinding{},
+					PullRequest: &config.TemplateBinding{
+						Bindings: []string{"svc-pr-binding"},
+					},
+				},
+			},
+			&config.Pipelines{
+				Integration: &config.TemplateBinding{
+					Template: "env-ci-template",
+					Bindings: []string{"env-ci-binding"},
+				},
+				PullRequest: &config.TemplateBinding{
+					Template: "app-ci-pr-template",
+					Bindings: []string{"svc-pr-binding"},
+				},
+			},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.desc, func(rt *testing.T) {
@@ -205,6 +581,13 @@ func fakeTriggers(t *testing.T, m *config.Manifest, gitOpsRepo string) []trigger
 	return triggers
 }
 
+// withGitHost sets the host of the test service, github.com, on the commit
+// status tasks of the pipeline.
+func withGitHost(p *pipelinev1.Pipeline) *pipelinev1.Pipeline {
+	pipelines.AddGitHost(p, tasks.GitHost{Driver: "github", APIURL: "https://api.github.com"})
+	return p
+}
+
 func testService() *config.Service {
 	return &config.Service{
 		Name:      "test-svc",
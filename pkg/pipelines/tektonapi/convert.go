@@ -0,0 +1,285 @@
+package tektonapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+)
+
+// The API versions that are generated, and the versions that they are
+// converted to.
+const (
+	PipelineV1Beta1  = "tekton.dev/v1beta1"
+	PipelineV1       = "tekton.dev/v1"
+	TriggersV1Alpha1 = "triggers.tekton.dev/v1alpha1"
+	TriggersV1Beta1  = "triggers.tekton.dev/v1beta1"
+)
+
+// The interceptors that were configured with their own field in
+// triggers.tekton.dev/v1alpha1, these are all ClusterInterceptors in
+// triggers.tekton.dev/v1beta1.
+var legacyInterceptors = []string{"cel", "github", "gitlab", "bitbucket"}
+
+var errPipelineResources = errors.New("PipelineResources are not supported by " + PipelineV1)
+
+// ConvertResources converts the Tekton resources to the API versions for the
+// target version, other resources are returned unchanged.
+//
+// The resources are generated with the TektonV1Beta1 versions, so only the
+// TektonV1 version requires any conversion.
+func ConvertResources(resources res.Resources, version string) (res.Resources, error) {
+	if version != config.TektonV1 {
+		return resources, nil
+	}
+	converted := res.Resources{}
+	for k, v := range resources {
+		obj, err := toObject(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert %s: %w", k, err)
+		}
+		if obj == nil {
+			converted[k] = v
+			continue
+		}
+		if err := Convert(obj); err != nil {
+			return nil, fmt.Errorf("failed to convert %s: %w", k, err)
+		}
+		converted[k] = obj
+	}
+	return converted, nil
+}
+
+// NeedsConversion returns true if the object is a Tekton resource that would
+// be changed by Convert.
+//
+// ClusterTasks are left with the tekton.dev/v1beta1 version, as there is no
+// ClusterTask in tekton.dev/v1.
+func NeedsConversion(obj map[string]interface{}) bool {
+	switch obj["apiVersion"] {
+	case PipelineV1Beta1:
+		return obj["kind"] != "ClusterTask"
+	case TriggersV1Alpha1:
+		return true
+	}
+	return false
+}
+
+// Convert converts a tekton.dev/v1beta1 or triggers.tekton.dev/v1alpha1
+// resource in place to the tekton.dev/v1 or triggers.tekton.dev/v1beta1
+// version, ClusterTasks are not converted.
+func Convert(obj map[string]interface{}) error {
+	if !NeedsConversion(obj) {
+		return nil
+	}
+	spec, _ := obj["spec"].(map[string]interface{})
+	switch obj["apiVersion"] {
+	case PipelineV1Beta1:
+		obj["apiVersion"] = PipelineV1
+		if spec == nil {
+			return nil
+		}
+		switch obj["kind"] {
+		case "Task":
+			return convertTaskSpec(spec)
+		case "Pipeline":
+			return convertPipelineSpec(spec)
+		case "PipelineRun":
+			return convertPipelineRunSpec(spec)
+		case "TaskRun":
+			return convertTaskRunSpec(spec)
+		}
+	case TriggersV1Alpha1:
+		obj["apiVersion"] = TriggersV1Beta1
+		if spec == nil {
+			return nil
+		}
+		switch obj["kind"] {
+		case "TriggerTemplate":
+			return convertTriggerTemplateSpec(spec)
+		case "EventListener":
+			for _, t := range objects(spec["triggers"]) {
+				convertTriggerSpec(t)
+			}
+		case "Trigger":
+			convertTriggerSpec(spec)
+		}
+	}
+	return nil
+}
+
+func convertTaskSpec(spec map[string]interface{}) error {
+	if _, ok := spec["resources"]; ok {
+		return errPipelineResources
+	}
+	for _, s := range objects(spec["steps"]) {
+		rename(s, "resources", "computeResources")
+	}
+	for _, s := range objects(spec["sidecars"]) {
+		rename(s, "resources", "computeResources")
+	}
+	if t, ok := spec["stepTemplate"].(map[string]interface{}); ok {
+		rename(t, "resources", "computeResources")
+	}
+	return nil
+}
+
+func convertPipelineSpec(spec map[string]interface{}) error {
+	if _, ok := spec["resources"]; ok {
+		return errPipelineResources
+	}
+	tasks := append(objects(spec["tasks"]), objects(spec["finally"])...)
+	for _, t := range tasks {
+		if _, ok := t["resources"]; ok {
+			return errPipelineResources
+		}
+		if _, ok := t["conditions"]; ok {
+			return fmt.Errorf("conditions are not supported by %s, use when expressions in task %v", PipelineV1, t["name"])
+		}
+		if ts, ok := t["taskSpec"].(map[string]interface{}); ok {
+			if err := convertTaskSpec(ts); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func convertPipelineRunSpec(spec map[string]interface{}) error {
+	if _, ok := spec["resources"]; ok {
+		return errPipelineResources
+	}
+	taskRunTemplate := map[string]interface{}{}
+	for _, k := range []string{"serviceAccountName", "podTemplate"} {
+		if v, ok := spec[k]; ok {
+			taskRunTemplate[k] = v
+			delete(spec, k)
+		}
+	}
+	if len(taskRunTemplate) > 0 {
+		spec["taskRunTemplate"] = taskRunTemplate
+	}
+	if v, ok := spec["timeout"]; ok {
+		spec["timeouts"] = map[string]interface{}{"pipeline": v}
+		delete(spec, "timeout")
+	}
+	if ps, ok := spec["pipelineSpec"].(map[string]interface{}); ok {
+		return convertPipelineSpec(ps)
+	}
+	return nil
+}
+
+func convertTaskRunSpec(spec map[string]interface{}) error {
+	if _, ok := spec["resources"]; ok {
+		return errPipelineResources
+	}
+	if ts, ok := spec["taskSpec"].(map[string]interface{}); ok {
+		return convertTaskSpec(ts)
+	}
+	return nil
+}
+
+func convertTriggerTemplateSpec(spec map[string]interface{}) error {
+	for _, rt := range objects(spec["resourcetemplates"]) {
+		if err := Convert(rt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// convertTriggerSpec replaces the deprecated name references to templates
+// and bindings, and the legacy interceptor fields with references to the
+// ClusterInterceptors.
+func convertTriggerSpec(spec map[string]interface{}) {
+	if t, ok := spec["template"].(map[string]interface{}); ok {
+		if _, ok := t["ref"]; !ok {
+			rename(t, "name", "ref")
+		}
+	}
+	for _, b := range objects(spec["bindings"]) {
+		_, hasRef := b["ref"]
+		_, hasValue := b["value"]
+		if !hasRef && !hasValue {
+			rename(b, "name", "ref")
+		}
+	}
+	for _, i := range objects(spec["interceptors"]) {
+		convertInterceptor(i)
+	}
+}
+
+func convertInterceptor(i map[string]interface{}) {
+	for _, name := range legacyInterceptors {
+		legacy, ok := i[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		delete(i, name)
+		keys := []string{}
+		for k := range legacy {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		params := []interface{}{}
+		for _, k := range keys {
+			params = append(params, map[string]interface{}{"name": k, "value": legacy[k]})
+		}
+		i["ref"] = map[string]interface{}{"name": name}
+		if len(params) > 0 {
+			i["params"] = params
+		}
+	}
+}
+
+// toObject returns the resource as a generic object if it needs conversion,
+// or nil if it doesn't.
+func toObject(v interface{}) (map[string]interface{}, error) {
+	if obj, ok := v.(map[string]interface{}); ok {
+		if NeedsConversion(obj) {
+			return obj, nil
+		}
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var peek struct {
+		APIVersion string `json:"apiVersion"`
+		Kind       string `json:"kind"`
+	}
+	if err := json.Unmarshal(b, &peek); err != nil {
+		// Not an object, and so not a Tekton resource.
+		return nil, nil
+	}
+	if !NeedsConversion(map[string]interface{}{"apiVersion": peek.APIVersion, "kind": peek.Kind}) {
+		return nil, nil
+	}
+	obj := map[string]interface{}{}
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+func objects(v interface{}) []map[string]interface{} {
+	items, _ := v.([]interface{})
+	objs := []map[string]interface{}{}
+	for _, item := range items {
+		if obj, ok := item.(map[string]interface{}); ok {
+			objs = append(objs, obj)
+		}
+	}
+	return objs
+}
+
+func rename(obj map[string]interface{}, from, to string) {
+	if v, ok := obj[from]; ok {
+		obj[to] = v
+		delete(obj, from)
+	}
+}
@@ -0,0 +1,270 @@
+package tektonapi
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/meta"
+	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+	"github.com/redhat-developer/kam/test"
+)
+
+func TestConvert(t *testing.T) {
+	convertTests := []struct {
+		name string
+		obj  string
+		want string
+	}{
+		{
+			"task",
+			`
+apiVersion: tekton.dev/v1beta1
+kind: Task
+spec:
+  steps:
+  - name: build
+    resources:
+      limits:
+        cpu: 1`,
+			`
+apiVersion: tekton.dev/v1
+kind: Task
+spec:
+  steps:
+  - name: build
+    computeResources:
+      limits:
+        cpu: 1`,
+		},
+		{
+			"pipeline with embedded task",
+			`
+apiVersion: tekton.dev/v1beta1
+kind: Pipeline
+spec:
+  tasks:
+  - name: build
+    taskSpec:
+      steps:
+      - name: build
+        resources: {}`,
+			`
+apiVersion: tekton.dev/v1
+kind: Pipeline
+spec:
+  tasks:
+  - name: build
+    taskSpec:
+      steps:
+      - name: build
+        computeResources: {}`,
+		},
+		{
+			"trigger template with a pipelinerun",
+			`
+apiVersion: triggers.tekton.dev/v1alpha1
+kind: TriggerTemplate
+spec:
+  resourcetemplates:
+  - apiVersion: tekton.dev/v1beta1
+    kind: PipelineRun
+    spec:
+      serviceAccountName: pipeline
+      timeout: 1h
+      pipelineRef:
+        name: app-ci-pipeline`,
+			`
+apiVersion: triggers.tekton.dev/v1beta1
+kind: TriggerTemplate
+spec:
+  resourcetemplates:
+  - apiVersion: tekton.dev/v1
+    kind: PipelineRun
+    spec:
+      taskRunTemplate:
+        serviceAccountName: pipeline
+      timeouts:
+        pipeline: 1h
+      pipelineRef:
+        name: app-ci-pipeline`,
+		},
+		{
+			"eventlistener with legacy interceptors",
+			`
+apiVersion: triggers.tekton.dev/v1alpha1
+kind: EventListener
+spec:
+  triggers:
+  - name: ci-dryrun-from-push
+    bindings:
+    - name: github-push-binding
+    template:
+      name: ci-dryrun-from-push-template
+    interceptors:
+    - github:
+        secretRef:
+          secretName: gitops-webhook-secret
+          secretKey: webhook-secret-key
+        eventTypes: [push]
+    - cel:
+        filter: header.match('X-GitHub-Event', 'push')`,
+			`
+apiVersion: triggers.tekton.dev/v1beta1
+kind: EventListener
+spec:
+  triggers:
+  - name: ci-dryrun-from-push
+    bindings:
+    - ref: github-push-binding
+    template:
+      ref: ci-dryrun-from-push-template
+    interceptors:
+    - ref:
+        name: github
+      params:
+      - name: eventTypes
+        value: [push]
+      - name: secretRef
+        value:
+          secretName: gitops-webhook-secret
+          secretKey: webhook-secret-key
+    - ref:
+        name: cel
+      params:
+      - name: filter
+        value: header.match('X-GitHub-Event', 'push')`,
+		},
+		{
+			"converted resources are unchanged",
+			`
+apiVersion: triggers.tekton.dev/v1beta1
+kind: TriggerBinding
+spec:
+  params:
+  - name: gitref
+    value: $(body.ref)`,
+			`
+apiVersion: triggers.tekton.dev/v1beta1
+kind: TriggerBinding
+spec:
+  params:
+  - name: gitref
+    value: $(body.ref)`,
+		},
+	}
+
+	for _, tt := range convertTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			obj := parse(rt, tt.obj)
+			if err := Convert(obj); err != nil {
+				rt.Fatal(err)
+			}
+			if diff := cmp.Diff(parse(rt, tt.want), obj); diff != "" {
+				rt.Fatalf("Convert() failed:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConvertClusterTask(t *testing.T) {
+	clusterTask := `
+apiVersion: tekton.dev/v1beta1
+kind: ClusterTask
+spec:
+  steps:
+  - name: build
+    resources:
+      limits:
+        cpu: 1`
+	obj := parse(t, clusterTask)
+	if NeedsConversion(obj) {
+		t.Fatal("NeedsConversion() is true for a ClusterTask")
+	}
+	if err := Convert(obj); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(parse(t, clusterTask), obj); diff != "" {
+		t.Fatalf("Convert() changed the ClusterTask:\n%s", diff)
+	}
+
+	resources := res.Resources{
+		"cluster-task.yaml": &pipelinev1.ClusterTask{
+			TypeMeta:   meta.TypeMeta("ClusterTask", PipelineV1Beta1),
+			ObjectMeta: meta.ObjectMeta(meta.NamespacedName("", "cluster-task")),
+		},
+	}
+	converted, err := ConvertResources(resources, config.TektonV1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(resources, converted); diff != "" {
+		t.Fatalf("ConvertResources(%s) changed the ClusterTask:\n%s", config.TektonV1, diff)
+	}
+}
+
+func TestConvertPipelineResources(t *testing.T) {
+	obj := parse(t, `
+apiVersion: tekton.dev/v1beta1
+kind: Pipeline
+spec:
+  resources:
+  - name: source-repo
+    type: git`)
+
+	err := Convert(obj)
+	test.AssertErrorMatch(t, "PipelineResources are not supported", err)
+}
+
+func TestConvertResources(t *testing.T) {
+	task := &pipelinev1.Task{
+		TypeMeta:   meta.TypeMeta("Task", PipelineV1Beta1),
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName("cicd", "test-task")),
+	}
+	kustomization := res.Kustomization{Resources: []string{"test-task.yaml"}}
+	resources := res.Resources{
+		"test-task.yaml":     task,
+		"kustomization.yaml": kustomization,
+	}
+
+	unchanged, err := ConvertResources(resources, config.TektonV1Beta1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(resources, unchanged); diff != "" {
+		t.Fatalf("ConvertResources(%s) failed:\n%s", config.TektonV1Beta1, diff)
+	}
+
+	converted, err := ConvertResources(resources, config.TektonV1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := res.Resources{
+		"test-task.yaml": map[string]interface{}{
+			"apiVersion": PipelineV1,
+			"kind":       "Task",
+			"metadata": map[string]interface{}{
+				"name":              "test-task",
+				"namespace":         "cicd",
+				"creationTimestamp": nil,
+			},
+			"spec": map[string]interface{}{},
+		},
+		"kustomization.yaml": kustomization,
+	}
+	if diff := cmp.Diff(want, converted); diff != "" {
+		t.Fatalf("ConvertResources(%s) failed:\n%s", config.TektonV1, diff)
+	}
+}
+
+func parse(t *testing.T, s string) map[string]interface{} {
+	t.Helper()
+	obj := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(s), &obj); err != nil {
+		t.Fatal(err)
+	}
+	return obj
+}
@@ -0,0 +1,32 @@
+package tektonapi
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+)
+
+// PipelineRunsResource identifies the PipelineRuns with the API version of
+// the configured Tekton version for clients that read them back from the
+// cluster.
+func PipelineRunsResource(version string) schema.GroupVersionResource {
+	return pipelineResource(version, "pipelineruns")
+}
+
+// TaskRunsResource identifies the TaskRuns with the API version of the
+// configured Tekton version for clients that read them back from the cluster.
+func TaskRunsResource(version string) schema.GroupVersionResource {
+	return pipelineResource(version, "taskruns")
+}
+
+func pipelineResource(version, resource string) schema.GroupVersionResource {
+	v := "v1beta1"
+	if version == config.TektonV1 {
+		v = "v1"
+	}
+	return schema.GroupVersionResource{
+		Group:    "tekton.dev",
+		Version:  v,
+		Resource: resource,
+	}
+}
@@ -0,0 +1,308 @@
+package pipelines
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	res "github.com/redhat-developer/kam/pkg/pipelines/resources"
+	"github.com/redhat-developer/kam/pkg/pipelines/tasks"
+	"github.com/redhat-developer/kam/pkg/pipelines/tektonapi"
+	"github.com/redhat-developer/kam/pkg/pipelines/yaml"
+)
+
+// AddPipelineOptions encapsulates the parameters for the pipeline add
+// command.
+type AddPipelineOptions struct {
+	PipelinesFolderPath string
+	FromDir             string // The directory with the Pipelines and Tasks to import.
+}
+
+// AddTriggerTemplateOptions encapsulates the parameters for the
+// trigger-template add command.
+type AddTriggerTemplateOptions struct {
+	PipelinesFolderPath string
+	FromFile            string // The file with the TriggerTemplates to import.
+}
+
+// AddPipelines imports the Pipelines and Tasks in the YAML files in a
+// directory into the CI/CD configuration, so that they can be used by custom
+// TriggerTemplates.
+//
+// The Tasks referenced by the Pipelines must either be imported with them, or
+// already exist in the CI/CD configuration.
+func AddPipelines(o *AddPipelineOptions, appFs afero.Fs) error {
+	m, cfg, err := loadPipelinesConfig(appFs, o.PipelinesFolderPath)
+	if err != nil {
+		return err
+	}
+	infos, err := afero.ReadDir(appFs, o.FromDir)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", o.FromDir, err)
+	}
+	objs := []map[string]interface{}{}
+	for _, info := range infos {
+		path := filepath.Join(o.FromDir, info.Name())
+		if info.IsDir() || !isYAMLFile(path) {
+			continue
+		}
+		read, err := readResources(appFs, path)
+		if err != nil {
+			return err
+		}
+		objs = append(objs, read...)
+	}
+	if len(objs) == 0 {
+		return fmt.Errorf("no Pipelines or Tasks found in %s", o.FromDir)
+	}
+	base := filepath.Join(o.PipelinesFolderPath, pipelinesPath(m.Config))
+	taskNames, err := config.ResourceNames(appFs, base, "Task")
+	if err != nil {
+		return err
+	}
+	generated := generatedResourcePaths(m)
+	files := res.Resources{}
+	pipelines := []map[string]interface{}{}
+	for _, obj := range objs {
+		name, err := validateImportedResource(obj, cfg.Name, []string{tektonapi.PipelineV1Beta1, tektonapi.PipelineV1}, "Pipeline", "Task")
+		if err != nil {
+			return err
+		}
+		path := filepath.ToSlash(filepath.Join("04-pipelines", name+".yaml"))
+		if obj["kind"] == "Task" {
+			path = filepath.ToSlash(filepath.Join("03-tasks", name+".yaml"))
+		}
+		if generated[path] {
+			return fmt.Errorf("failed to import %v %q: the name is used by a resource generated by kam", obj["kind"], name)
+		}
+		switch obj["kind"] {
+		case "Task":
+			taskNames[name] = true
+		case "Pipeline":
+			pipelines = append(pipelines, obj)
+		}
+		files[path] = obj
+	}
+	for _, p := range pipelines {
+		if err := validateTaskRefs(p, taskNames); err != nil {
+			return err
+		}
+	}
+	return writeImportedResources(appFs, base, files, cfg)
+}
+
+// AddTriggerTemplates imports the TriggerTemplates in a file into the CI/CD
+// configuration, so that they can be referenced by the pipelines of
+// environments and services.
+//
+// The Pipelines referenced by the PipelineRuns in the templates must already
+// exist in the CI/CD configuration.
+func AddTriggerTemplates(o *AddTriggerTemplateOptions, appFs afero.Fs) error {
+	m, cfg, err := loadPipelinesConfig(appFs, o.PipelinesFolderPath)
+	if err != nil {
+		return err
+	}
+	objs, err := readResources(appFs, o.FromFile)
+	if err != nil {
+		return err
+	}
+	if len(objs) == 0 {
+		return fmt.Errorf("no TriggerTemplates found in %s", o.FromFile)
+	}
+	base := filepath.Join(o.PipelinesFolderPath, pipelinesPath(m.Config))
+	pipelines, err := config.ResourceNames(appFs, base, "Pipeline")
+	if err != nil {
+		return err
+	}
+	generated := generatedResourcePaths(m)
+	files := res.Resources{}
+	for _, obj := range objs {
+		name, err := validateImportedResource(obj, cfg.Name, []string{tektonapi.TriggersV1Alpha1, tektonapi.TriggersV1Beta1}, "TriggerTemplate")
+		if err != nil {
+			return err
+		}
+		path := filepath.ToSlash(filepath.Join("06-templates", name+".yaml"))
+		if generated[path] {
+			return fmt.Errorf("failed to import TriggerTemplate %q: the name is used by a resource generated by kam", name)
+		}
+		if err := validatePipelineRefs(obj, pipelines); err != nil {
+			return err
+		}
+		files[path] = obj
+	}
+	return writeImportedResources(appFs, base, files, cfg)
+}
+
+// generatedResourcePaths returns the paths that the resources generated by kam
+// are written to in the CI/CD configuration, and the paths that resources with
+// the same names as the generated resources would be imported to, so that
+// imported resources don't replace, or clash with, the generated resources.
+func generatedResourcePaths(m *config.Manifest) map[string]bool {
+	paths := map[string]bool{}
+	for _, p := range []string{
+		gitopsTasksPath, commitStatusTaskPath, buildImageTaskPath, prCommentTaskPath,
+		updateImageTaskPath, notificationTaskPath, buildpacksTaskPath,
+		ciPipelinesPath, appCiPipelinesPath, appCIPRPipelinesPath, ciPRPipelinesPath,
+		pushTemplatePath, appCIPushTemplatePath, appCIPRTemplatePath, prTemplatePath,
+	} {
+		paths[p] = true
+	}
+	for _, name := range []string{
+		tasks.CommitStatusTaskName, tasks.BuildImageTaskName, tasks.PRCommentTaskName,
+		tasks.UpdateImageTaskName, tasks.NotificationTaskName, tasks.BuildpacksTaskName,
+	} {
+		paths[filepath.ToSlash(filepath.Join("03-tasks", name+".yaml"))] = true
+	}
+	for _, name := range []string{appCITemplateName, appCIPRTemplateName} {
+		paths[filepath.ToSlash(filepath.Join("06-templates", name+".yaml"))] = true
+	}
+	for _, env := range m.Environments {
+		for _, app := range env.Apps {
+			for _, svc := range app.Services {
+				paths[filepath.ToSlash(filepath.Join("04-pipelines", "app-ci-pipeline-"+svc.Name+".yaml"))] = true
+				paths[filepath.ToSlash(filepath.Join("04-pipelines", "app-ci-pr-pipeline-"+svc.Name+".yaml"))] = true
+				paths[filepath.ToSlash(filepath.Join("06-templates", "app-ci-template-"+svc.Name+".yaml"))] = true
+				paths[filepath.ToSlash(filepath.Join("06-templates", "app-ci-pr-template-"+svc.Name+".yaml"))] = true
+			}
+		}
+	}
+	return paths
+}
+
+func loadPipelinesConfig(appFs afero.Fs, path string) (*config.Manifest, *config.PipelinesConfig, error) {
+	m, err := config.LoadManifest(appFs, path)
+	if err != nil {
+		return nil, nil, err
+	}
+	cfg := m.GetPipelinesConfig()
+	if cfg == nil {
+		return nil, nil, errors.New("failed to find the pipelines configuration in the manifest")
+	}
+	return m, cfg, nil
+}
+
+// validateImportedResource checks the apiVersion and kind of the resource,
+// and that it is in the CI/CD namespace, and returns its name.
+//
+// Resources without a namespace are moved into the CI/CD namespace.
+func validateImportedResource(obj map[string]interface{}, ns string, apiVersions []string, kinds ...string) (string, error) {
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	if name == "" {
+		return "", fmt.Errorf("%v is missing a name", obj["kind"])
+	}
+	if !containsString(kinds, obj["kind"]) {
+		return "", fmt.Errorf("%v %q is not supported, must be one of %v", obj["kind"], name, kinds)
+	}
+	if !containsString(apiVersions, obj["apiVersion"]) {
+		return "", fmt.Errorf("%v %q has unsupported apiVersion %v, must be one of %v", obj["kind"], name, obj["apiVersion"], apiVersions)
+	}
+	if objNS, ok := metadata["namespace"].(string); ok && objNS != ns {
+		return "", fmt.Errorf("%v %q must be in the %q namespace, not %q", obj["kind"], name, ns, objNS)
+	}
+	metadata["namespace"] = ns
+	return name, nil
+}
+
+// validateTaskRefs checks that the Tasks referenced by the Pipeline exist,
+// references to ClusterTasks and remote Tasks are not checked.
+func validateTaskRefs(pipeline map[string]interface{}, tasks map[string]bool) error {
+	spec, _ := pipeline["spec"].(map[string]interface{})
+	pipelineTasks, _ := spec["tasks"].([]interface{})
+	finallyTasks, _ := spec["finally"].([]interface{})
+	for _, t := range append(pipelineTasks, finallyTasks...) {
+		pt, _ := t.(map[string]interface{})
+		ref, ok := pt["taskRef"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if kind, ok := ref["kind"].(string); ok && kind != "Task" {
+			continue
+		}
+		name, _ := ref["name"].(string)
+		if name == "" {
+			continue
+		}
+		if !tasks[name] {
+			metadata, _ := pipeline["metadata"].(map[string]interface{})
+			return fmt.Errorf("failed to import Pipeline %q: unknown Task %q", metadata["name"], name)
+		}
+	}
+	return nil
+}
+
+// validatePipelineRefs checks that the Pipelines referenced by the
+// PipelineRuns in the TriggerTemplate exist.
+func validatePipelineRefs(template map[string]interface{}, pipelines map[string]bool) error {
+	spec, _ := template["spec"].(map[string]interface{})
+	resourceTemplates, _ := spec["resourcetemplates"].([]interface{})
+	for _, rt := range resourceTemplates {
+		obj, _ := rt.(map[string]interface{})
+		if obj["kind"] != "PipelineRun" {
+			continue
+		}
+		runSpec, _ := obj["spec"].(map[string]interface{})
+		ref, _ := runSpec["pipelineRef"].(map[string]interface{})
+		name, _ := ref["name"].(string)
+		if name != "" && !pipelines[name] {
+			metadata, _ := template["metadata"].(map[string]interface{})
+			return fmt.Errorf("failed to import TriggerTemplate %q: unknown Pipeline %q", metadata["name"], name)
+		}
+	}
+	return nil
+}
+
+// writeImportedResources writes the resources into the CI/CD configuration
+// with the configured Tekton API version, and updates the kustomization.
+func writeImportedResources(appFs afero.Fs, base string, files res.Resources, cfg *config.PipelinesConfig) error {
+	files, err := tektonapi.ConvertResources(files, cfg.GetTektonAPIVersion())
+	if err != nil {
+		return err
+	}
+	if _, err := yaml.WriteResources(appFs, base, files); err != nil {
+		return err
+	}
+	return updateKustomization(appFs, base)
+}
+
+// readResources reads all the documents in a YAML file.
+func readResources(appFs afero.Fs, path string) ([]map[string]interface{}, error) {
+	f, err := appFs.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+	decoder := k8syaml.NewYAMLOrJSONDecoder(f, 4096)
+	objs := []map[string]interface{}{}
+	for {
+		obj := map[string]interface{}{}
+		if err := decoder.Decode(&obj); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objs, nil
+			}
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		if len(obj) > 0 {
+			objs = append(objs, obj)
+		}
+	}
+}
+
+func isYAMLFile(path string) bool {
+	ext := filepath.Ext(path)
+	return ext == ".yaml" || ext == ".yml"
+}
+
+func containsString(items []string, v interface{}) bool {
+	for _, item := range items {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,195 @@
+package pipelines
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+
+	"github.com/redhat-developer/kam/test"
+)
+
+const (
+	testTemplatesManifest = `config:
+  pipelines:
+    name: cicd
+environments:
+- name: dev
+`
+
+	testGoTestTask = `apiVersion: tekton.dev/v1beta1
+kind: Task
+metadata:
+  name: go-test
+spec:
+  steps:
+  - name: test
+    image: golang:1.17
+    script: go test ./...
+`
+
+	testGoPipeline = `apiVersion: tekton.dev/v1beta1
+kind: Pipeline
+metadata:
+  name: go-ci-pipeline
+spec:
+  tasks:
+  - name: clone
+    taskRef:
+      name: git-clone
+      kind: ClusterTask
+  - name: test
+    taskRef:
+      name: go-test
+`
+
+	testGoTemplate = `apiVersion: triggers.tekton.dev/v1alpha1
+kind: TriggerTemplate
+metadata:
+  name: go-ci-template
+spec:
+  resourcetemplates:
+  - apiVersion: tekton.dev/v1beta1
+    kind: PipelineRun
+    metadata:
+      generateName: go-ci-
+    spec:
+      pipelineRef:
+        name: go-ci-pipeline
+`
+)
+
+func TestAddPipelines(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeTestFiles(t, fs, map[string]string{
+		"/gitops/pipelines.yaml":      testTemplatesManifest,
+		"/tekton/go-test-task.yaml":   testGoTestTask,
+		"/tekton/go-ci-pipeline.yaml": testGoPipeline,
+		"/tekton/README.md":           "not a resource",
+	})
+
+	err := AddPipelines(&AddPipelineOptions{PipelinesFolderPath: "/gitops", FromDir: "/tekton"}, fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	task := mustReadFileAsMap(t, fs, "/gitops/config/cicd/base/03-tasks/go-test.yaml")
+	if diff := cmp.Diff(map[string]interface{}{"name": "go-test", "namespace": "cicd"}, task["metadata"]); diff != "" {
+		t.Fatalf("imported Task failed:\n%s", diff)
+	}
+	want := map[string]interface{}{
+		"resources": []interface{}{"03-tasks/go-test.yaml", "04-pipelines/go-ci-pipeline.yaml"},
+	}
+	got := mustReadFileAsMap(t, fs, "/gitops/config/cicd/base/kustomization.yaml")
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("kustomization failed:\n%s", diff)
+	}
+}
+
+func TestAddPipelinesWithErrors(t *testing.T) {
+	addTests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			"unknown task",
+			map[string]string{"/tekton/go-ci-pipeline.yaml": testGoPipeline},
+			`failed to import Pipeline "go-ci-pipeline": unknown Task "go-test"`,
+		},
+		{
+			"unsupported kind",
+			map[string]string{"/tekton/go-ci-template.yaml": testGoTemplate},
+			`TriggerTemplate "go-ci-template" is not supported`,
+		},
+		{
+			"different namespace",
+			map[string]string{"/tekton/go-test-task.yaml": strings.Replace(testGoTestTask, "name: go-test\n", "name: go-test\n  namespace: other\n", 1)},
+			`Task "go-test" must be in the "cicd" namespace, not "other"`,
+		},
+		{
+			"no resources",
+			map[string]string{"/tekton/README.md": "not a resource"},
+			"no Pipelines or Tasks found in /tekton",
+		},
+		{
+			"generated task",
+			map[string]string{"/tekton/go-test-task.yaml": strings.Replace(testGoTestTask, "name: go-test", "name: set-commit-status", 1)},
+			`failed to import Task "set-commit-status": the name is used by a resource generated by kam`,
+		},
+		{
+			"generated pipeline",
+			map[string]string{"/tekton/go-ci-pipeline.yaml": strings.Replace(testGoPipeline, "name: go-ci-pipeline", "name: app-ci-pipeline", 1)},
+			`failed to import Pipeline "app-ci-pipeline": the name is used by a resource generated by kam`,
+		},
+	}
+
+	for _, tt := range addTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			fs := afero.NewMemMapFs()
+			writeTestFiles(rt, fs, tt.files)
+			writeTestFiles(rt, fs, map[string]string{"/gitops/pipelines.yaml": testTemplatesManifest})
+
+			err := AddPipelines(&AddPipelineOptions{PipelinesFolderPath: "/gitops", FromDir: "/tekton"}, fs)
+			test.AssertErrorMatch(rt, tt.wantErr, err)
+		})
+	}
+}
+
+func TestAddTriggerTemplates(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeTestFiles(t, fs, map[string]string{
+		"/gitops/pipelines.yaml":                                    testTemplatesManifest,
+		"/gitops/config/cicd/base/04-pipelines/go-ci-pipeline.yaml": testGoPipeline,
+		"/tekton/go-ci-template.yaml":                               testGoTemplate,
+	})
+
+	err := AddTriggerTemplates(&AddTriggerTemplateOptions{PipelinesFolderPath: "/gitops", FromFile: "/tekton/go-ci-template.yaml"}, fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := mustReadFileAsMap(t, fs, "/gitops/config/cicd/base/06-templates/go-ci-template.yaml")
+	if diff := cmp.Diff(map[string]interface{}{"name": "go-ci-template", "namespace": "cicd"}, template["metadata"]); diff != "" {
+		t.Fatalf("imported TriggerTemplate failed:\n%s", diff)
+	}
+}
+
+func TestAddTriggerTemplatesWithUnknownPipeline(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeTestFiles(t, fs, map[string]string{
+		"/gitops/pipelines.yaml":      testTemplatesManifest,
+		"/tekton/go-ci-template.yaml": testGoTemplate,
+	})
+
+	err := AddTriggerTemplates(&AddTriggerTemplateOptions{PipelinesFolderPath: "/gitops", FromFile: "/tekton/go-ci-template.yaml"}, fs)
+	test.AssertErrorMatch(t, `failed to import TriggerTemplate "go-ci-template": unknown Pipeline "go-ci-pipeline"`, err)
+}
+
+func TestAddTriggerTemplatesWithGeneratedName(t *testing.T) {
+	manifest := testTemplatesManifest + `  apps:
+  - name: taxi
+    services:
+    - name: taxi-svc
+`
+	fs := afero.NewMemMapFs()
+	writeTestFiles(t, fs, map[string]string{
+		"/gitops/pipelines.yaml":                                    manifest,
+		"/gitops/config/cicd/base/04-pipelines/go-ci-pipeline.yaml": testGoPipeline,
+		"/tekton/go-ci-template.yaml":                               strings.Replace(testGoTemplate, "name: go-ci-template", "name: app-ci-template-taxi-svc", 1),
+	})
+
+	err := AddTriggerTemplates(&AddTriggerTemplateOptions{PipelinesFolderPath: "/gitops", FromFile: "/tekton/go-ci-template.yaml"}, fs)
+	test.AssertErrorMatch(t, `failed to import TriggerTemplate "app-ci-template-taxi-svc": the name is used by a resource generated by kam`, err)
+}
+
+func writeTestFiles(t *testing.T, fs afero.Fs, files map[string]string) {
+	t.Helper()
+	for path, body := range files {
+		if err := afero.WriteFile(fs, filepath.FromSlash(path), []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -11,8 +11,10 @@ var (
 	TriggerBindingTypeMeta = meta.TypeMeta("TriggerBinding", "triggers.tekton.dev/v1alpha1")
 )
 
-// CreateImageRepoBinding returns a TriggerBinding with the imageRepo.
-func CreateImageRepoBinding(ns, bindingName, imageRepo, tlsVerify string) triggersv1.TriggerBinding {
+// CreateImageRepoBinding returns a TriggerBinding with the imageRepo, the path
+// to the overlay in the GitOps repository where the image is deployed, and the
+// name of the service.
+func CreateImageRepoBinding(ns, bindingName, imageRepo, tlsVerify, overlayPath, serviceName string) triggersv1.TriggerBinding {
 	return triggersv1.TriggerBinding{
 		TypeMeta:   TriggerBindingTypeMeta,
 		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(ns, bindingName)),
@@ -20,6 +22,8 @@ func CreateImageRepoBinding(ns, bindingName, imageRepo, tlsVerify string) trigge
 			Params: []triggersv1.Param{
 				createBindingParam("imageRepo", imageRepo),
 				createBindingParam("tlsVerify", tlsVerify),
+				createBindingParam("overlayPath", overlayPath),
+				createBindingParam("serviceName", serviceName),
 			},
 		},
 	}
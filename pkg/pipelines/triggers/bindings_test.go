@@ -40,10 +40,18 @@ func TestCreateImageRepoBinding(t *testing.T) {
 					Name:  "tlsVerify",
 					Value: "true",
 				},
+				{
+					Name:  "overlayPath",
+					Value: "environments/dev/apps/app/services/svc/overlays",
+				},
+				{
+					Name:  "serviceName",
+					Value: "svc",
+				},
 			},
 		},
 	}
-	binding := CreateImageRepoBinding("testns", "test-binding", "quay.io/user/testing", "true")
+	binding := CreateImageRepoBinding("testns", "test-binding", "quay.io/user/testing", "true", "environments/dev/apps/app/services/svc/overlays", "svc")
 	if diff := cmp.Diff(imageRepoBinding, binding); diff != "" {
 		t.Fatalf("CreateImageRepoBinding() failed:\n%s", diff)
 	}
@@ -1,7 +1,6 @@
 package triggers
 
 import (
-	pipelinev1alpha1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -20,19 +19,26 @@ func createDevCDPipelineRun(saName string) pipelinev1.PipelineRun {
 		Spec: pipelinev1.PipelineRunSpec{
 			ServiceAccountName: saName,
 			PipelineRef:        createPipelineRef("app-cd-pipeline"),
-			Resources:          createDevResource("$(tt.params." + GitCommitID + ")"),
+			Params: []pipelinev1.Param{
+				createPipelineBindingParam("GIT_REPO", "$(tt.params.gitrepositoryurl)"),
+				createPipelineBindingParam("GIT_REF", "$(tt.params."+GitCommitID+")"),
+				createPipelineBindingParam("IMAGE", "$(tt.params.imageRepo):$(tt.params."+GitRef+")-$(tt.params."+GitCommitID+")"),
+			},
+			Workspaces: []pipelinev1.WorkspaceBinding{
+				createWorkspaceBinding("shared-data"),
+			},
 		},
 	}
 }
 
-func createDevCIPipelineRun(saName string) pipelinev1.PipelineRun {
+func createDevCIPipelineRun(saName, pipelineName string) pipelinev1.PipelineRun {
 	return pipelinev1.PipelineRun{
 		TypeMeta: pipelineRunTypeMeta,
 		ObjectMeta: meta.ObjectMeta(
 			meta.NamespacedName("", "app-ci-$(uid)")),
 		Spec: pipelinev1.PipelineRunSpec{
 			ServiceAccountName: saName,
-			PipelineRef:        createPipelineRef("app-ci-pipeline"),
+			PipelineRef:        createPipelineRef(pipelineName),
 			Params: []pipelinev1.Param{
 				createPipelineBindingParam("REPO", "$(tt.params.fullname)"),
 				createPipelineBindingParam("GIT_REPO", "$(tt.params.gitrepositoryurl)"),
@@ -44,24 +50,46 @@ func createDevCIPipelineRun(saName string) pipelinev1.PipelineRun {
 				createPipelineBindingParam("COMMIT_DATE", "$(tt.params."+GitCommitDate+")"),
 				createPipelineBindingParam("COMMIT_AUTHOR", "$(tt.params."+GitCommitAuthor+")"),
 				createPipelineBindingParam("COMMIT_MESSAGE", "$(tt.params."+GitCommitMessage+")"),
+				createPipelineBindingParam("OVERLAY_PATH", "$(tt.params.overlayPath)"),
+				createPipelineBindingParam("SERVICE", "$(tt.params.serviceName)"),
 			},
 			Workspaces: []pipelinev1.WorkspaceBinding{
-				{
-					Name: "shared-data",
-					VolumeClaimTemplate: &corev1.PersistentVolumeClaim{
-						Spec: corev1.PersistentVolumeClaimSpec{
-							AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
-							Resources: corev1.ResourceRequirements{
-								Requests: corev1.ResourceList{"storage": resource.MustParse("1Gi")},
-							},
-						},
-					},
-				},
+				createWorkspaceBinding("shared-data"),
 			},
 		},
 	}
 }
 
+func createAppCIPRPipelineRun(saName string) pipelinev1.PipelineRun {
+	return pipelinev1.PipelineRun{
+		TypeMeta: pipelineRunTypeMeta,
+		ObjectMeta: meta.ObjectMeta(
+			meta.NamespacedName("", "app-ci-pr-$(uid)")),
+		Spec: pipelinev1.PipelineRunSpec{
+			ServiceAccountName: saName,
+			PipelineRef:        createPipelineRef("app-ci-pr-pipeline"),
+			Params: []pipelinev1.Param{
+				createPipelineBindingParam("REPO", "$(tt.params.fullname)"),
+				createPipelineBindingParam("GIT_REPO", "$(tt.params.gitrepositoryurl)"),
+				createPipelineBindingParam("TLSVERIFY", "$(tt.params.tlsVerify)"),
+				createPipelineBindingParam("IMAGE", "$(tt.params.imageRepo):pr-$(tt.params."+GitCommitID+")"),
+				createPipelineBindingParam("COMMIT_SHA", "$(tt.params."+GitCommitID+")"),
+				createPipelineBindingParam("GIT_REF", "$(tt.params."+GitRef+")"),
+			},
+			Workspaces: []pipelinev1.WorkspaceBinding{
+				createWorkspaceBinding("shared-data"),
+			},
+		},
+	}
+}
+
+func createServiceCIPRPipelineRun(saName, pipelineName string) pipelinev1.PipelineRun {
+	pr := createAppCIPRPipelineRun(saName)
+	pr.Spec.PipelineRef = createPipelineRef(pipelineName)
+	pr.Spec.Params = append(pr.Spec.Params, createPipelineBindingParam("BASE_REF", "$(tt.params."+PullRequestBaseRef+")"))
+	return pr
+}
+
 func createCDPipelineRun(saName string) pipelinev1.PipelineRun {
 	return pipelinev1.PipelineRun{
 		TypeMeta:   pipelineRunTypeMeta,
@@ -69,7 +97,13 @@ func createCDPipelineRun(saName string) pipelinev1.PipelineRun {
 		Spec: pipelinev1.PipelineRunSpec{
 			ServiceAccountName: saName,
 			PipelineRef:        createPipelineRef("cd-deploy-from-push-pipeline"),
-			Resources:          createResources(),
+			Params: []pipelinev1.Param{
+				createPipelineBindingParam("GIT_REPO", "$(tt.params.gitrepositoryurl)"),
+				createPipelineBindingParam("COMMIT_SHA", "$(tt.params."+GitCommitID+")"),
+			},
+			Workspaces: []pipelinev1.WorkspaceBinding{
+				createWorkspaceBinding("shared-data"),
+			},
 		},
 	}
 }
@@ -82,52 +116,55 @@ func createCIPipelineRun(saName string) pipelinev1.PipelineRun {
 		Spec: pipelinev1.PipelineRunSpec{
 			ServiceAccountName: saName,
 			PipelineRef:        createPipelineRef("ci-dryrun-from-push-pipeline"),
-			Resources:          createResources(),
 			Params: []pipelinev1.Param{
 				createPipelineBindingParam("REPO", "$(tt.params.fullname)"),
 				createPipelineBindingParam("GIT_REPO", "$(tt.params.gitrepositoryurl)"),
-				createPipelineBindingParam("COMMIT_SHA", "$(tt.params.io.openshift.build.commit.id)"),
+				createPipelineBindingParam("COMMIT_SHA", "$(tt.params."+GitCommitID+")"),
+				createPipelineBindingParam("COMMIT_AUTHOR", "$(tt.params."+GitCommitAuthor+")"),
+			},
+			Workspaces: []pipelinev1.WorkspaceBinding{
+				createWorkspaceBinding("shared-data"),
 			},
 		},
 	}
 }
 
-func createDevResource(revision string) []pipelinev1.PipelineResourceBinding {
-	return []pipelinev1.PipelineResourceBinding{
-		{
-			Name: "source-repo",
-			ResourceSpec: &pipelinev1alpha1.PipelineResourceSpec{
-				Type: "git",
-				Params: []pipelinev1.ResourceParam{
-					createResourceParams("revision", revision),
-					createResourceParams("url", "$(tt.params.gitrepositoryurl)"),
-				},
+func createCIPRPipelineRun(saName string) pipelinev1.PipelineRun {
+	return pipelinev1.PipelineRun{
+		TypeMeta: pipelineRunTypeMeta,
+		ObjectMeta: meta.ObjectMeta(
+			meta.NamespacedName("", "ci-dryrun-from-pr-$(uid)")),
+		Spec: pipelinev1.PipelineRunSpec{
+			ServiceAccountName: saName,
+			PipelineRef:        createPipelineRef("ci-dryrun-from-pr-pipeline"),
+			Params: []pipelinev1.Param{
+				createPipelineBindingParam("REPO", "$(tt.params.fullname)"),
+				createPipelineBindingParam("GIT_REPO", "$(tt.params.gitrepositoryurl)"),
+				createPipelineBindingParam("COMMIT_SHA", "$(tt.params."+GitCommitID+")"),
+				createPipelineBindingParam("PULL_REQUEST", "$(tt.params."+PullRequestNumber+")"),
+				createPipelineBindingParam("COMMIT_AUTHOR", "$(tt.params."+GitCommitAuthor+")"),
+			},
+			Workspaces: []pipelinev1.WorkspaceBinding{
+				createWorkspaceBinding("shared-data"),
 			},
 		},
 	}
 }
 
-func createResources() []pipelinev1.PipelineResourceBinding {
-	return []pipelinev1.PipelineResourceBinding{
-		{
-			Name: "source-repo",
-			ResourceSpec: &pipelinev1alpha1.PipelineResourceSpec{
-				Type: "git",
-				Params: []pipelinev1.ResourceParam{
-					createResourceParams("revision", "$(tt.params."+GitCommitID+")"),
-					createResourceParams("url", "$(tt.params.gitrepositoryurl)"),
+func createWorkspaceBinding(name string) pipelinev1.WorkspaceBinding {
+	return pipelinev1.WorkspaceBinding{
+		Name: name,
+		VolumeClaimTemplate: &corev1.PersistentVolumeClaim{
+			Spec: corev1.PersistentVolumeClaimSpec{
+				AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{"storage": resource.MustParse("1Gi")},
 				},
 			},
 		},
 	}
 }
 
-func createResourceParams(name, value string) pipelinev1.ResourceParam {
-	return pipelinev1.ResourceParam{
-		Name:  name,
-		Value: value,
-	}
-}
 func createPipelineRef(name string) *pipelinev1.PipelineRef {
 	return &pipelinev1.PipelineRef{
 		Name: name,
@@ -4,7 +4,6 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
-	pipelinev1alpha1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	corev1 "k8s.io/api/core/v1"
@@ -24,7 +23,14 @@ func TestCreateDevCDPipelineRun(t *testing.T) {
 		Spec: pipelinev1.PipelineRunSpec{
 			ServiceAccountName: sName,
 			PipelineRef:        createPipelineRef("app-cd-pipeline"),
-			Resources:          createDevResource("$(tt.params.io.openshift.build.commit.id)"),
+			Params: []pipelinev1.Param{
+				createPipelineBindingParam("GIT_REPO", "$(tt.params.gitrepositoryurl)"),
+				createPipelineBindingParam("GIT_REF", "$(tt.params.io.openshift.build.commit.id)"),
+				createPipelineBindingParam("IMAGE", "$(tt.params.imageRepo):$(tt.params.io.openshift.build.commit.ref)-$(tt.params.io.openshift.build.commit.id)"),
+			},
+			Workspaces: []pipelinev1.WorkspaceBinding{
+				createWorkspaceBinding("shared-data"),
+			},
 		},
 	}
 	template := createDevCDPipelineRun(sName)
@@ -65,15 +71,79 @@ func TestCreateDevCIPipelineRun(t *testing.T) {
 				createPipelineBindingParam("COMMIT_DATE", "$(tt.params.io.openshift.build.commit.date)"),
 				createPipelineBindingParam("COMMIT_AUTHOR", "$(tt.params.io.openshift.build.commit.author)"),
 				createPipelineBindingParam("COMMIT_MESSAGE", "$(tt.params.io.openshift.build.commit.message)"),
+				createPipelineBindingParam("OVERLAY_PATH", "$(tt.params.overlayPath)"),
+				createPipelineBindingParam("SERVICE", "$(tt.params.serviceName)"),
 			},
 		},
 	}
-	template := createDevCIPipelineRun(sName)
+	template := createDevCIPipelineRun(sName, "app-ci-pipeline")
 	if diff := cmp.Diff(want, template); diff != "" {
 		t.Fatalf("createDevCIPipelineRun failed:\n%s", diff)
 	}
 }
 
+func TestCreateAppCIPRPipelineRun(t *testing.T) {
+	want := pipelinev1.PipelineRun{
+		TypeMeta: pipelineRunTypeMeta,
+		ObjectMeta: meta.ObjectMeta(
+			meta.NamespacedName("", "app-ci-pr-$(uid)")),
+		Spec: pipelinev1.PipelineRunSpec{
+			ServiceAccountName: sName,
+			PipelineRef:        createPipelineRef("app-ci-pr-pipeline"),
+			Workspaces: []pipelinev1.WorkspaceBinding{
+				{
+					Name: "shared-data",
+					VolumeClaimTemplate: &corev1.PersistentVolumeClaim{
+						Spec: corev1.PersistentVolumeClaimSpec{
+							AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{"storage": resource.MustParse("1Gi")},
+							},
+						},
+					},
+				},
+			},
+			Params: []pipelinev1.Param{
+				createPipelineBindingParam("REPO", "$(tt.params.fullname)"),
+				createPipelineBindingParam("GIT_REPO", "$(tt.params.gitrepositoryurl)"),
+				createPipelineBindingParam("TLSVERIFY", "$(tt.params.tlsVerify)"),
+				createPipelineBindingParam("IMAGE", "$(tt.params.imageRepo):pr-$(tt.params.io.openshift.build.commit.id)"),
+				createPipelineBindingParam("COMMIT_SHA", "$(tt.params.io.openshift.build.commit.id)"),
+				createPipelineBindingParam("GIT_REF", "$(tt.params.io.openshift.build.commit.ref)"),
+			},
+		},
+	}
+	template := createAppCIPRPipelineRun(sName)
+	if diff := cmp.Diff(want, template); diff != "" {
+		t.Fatalf("createAppCIPRPipelineRun failed:\n%s", diff)
+	}
+}
+
+func TestCreateCIPRPipelineRun(t *testing.T) {
+	want := pipelinev1.PipelineRun{
+		TypeMeta:   pipelineRunTypeMeta,
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName("", "ci-dryrun-from-pr-$(uid)")),
+		Spec: pipelinev1.PipelineRunSpec{
+			ServiceAccountName: sName,
+			PipelineRef:        createPipelineRef("ci-dryrun-from-pr-pipeline"),
+			Workspaces: []pipelinev1.WorkspaceBinding{
+				createWorkspaceBinding("shared-data"),
+			},
+			Params: []pipelinev1.Param{
+				createPipelineBindingParam("REPO", "$(tt.params.fullname)"),
+				createPipelineBindingParam("GIT_REPO", "$(tt.params.gitrepositoryurl)"),
+				createPipelineBindingParam("COMMIT_SHA", "$(tt.params.io.openshift.build.commit.id)"),
+				createPipelineBindingParam("PULL_REQUEST", "$(tt.params.pullrequestnumber)"),
+				createPipelineBindingParam("COMMIT_AUTHOR", "$(tt.params.io.openshift.build.commit.author)"),
+			},
+		},
+	}
+	template := createCIPRPipelineRun(sName)
+	if diff := cmp.Diff(want, template); diff != "" {
+		t.Fatalf("createCIPRPipelineRun failed:\n%s", diff)
+	}
+}
+
 func TestCreateCDPipelineRun(t *testing.T) {
 	want := pipelinev1.PipelineRun{
 		TypeMeta:   pipelineRunTypeMeta,
@@ -81,7 +151,13 @@ func TestCreateCDPipelineRun(t *testing.T) {
 		Spec: pipelinev1.PipelineRunSpec{
 			ServiceAccountName: sName,
 			PipelineRef:        createPipelineRef("cd-deploy-from-push-pipeline"),
-			Resources:          createResources(),
+			Params: []pipelinev1.Param{
+				createPipelineBindingParam("GIT_REPO", "$(tt.params.gitrepositoryurl)"),
+				createPipelineBindingParam("COMMIT_SHA", "$(tt.params.io.openshift.build.commit.id)"),
+			},
+			Workspaces: []pipelinev1.WorkspaceBinding{
+				createWorkspaceBinding("shared-data"),
+			},
 		},
 	}
 	template := createCDPipelineRun(sName)
@@ -98,11 +174,14 @@ func TestCreateStageCIPipelineRun(t *testing.T) {
 		Spec: pipelinev1.PipelineRunSpec{
 			ServiceAccountName: sName,
 			PipelineRef:        createPipelineRef("ci-dryrun-from-push-pipeline"),
-			Resources:          createResources(),
+			Workspaces: []pipelinev1.WorkspaceBinding{
+				createWorkspaceBinding("shared-data"),
+			},
 			Params: []v1beta1.Param{
 				createPipelineBindingParam("REPO", "$(tt.params.fullname)"),
 				createPipelineBindingParam("GIT_REPO", "$(tt.params.gitrepositoryurl)"),
 				createPipelineBindingParam("COMMIT_SHA", "$(tt.params.io.openshift.build.commit.id)"),
+				createPipelineBindingParam("COMMIT_AUTHOR", "$(tt.params.io.openshift.build.commit.author)"),
 			},
 		},
 	}
@@ -111,22 +190,3 @@ func TestCreateStageCIPipelineRun(t *testing.T) {
 		t.Fatalf("createCIPipelineRun failed:\n%s", diff)
 	}
 }
-
-func TestCreateDevResource(t *testing.T) {
-	want := []pipelinev1.PipelineResourceBinding{
-		{
-			Name: "source-repo",
-			ResourceSpec: &pipelinev1alpha1.PipelineResourceSpec{
-				Type: "git",
-				Params: []pipelinev1.ResourceParam{
-					createResourceParams("revision", "test"),
-					createResourceParams("url", "$(tt.params.gitrepositoryurl)"),
-				},
-			},
-		},
-	}
-	got := createDevResource("test")
-	if diff := cmp.Diff(got, want); diff != "" {
-		t.Fatalf("createDevResource() failed: \n%s", diff)
-	}
-}
@@ -2,6 +2,7 @@ package triggers
 
 import (
 	"encoding/json"
+	"fmt"
 
 	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -26,15 +27,35 @@ const (
 	// GitCommitDate is a label representing the commit timestamp for this
 	// build.
 	GitCommitDate = "io.openshift.build.commit.date"
+	// PullRequestNumber is the number of the pull request that triggered the
+	// build.
+	PullRequestNumber = "pullrequestnumber"
+	// PullRequestBaseRef is the branch that the pull request that triggered
+	// the build is to be merged into.
+	PullRequestBaseRef = "pullrequestbaseref"
 )
 
+// ServicePushTriggerName returns the name of the EventListener trigger that
+// starts the integration pipeline of a service for pushes.
+func ServicePushTriggerName(svc string) string {
+	return fmt.Sprintf("app-ci-build-from-push-%s", svc)
+}
+
+// ServicePRTriggerName returns the name of the EventListener trigger that
+// starts the pipeline of a service for pull requests.
+func ServicePRTriggerName(svc string) string {
+	return fmt.Sprintf("app-ci-build-from-pr-%s", svc)
+}
+
 // GenerateTemplates will return a slice of trigger templates
 func GenerateTemplates(ns, saName string) []triggersv1.TriggerTemplate {
 	return []triggersv1.TriggerTemplate{
 		CreateDevCDDeployTemplate(ns, saName),
 		CreateDevCIBuildPRTemplate(ns, saName),
+		CreateAppCIPRTemplate(ns, saName),
 		CreateCDPushTemplate(ns, saName),
 		CreateCIDryRunTemplate(ns, saName),
+		CreateCIDryRunPRTemplate(ns, saName),
 	}
 }
 
@@ -46,7 +67,9 @@ func CreateDevCDDeployTemplate(ns, saName string) triggersv1.TriggerTemplate {
 		Spec: triggersv1.TriggerTemplateSpec{
 			Params: []triggersv1.ParamSpec{
 				createTemplateParamSpec(GitCommitID, "The specific commit SHA."),
+				createTemplateParamSpecDefault(GitRef, "The git revision", "master"),
 				createTemplateParamSpec("gitrepositoryurl", "The git repository url"),
+				createTemplateParamSpec("imageRepo", "The image repository url"),
 			},
 			ResourceTemplates: []triggersv1.TriggerResourceTemplate{
 				{
@@ -61,10 +84,17 @@ func CreateDevCDDeployTemplate(ns, saName string) triggersv1.TriggerTemplate {
 
 // CreateDevCIBuildPRTemplate creates DevCIBuildPRTemplate
 func CreateDevCIBuildPRTemplate(ns, saName string) triggersv1.TriggerTemplate {
+	return CreateServiceCITemplate(ns, saName, "app-ci-template", "app-ci-pipeline")
+}
+
+// CreateServiceCITemplate creates a TriggerTemplate with the same parameters
+// as the app-ci-template, that runs the named pipeline, this is used for
+// services that configure their own build.
+func CreateServiceCITemplate(ns, saName, name, pipelineName string) triggersv1.TriggerTemplate {
 	return triggersv1.TriggerTemplate{
 		TypeMeta: triggerTemplateTypeMeta,
 		ObjectMeta: meta.ObjectMeta(
-			meta.NamespacedName(ns, "app-ci-template")),
+			meta.NamespacedName(ns, name)),
 		Spec: triggersv1.TriggerTemplateSpec{
 			Params: []triggersv1.ParamSpec{
 				createTemplateParamSpec(GitRef, "The git branch for this PR."),
@@ -77,11 +107,70 @@ func CreateDevCIBuildPRTemplate(ns, saName string) triggersv1.TriggerTemplate {
 				createTemplateParamSpec("imageRepo", "The repository to push built images to."),
 				createTemplateParamSpec("tlsVerify", "Enable image repository TLS certification verification."),
 				createTemplateParamSpec("build_extra_args", "Extra parameters passed for the push command when pushing images."),
+				createTemplateParamSpecDefault("overlayPath", "The path to the service's overlay in the GitOps repository.", ""),
+				createTemplateParamSpecDefault("serviceName", "The name of the service that the image is built for.", ""),
+			},
+			ResourceTemplates: []triggersv1.TriggerResourceTemplate{
+				{
+					RawExtension: runtime.RawExtension{
+						Raw: createDevCIResourceTemplate(saName, pipelineName),
+					},
+				},
+			},
+		},
+	}
+}
+
+// CreateAppCIPRTemplate creates the TriggerTemplate that builds pull requests
+// for application services.
+func CreateAppCIPRTemplate(ns, saName string) triggersv1.TriggerTemplate {
+	return triggersv1.TriggerTemplate{
+		TypeMeta: triggerTemplateTypeMeta,
+		ObjectMeta: meta.ObjectMeta(
+			meta.NamespacedName(ns, "app-ci-pr-template")),
+		Spec: triggersv1.TriggerTemplateSpec{
+			Params: []triggersv1.ParamSpec{
+				createTemplateParamSpec(GitRef, "The git branch for this PR."),
+				createTemplateParamSpec(GitCommitID, "the specific commit SHA."),
+				createTemplateParamSpec("gitrepositoryurl", "The git repository URL."),
+				createTemplateParamSpec("fullname", "The repository name for this PullRequest."),
+				createTemplateParamSpec("imageRepo", "The repository to name built images with."),
+				createTemplateParamSpec("tlsVerify", "Enable image repository TLS certification verification."),
 			},
 			ResourceTemplates: []triggersv1.TriggerResourceTemplate{
 				{
 					RawExtension: runtime.RawExtension{
-						Raw: createDevCIResourceTemplate(saName),
+						Raw: createAppCIPRResourceTemplate(saName),
+					},
+				},
+			},
+		},
+	}
+}
+
+// CreateServiceCIPRTemplate creates a TriggerTemplate with the parameters of
+// the app-ci-pr-template, and the base branch of the pull request, that runs
+// the named pipeline, this is used for services that configure their own
+// build.
+func CreateServiceCIPRTemplate(ns, saName, name, pipelineName string) triggersv1.TriggerTemplate {
+	return triggersv1.TriggerTemplate{
+		TypeMeta: triggerTemplateTypeMeta,
+		ObjectMeta: meta.ObjectMeta(
+			meta.NamespacedName(ns, name)),
+		Spec: triggersv1.TriggerTemplateSpec{
+			Params: []triggersv1.ParamSpec{
+				createTemplateParamSpec(GitRef, "The git branch for this PR."),
+				createTemplateParamSpec(GitCommitID, "the specific commit SHA."),
+				createTemplateParamSpec("gitrepositoryurl", "The git repository URL."),
+				createTemplateParamSpec("fullname", "The repository name for this PullRequest."),
+				createTemplateParamSpec("imageRepo", "The repository to name built images with."),
+				createTemplateParamSpec("tlsVerify", "Enable image repository TLS certification verification."),
+				createTemplateParamSpecDefault(PullRequestBaseRef, "The branch that the PullRequest is to be merged into.", ""),
+			},
+			ResourceTemplates: []triggersv1.TriggerResourceTemplate{
+				{
+					RawExtension: runtime.RawExtension{
+						Raw: createServiceCIPRResourceTemplate(saName, pipelineName),
 					},
 				},
 			},
@@ -96,8 +185,8 @@ func CreateCDPushTemplate(ns, saName string) triggersv1.TriggerTemplate {
 		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(ns, "cd-deploy-from-push-template")),
 		Spec: triggersv1.TriggerTemplateSpec{
 			Params: []triggersv1.ParamSpec{
-
 				createTemplateParamSpecDefault(GitRef, "The git revision", "master"),
+				createTemplateParamSpec(GitCommitID, "The specific commit SHA"),
 				createTemplateParamSpec(GitCommitDate, "The date at which the commit was made"),
 				createTemplateParamSpec(GitCommitAuthor, "The name of the github user handle that made the commit"),
 				createTemplateParamSpec(GitCommitMessage, "The commit message"),
@@ -125,6 +214,7 @@ func CreateCIDryRunTemplate(ns, saName string) triggersv1.TriggerTemplate {
 				createTemplateParamSpec(GitCommitID, "The specific commit SHA"),
 				createTemplateParamSpec("gitrepositoryurl", "The git repository url"),
 				createTemplateParamSpec("fullname", "The repository name for this PullRequest"),
+				createTemplateParamSpecDefault(GitCommitAuthor, "The name of the user that made the commit", ""),
 			},
 			ResourceTemplates: []triggersv1.TriggerResourceTemplate{
 				{
@@ -137,6 +227,31 @@ func CreateCIDryRunTemplate(ns, saName string) triggersv1.TriggerTemplate {
 	}
 }
 
+// CreateCIDryRunPRTemplate returns the TriggerTemplate that dry-runs pull
+// requests to the GitOps repository.
+func CreateCIDryRunPRTemplate(ns, saName string) triggersv1.TriggerTemplate {
+	return triggersv1.TriggerTemplate{
+		TypeMeta:   triggerTemplateTypeMeta,
+		ObjectMeta: meta.ObjectMeta(meta.NamespacedName(ns, "ci-dryrun-from-pr-template")),
+		Spec: triggersv1.TriggerTemplateSpec{
+			Params: []triggersv1.ParamSpec{
+				createTemplateParamSpec(GitCommitID, "The specific commit SHA"),
+				createTemplateParamSpec("gitrepositoryurl", "The git repository url"),
+				createTemplateParamSpec("fullname", "The repository name for this PullRequest"),
+				createTemplateParamSpec(PullRequestNumber, "The number of the PullRequest"),
+				createTemplateParamSpecDefault(GitCommitAuthor, "The name of the user that made the commit", ""),
+			},
+			ResourceTemplates: []triggersv1.TriggerResourceTemplate{
+				{
+					RawExtension: runtime.RawExtension{
+						Raw: createCIPRResourceTemplate(saName),
+					},
+				},
+			},
+		},
+	}
+}
+
 func createTemplateParamSpecDefault(name, description, value string) triggersv1.ParamSpec {
 	return triggersv1.ParamSpec{
 		Name:        name,
@@ -157,11 +272,26 @@ func createDevCDResourceTemplate(saName string) []byte {
 	return byteTemplate
 }
 
-func createDevCIResourceTemplate(saName string) []byte {
-	byteTemplateCI, _ := json.Marshal(createDevCIPipelineRun(saName))
+func createDevCIResourceTemplate(saName, pipelineName string) []byte {
+	byteTemplateCI, _ := json.Marshal(createDevCIPipelineRun(saName, pipelineName))
 	return byteTemplateCI
 }
 
+func createCIPRResourceTemplate(saName string) []byte {
+	byteTemplate, _ := json.Marshal(createCIPRPipelineRun(saName))
+	return byteTemplate
+}
+
+func createAppCIPRResourceTemplate(saName string) []byte {
+	byteTemplate, _ := json.Marshal(createAppCIPRPipelineRun(saName))
+	return byteTemplate
+}
+
+func createServiceCIPRResourceTemplate(saName, pipelineName string) []byte {
+	byteTemplate, _ := json.Marshal(createServiceCIPRPipelineRun(saName, pipelineName))
+	return byteTemplate
+}
+
 func createCDResourceTemplate(saName string) []byte {
 	byteStageCD, _ := json.Marshal(createCDPipelineRun(saName))
 	return byteStageCD
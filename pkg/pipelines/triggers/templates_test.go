@@ -24,10 +24,19 @@ func TestCreateDevCDDeployTemplate(t *testing.T) {
 					Name:        GitCommitID,
 					Description: "The specific commit SHA.",
 				},
+				{
+					Name:        GitRef,
+					Description: "The git revision",
+					Default:     strPtr("master"),
+				},
 				{
 					Name:        "gitrepositoryurl",
 					Description: "The git repository url",
 				},
+				{
+					Name:        "imageRepo",
+					Description: "The image repository url",
+				},
 			},
 
 			ResourceTemplates: []triggersv1.TriggerResourceTemplate{
@@ -92,11 +101,21 @@ func TestCreateDevCIBuildPRTemplate(t *testing.T) {
 					Name:        "build_extra_args",
 					Description: "Extra parameters passed for the push command when pushing images.",
 				},
+				{
+					Name:        "overlayPath",
+					Description: "The path to the service's overlay in the GitOps repository.",
+					Default:     strPtr(""),
+				},
+				{
+					Name:        "serviceName",
+					Description: "The name of the service that the image is built for.",
+					Default:     strPtr(""),
+				},
 			},
 			ResourceTemplates: []triggersv1.TriggerResourceTemplate{
 				{
 					RawExtension: runtime.RawExtension{
-						Raw: createDevCIResourceTemplate(serviceAccName),
+						Raw: createDevCIResourceTemplate(serviceAccName, "app-ci-pipeline"),
 					},
 				},
 			},
@@ -108,6 +127,36 @@ func TestCreateDevCIBuildPRTemplate(t *testing.T) {
 	}
 }
 
+func TestCreateServiceCITemplate(t *testing.T) {
+	template := CreateServiceCITemplate("testns", serviceAccName, "app-ci-template-http-api", "app-ci-pipeline-http-api")
+
+	want := CreateDevCIBuildPRTemplate("testns", serviceAccName)
+	want.Name = "app-ci-template-http-api"
+	want.Spec.ResourceTemplates[0].RawExtension.Raw = createDevCIResourceTemplate(serviceAccName, "app-ci-pipeline-http-api")
+	if diff := cmp.Diff(want, template); diff != "" {
+		t.Fatalf("CreateServiceCITemplate failed:\n%s", diff)
+	}
+}
+
+func TestCreateServiceCIPRTemplate(t *testing.T) {
+	template := CreateServiceCIPRTemplate("testns", serviceAccName, "app-ci-pr-template-http-api", "app-ci-pr-pipeline-http-api")
+
+	want := CreateAppCIPRTemplate("testns", serviceAccName)
+	want.Name = "app-ci-pr-template-http-api"
+	want.Spec.Params = append(want.Spec.Params, createTemplateParamSpecDefault(PullRequestBaseRef, "The branch that the PullRequest is to be merged into.", ""))
+	want.Spec.ResourceTemplates[0].RawExtension.Raw = createServiceCIPRResourceTemplate(serviceAccName, "app-ci-pr-pipeline-http-api")
+	if diff := cmp.Diff(want, template); diff != "" {
+		t.Fatalf("CreateServiceCIPRTemplate failed:\n%s", diff)
+	}
+	pr := createServiceCIPRPipelineRun(serviceAccName, "app-ci-pr-pipeline-http-api")
+	if pr.Spec.PipelineRef.Name != "app-ci-pr-pipeline-http-api" {
+		t.Fatalf("got pipeline %q, want %q", pr.Spec.PipelineRef.Name, "app-ci-pr-pipeline-http-api")
+	}
+	if diff := cmp.Diff(createPipelineBindingParam("BASE_REF", "$(tt.params.pullrequestbaseref)"), pr.Spec.Params[len(pr.Spec.Params)-1]); diff != "" {
+		t.Fatalf("base ref param failed:\n%s", diff)
+	}
+}
+
 func TestCreateCDPushTemplate(t *testing.T) {
 	ValidStageCDPushTemplate := triggersv1.TriggerTemplate{
 		TypeMeta:   triggerTemplateTypeMeta,
@@ -119,6 +168,10 @@ func TestCreateCDPushTemplate(t *testing.T) {
 					Description: "The git revision",
 					Default:     strPtr("master"),
 				},
+				{
+					Name:        GitCommitID,
+					Description: "The specific commit SHA",
+				},
 				{
 					Name:        GitCommitDate,
 					Description: "The date at which the commit was made",
@@ -162,6 +215,7 @@ func TestCreateCIDryRunTemplate(t *testing.T) {
 				{Name: "io.openshift.build.commit.id", Description: "The specific commit SHA"},
 				{Name: "gitrepositoryurl", Description: "The git repository url"},
 				{Name: "fullname", Description: "The repository name for this PullRequest"},
+				{Name: GitCommitAuthor, Description: "The name of the user that made the commit", Default: strPtr("")},
 			},
 			ResourceTemplates: []triggersv1.TriggerResourceTemplate{
 				{
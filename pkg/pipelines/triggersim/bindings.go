@@ -0,0 +1,129 @@
+package triggersim
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/textproto"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// jsonRegexp matches the relaxed JSONPath expressions that Triggers accepts
+// in bindings, with or without the enclosing {} and the leading ".".
+var jsonRegexp = regexp.MustCompile(`^\{\.?([^{}]+)\}$|^\.?([^{}]+)$`)
+
+// bindingEvent is the event that binding params are resolved against, the
+// headers are joined in the same way as Triggers does.
+func bindingEvent(event *Event, extensions map[string]interface{}) (interface{}, error) {
+	header := map[string]string{}
+	for k, v := range event.Header {
+		header[k] = strings.Join(v, ",")
+	}
+	b, err := json.Marshal(map[string]interface{}{
+		"body":       event.body,
+		"header":     header,
+		"extensions": extensions,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var parsed interface{}
+	return parsed, json.Unmarshal(b, &parsed)
+}
+
+// resolveParam replaces the $() expressions in value with the values from
+// the event.
+func resolveParam(event interface{}, value string) (string, error) {
+	for _, expr := range findExpressions(value) {
+		path := expr
+		if strings.HasPrefix(path, "header.") {
+			path = "header." + textproto.CanonicalMIMEHeaderKey(strings.TrimPrefix(path, "header."))
+		}
+		resolved, err := parseJSONPath(event, path)
+		if err != nil {
+			return "", fmt.Errorf("failed to replace JSONPath value %s: %w", expr, err)
+		}
+		value = strings.ReplaceAll(value, "$("+expr+")", resolved)
+	}
+	return value, nil
+}
+
+// findExpressions returns the expressions in value that are wrapped in $(),
+// expressions can contain balanced parentheses.
+func findExpressions(value string) []string {
+	exprs := []string{}
+	parts := strings.Split(value, "$(")
+	for _, p := range parts[1:] {
+		open := 0
+		for i, ch := range p {
+			if ch == '(' {
+				open++
+			}
+			if ch == ')' {
+				if open == 0 {
+					exprs = append(exprs, p[:i])
+					break
+				}
+				open--
+			}
+		}
+	}
+	return exprs
+}
+
+func parseJSONPath(event interface{}, expr string) (string, error) {
+	submatches := jsonRegexp.FindStringSubmatch(expr)
+	if submatches == nil {
+		return "", fmt.Errorf("unexpected path string %q", expr)
+	}
+	field := submatches[1]
+	if field == "" {
+		field = submatches[2]
+	}
+	j := jsonpath.New("").AllowMissingKeys(false)
+	if err := j.Parse(fmt.Sprintf("{.%s}", field)); err != nil {
+		return "", err
+	}
+	results, err := j.FindResults(event)
+	if err != nil {
+		return "", err
+	}
+	buf := &bytes.Buffer{}
+	for _, r := range results {
+		b, err := jsonResult(r)
+		if err != nil {
+			return "", err
+		}
+		buf.Write(b)
+	}
+	return buf.String(), nil
+}
+
+// jsonResult formats the values in the same way as Triggers, a single string
+// is not quoted, and several values are a JSON array.
+func jsonResult(values []reflect.Value) ([]byte, error) {
+	if len(values) == 1 {
+		v := values[0].Interface()
+		switch s := v.(type) {
+		case nil:
+			return []byte("null"), nil
+		case string:
+			b, err := json.Marshal(s)
+			if err != nil {
+				return nil, err
+			}
+			return b[1 : len(b)-1], nil
+		default:
+			return json.Marshal(v)
+		}
+	}
+	results := []interface{}{}
+	for _, v := range values {
+		results = append(results, v.Interface())
+	}
+	return json.Marshal(results)
+}
@@ -0,0 +1,53 @@
+package triggersim
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestResolveParam(t *testing.T) {
+	event, err := NewEvent([]byte(`{"ref":"refs/heads/main","commits":[{"id":"abc"},{"id":"def"}],"repository":{"name":"test","private":false},"message":"a \"quoted\" message"}`),
+		http.Header{"X-Github-Event": []string{"push"}}, "")
+	assertNoError(t, err)
+	resolved, err := bindingEvent(event, map[string]interface{}{"ref": "main"})
+	assertNoError(t, err)
+
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"$(body.ref)", "refs/heads/main"},
+		{"$(body.commits[0].id)", "abc"},
+		{"$(body.commits[*].id)", `["abc","def"]`},
+		{"$(body.repository)", `{"name":"test","private":false}`},
+		{"$(body.repository.private)", "false"},
+		{"$(body.message)", `a \"quoted\" message`},
+		{"$(header.X-GitHub-Event)", "push"},
+		{"$(header.x-github-event)", "push"},
+		{"$(extensions.ref)", "main"},
+		{"$(body.repository.name)-$(extensions.ref)", "test-main"},
+		{"static", "static"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(rt *testing.T) {
+			got, err := resolveParam(resolved, tt.value)
+			assertNoError(rt, err)
+			if got != tt.want {
+				rt.Fatalf("resolveParam(%q) got %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveParamWithMissingField(t *testing.T) {
+	event, err := NewEvent([]byte(`{"ref":"refs/heads/main"}`), http.Header{}, "")
+	assertNoError(t, err)
+	resolved, err := bindingEvent(event, nil)
+	assertNoError(t, err)
+
+	_, err = resolveParam(resolved, "$(body.head_commit.id)")
+	if err == nil {
+		t.Fatal("expected an error for a missing field")
+	}
+}
@@ -0,0 +1,79 @@
+package triggersim
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/ext"
+	triggerscel "github.com/tektoncd/triggers/pkg/interceptors/cel"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	corev1lister "k8s.io/client-go/listers/core/v1"
+)
+
+// The environment has the same variables as the Triggers CEL interceptor, and
+// the functions from its library, compareSecret looks up the secrets in the
+// namespace ns with secrets.
+func newCELEnv(ns string, secrets corev1lister.SecretLister) (*cel.Env, error) {
+	mapStrDyn := decls.NewMapType(decls.String, decls.Dyn)
+	return cel.NewEnv(
+		triggerscel.Triggers(ns, secrets),
+		ext.Strings(),
+		ext.Encoders(),
+		cel.Declarations(
+			decls.NewVar("body", mapStrDyn),
+			decls.NewVar("header", mapStrDyn),
+			decls.NewVar("extensions", mapStrDyn),
+			decls.NewVar("requestURL", decls.String),
+		))
+}
+
+// evaluate parses, checks and evaluates expr with the variables in data.
+func evaluate(env *cel.Env, expr string, data map[string]interface{}) (ref.Val, error) {
+	parsed, issues := env.Parse(expr)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("failed to parse expression %#v: %w", expr, issues.Err())
+	}
+	checked, issues := env.Check(parsed)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("expression %#v check failed: %w", expr, issues.Err())
+	}
+	prg, err := env.Program(checked)
+	if err != nil {
+		return nil, fmt.Errorf("expression %#v failed to create a Program: %w", expr, err)
+	}
+	out, _, err := prg.Eval(data)
+	if err != nil {
+		return nil, fmt.Errorf("expression %#v failed to evaluate: %w", expr, err)
+	}
+	return out, nil
+}
+
+// toJSONValue converts the result of an overlay expression to the value that
+// the Triggers CEL interceptor adds to the extensions.
+func toJSONValue(val ref.Val) (interface{}, error) {
+	raw, err := val.ConvertToNative(reflect.TypeOf(&structpb.Value{}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert overlay result to type: %w", err)
+	}
+	return raw.(*structpb.Value).AsInterface(), nil
+}
+
+// setExtension sets the value of a dotted key, creating the intermediate
+// objects as needed.
+func setExtension(extensions map[string]interface{}, key string, value interface{}) {
+	parts := strings.Split(key, ".")
+	m := extensions
+	for _, p := range parts[:len(parts)-1] {
+		next, ok := m[p].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			m[p] = next
+		}
+		m = next
+	}
+	m[parts[len(parts)-1]] = value
+}
@@ -0,0 +1,43 @@
+package triggersim
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	corev1lister "k8s.io/client-go/listers/core/v1"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/eventlisteners"
+)
+
+// NewWebhookSecretLister returns a SecretLister for the CEL interceptors, the
+// secrets in the cluster can't be read, so every secret has the webhook secret
+// value, in the key of the generated webhook secrets.
+//
+// If the value is empty, no secrets are found.
+func NewWebhookSecretLister(value string) corev1lister.SecretLister {
+	return webhookSecrets{value: value}
+}
+
+type webhookSecrets struct {
+	namespace string
+	value     string
+}
+
+func (s webhookSecrets) List(selector labels.Selector) ([]*corev1.Secret, error) {
+	return nil, nil
+}
+
+func (s webhookSecrets) Secrets(namespace string) corev1lister.SecretNamespaceLister {
+	return webhookSecrets{namespace: namespace, value: s.value}
+}
+
+func (s webhookSecrets) Get(name string) (*corev1.Secret, error) {
+	if s.value == "" {
+		return nil, apierrors.NewNotFound(corev1.Resource("secrets"), name)
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: s.namespace},
+		Data:       map[string][]byte{eventlisteners.WebhookSecretKey: []byte(s.value)},
+	}, nil
+}
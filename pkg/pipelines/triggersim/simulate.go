@@ -0,0 +1,271 @@
+package triggersim
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	corev1lister "k8s.io/client-go/listers/core/v1"
+)
+
+// The headers that the Git host interceptors check the event type of.
+var eventTypeHeaders = map[string]string{
+	"github":    "X-GitHub-Event",
+	"gitlab":    "X-Gitlab-Event",
+	"bitbucket": "X-Event-Key",
+}
+
+// Event is a webhook event that is sent to the EventListener.
+type Event struct {
+	Header http.Header
+	URL    string
+	body   map[string]interface{}
+}
+
+// NewEvent parses the JSON body of an event, the header names are
+// canonicalized, as they are in the requests that the EventListener receives.
+func NewEvent(body []byte, header http.Header, url string) (*Event, error) {
+	parsed := map[string]interface{}{}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &parsed); err != nil {
+			return nil, fmt.Errorf("failed to parse the body as JSON: %w", err)
+		}
+	}
+	canonical := http.Header{}
+	for k, v := range header {
+		for _, s := range v {
+			canonical.Add(k, s)
+		}
+	}
+	return &Event{Header: canonical, URL: url, body: parsed}, nil
+}
+
+// Result is the outcome of evaluating a trigger of an EventListener for an
+// event.
+type Result struct {
+	Trigger string `json:"trigger"`
+	Fired   bool   `json:"fired"`
+	// Reason is why the trigger did not fire.
+	Reason string `json:"reason,omitempty"`
+	// Unverified are the checks of the interceptors that can't be done
+	// without the cluster, e.g. the webhook secret of the GitHub interceptor.
+	Unverified []string               `json:"unverified,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+	Bindings   []*Binding             `json:"bindings,omitempty"`
+}
+
+// Binding is a TriggerBinding of a trigger that fired, with the resolved
+// values of its params.
+//
+// Params that are declared inline in the trigger have no binding name.
+type Binding struct {
+	Name   string   `json:"name,omitempty"`
+	Params []*Param `json:"params,omitempty"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// Param is a TriggerBinding param, and the value that it resolves to.
+type Param struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	Error string `json:"error,omitempty"`
+}
+
+// Simulate evaluates the interceptors of each trigger in the EventListener
+// against the event, in the same way as the EventListener would, and resolves
+// the params of the bindings of the triggers that fire.
+//
+// The bindings are the TriggerBindings that the triggers refer to, by name,
+// and the secrets are compared by the CEL interceptors, in the namespace of
+// the EventListener.
+func Simulate(el *triggersv1.EventListener, bindings map[string]*triggersv1.TriggerBinding, event *Event, secrets corev1lister.SecretLister) ([]*Result, error) {
+	env, err := newCELEnv(el.Namespace, secrets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the CEL environment: %w", err)
+	}
+	results := []*Result{}
+	for _, t := range el.Spec.Triggers {
+		results = append(results, simulateTrigger(env, t, bindings, event))
+	}
+	return results, nil
+}
+
+func simulateTrigger(env *cel.Env, t triggersv1.EventListenerTrigger, bindings map[string]*triggersv1.TriggerBinding, event *Event) *Result {
+	r := &Result{Trigger: t.Name}
+	if t.TriggerRef != "" {
+		r.Reason = fmt.Sprintf("references Trigger %s, which is not supported", t.TriggerRef)
+		return r
+	}
+	extensions := map[string]interface{}{}
+	for _, i := range t.Interceptors {
+		ic, err := parseInterceptor(i)
+		if err != nil {
+			r.Reason = err.Error()
+			return r
+		}
+		if ic.unsupported {
+			r.Unverified = append(r.Unverified, fmt.Sprintf("interceptor %s is not evaluated", ic.name))
+			continue
+		}
+		if ic.secret {
+			r.Unverified = append(r.Unverified, fmt.Sprintf("%s: the webhook secret is not verified", ic.name))
+		}
+		if err := ic.intercept(env, event, extensions); err != nil {
+			r.Reason = err.Error()
+			return r
+		}
+	}
+	r.Fired = true
+	if len(extensions) > 0 {
+		r.Extensions = extensions
+	}
+	resolved, err := bindingEvent(event, extensions)
+	if err != nil {
+		r.Reason = fmt.Sprintf("failed to create the event for the bindings: %v", err)
+		return r
+	}
+	for _, b := range t.Bindings {
+		r.Bindings = append(r.Bindings, resolveBinding(b, bindings, resolved))
+	}
+	return r
+}
+
+func resolveBinding(b *triggersv1.EventListenerBinding, bindings map[string]*triggersv1.TriggerBinding, event interface{}) *Binding {
+	if b.Ref == "" {
+		value := ""
+		if b.Value != nil {
+			value = *b.Value
+		}
+		return &Binding{Params: []*Param{resolveBindingParam(event, b.Name, value)}}
+	}
+	resolved := &Binding{Name: b.Ref}
+	if b.Kind == triggersv1.ClusterTriggerBindingKind {
+		resolved.Error = "ClusterTriggerBindings are not supported"
+		return resolved
+	}
+	tb, ok := bindings[b.Ref]
+	if !ok {
+		resolved.Error = fmt.Sprintf("failed to find the TriggerBinding %s", b.Ref)
+		return resolved
+	}
+	for _, p := range tb.Spec.Params {
+		resolved.Params = append(resolved.Params, resolveBindingParam(event, p.Name, p.Value))
+	}
+	return resolved
+}
+
+func resolveBindingParam(event interface{}, name, value string) *Param {
+	resolved, err := resolveParam(event, value)
+	if err != nil {
+		return &Param{Name: name, Value: value, Error: err.Error()}
+	}
+	return &Param{Name: name, Value: resolved}
+}
+
+// interceptor is the configuration of a trigger interceptor, from either the
+// params of a referenced interceptor or the deprecated inline fields.
+type interceptor struct {
+	name        string
+	filter      string
+	overlays    []triggersv1.CELOverlay
+	eventTypes  []string
+	secret      bool
+	unsupported bool
+}
+
+func parseInterceptor(i *triggersv1.EventInterceptor) (*interceptor, error) {
+	switch {
+	case i.DeprecatedCEL != nil:
+		return &interceptor{name: "cel", filter: i.DeprecatedCEL.Filter, overlays: i.DeprecatedCEL.Overlays}, nil
+	case i.DeprecatedGitHub != nil:
+		return &interceptor{name: "github", eventTypes: i.DeprecatedGitHub.EventTypes, secret: i.DeprecatedGitHub.SecretRef != nil}, nil
+	case i.DeprecatedGitLab != nil:
+		return &interceptor{name: "gitlab", eventTypes: i.DeprecatedGitLab.EventTypes, secret: i.DeprecatedGitLab.SecretRef != nil}, nil
+	case i.DeprecatedBitbucket != nil:
+		return &interceptor{name: "bitbucket", eventTypes: i.DeprecatedBitbucket.EventTypes, secret: i.DeprecatedBitbucket.SecretRef != nil}, nil
+	case i.Webhook != nil:
+		return &interceptor{name: "webhook", unsupported: true}, nil
+	}
+	ic := &interceptor{name: i.Ref.Name}
+	if _, ok := eventTypeHeaders[ic.name]; !ok && ic.name != "cel" {
+		ic.unsupported = true
+		return ic, nil
+	}
+	for _, p := range i.Params {
+		var err error
+		switch p.Name {
+		case "filter":
+			err = json.Unmarshal(p.Value.Raw, &ic.filter)
+		case "overlays":
+			err = json.Unmarshal(p.Value.Raw, &ic.overlays)
+		case "eventTypes":
+			err = json.Unmarshal(p.Value.Raw, &ic.eventTypes)
+		case "secretRef":
+			ic.secret = true
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the %s param of interceptor %s: %w", p.Name, ic.name, err)
+		}
+	}
+	return ic, nil
+}
+
+// intercept checks the event, and adds the overlays to the extensions, the
+// error is the reason that the event is rejected.
+func (ic *interceptor) intercept(env *cel.Env, event *Event, extensions map[string]interface{}) error {
+	if len(ic.eventTypes) > 0 {
+		header := eventTypeHeaders[ic.name]
+		eventType := event.Header.Get(header)
+		if !contains(ic.eventTypes, eventType) {
+			return fmt.Errorf("%s: event type %q in header %s is not allowed", ic.name, eventType, header)
+		}
+	}
+	if ic.name != "cel" {
+		return nil
+	}
+	data := map[string]interface{}{
+		"body":       event.body,
+		"header":     event.Header,
+		"requestURL": event.URL,
+		"extensions": extensions,
+	}
+	if ic.filter != "" {
+		out, err := evaluate(env, ic.filter, data)
+		if err != nil {
+			return fmt.Errorf("error evaluating cel expression: %w", err)
+		}
+		if out != types.True {
+			return fmt.Errorf("expression %s did not return true", ic.filter)
+		}
+	}
+	// The overlays are evaluated against the extensions from the previous
+	// interceptors, and added when they have all been evaluated.
+	overlays := map[string]interface{}{}
+	for _, o := range ic.overlays {
+		val, err := evaluate(env, o.Expression, data)
+		if err != nil {
+			return fmt.Errorf("error evaluating cel expression: %w", err)
+		}
+		v, err := toJSONValue(val)
+		if err != nil {
+			return err
+		}
+		setExtension(overlays, o.Key, v)
+	}
+	for k, v := range overlays {
+		extensions[k] = v
+	}
+	return nil
+}
+
+func contains(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,365 @@
+package triggersim
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jenkins-x/go-scm/scm/factory"
+	triggersv1 "github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/eventlisteners"
+	"github.com/redhat-developer/kam/pkg/pipelines/scm"
+)
+
+func TestSimulateGeneratedTriggers(t *testing.T) {
+	repo, err := scm.NewRepository("https://github.com/org/test.git")
+	assertNoError(t, err)
+	pushTrigger, err := repo.CreateFilteredPushTrigger("app-ci-build-from-push", "webhook-secret", "cicd", "app-ci-template", []string{"github-push-binding"}, scm.PushFilter{Branches: []string{"main", "release-*"}, ContextDir: "services/taxi"})
+	assertNoError(t, err)
+	prTrigger, err := repo.CreatePRTrigger("app-ci-build-from-pr", "webhook-secret", "cicd", "app-ci-template", []string{"github-pr-binding"})
+	assertNoError(t, err)
+	el := eventlisteners.CreateELFromTriggers("cicd", "pipeline", []triggersv1.EventListenerTrigger{pushTrigger, prTrigger})
+	pushBinding, _ := repo.CreatePushBinding("cicd")
+	bindings := map[string]*triggersv1.TriggerBinding{"github-push-binding": &pushBinding}
+
+	pushTests := []struct {
+		name   string
+		event  scm.PushEvent
+		fired  bool
+		reason string
+	}{
+		{"push to matching branch and directory", scm.PushEvent{Ref: "refs/heads/release-1.0", SHA: "6dcb09b5b57875f334f61aebed695e2e4193db5e", Modified: []string{"services/taxi/main.go"}}, true, ""},
+		{"push to other branch", scm.PushEvent{Ref: "refs/heads/feature", SHA: "6dcb09b5b57875f334f61aebed695e2e4193db5e", Modified: []string{"services/taxi/main.go"}}, false, "expression"},
+		{"push to other directory", scm.PushEvent{Ref: "refs/heads/main", SHA: "6dcb09b5b57875f334f61aebed695e2e4193db5e", Modified: []string{"services/bus/main.go"}}, false, "expression"},
+	}
+
+	for _, tt := range pushTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			header, body, err := repo.CreatePushEvent(tt.event, "secret")
+			assertNoError(rt, err)
+			event, err := NewEvent(body, header, "http://el-cicd-event-listener.cicd.svc:8080")
+			assertNoError(rt, err)
+
+			results, err := Simulate(el, bindings, event, NewWebhookSecretLister("secret"))
+			assertNoError(rt, err)
+
+			if len(results) != 2 {
+				rt.Fatalf("got %d results, want 2", len(results))
+			}
+			push := results[0]
+			if push.Fired != tt.fired {
+				rt.Fatalf("push trigger fired = %v, want %v: %s", push.Fired, tt.fired, push.Reason)
+			}
+			if tt.reason != "" && !matchesPrefix(push.Reason, tt.reason) {
+				rt.Fatalf("reason got %q, want prefix %q", push.Reason, tt.reason)
+			}
+			if results[1].Fired {
+				rt.Fatal("pull request trigger fired for a push event")
+			}
+			if diff := cmp.Diff([]string{"github: the webhook secret is not verified"}, push.Unverified); diff != "" {
+				rt.Fatalf("unverified checks:\n%s", diff)
+			}
+		})
+	}
+}
+
+// The generated push events don't list removed files, so the commit of the
+// event is changed to remove a file in the directory.
+func TestSimulatePushWithRemovedFile(t *testing.T) {
+	repo, err := scm.NewRepository("https://github.com/org/test.git")
+	assertNoError(t, err)
+	pushTrigger, err := repo.CreateFilteredPushTrigger("app-ci-build-from-push", "webhook-secret", "cicd", "app-ci-template", []string{"github-push-binding"}, scm.PushFilter{ContextDir: "services/taxi"})
+	assertNoError(t, err)
+	el := eventlisteners.CreateELFromTriggers("cicd", "pipeline", []triggersv1.EventListenerTrigger{pushTrigger})
+	header, body, err := repo.CreatePushEvent(scm.PushEvent{Ref: "refs/heads/main", SHA: "6dcb09b5b57875f334f61aebed695e2e4193db5e"}, "secret")
+	assertNoError(t, err)
+	payload := map[string]interface{}{}
+	assertNoError(t, json.Unmarshal(body, &payload))
+	payload["commits"].([]interface{})[0].(map[string]interface{})["removed"] = []string{"services/taxi/old.go"}
+	body, err = json.Marshal(payload)
+	assertNoError(t, err)
+	event, err := NewEvent(body, header, "http://el-cicd-event-listener.cicd.svc:8080")
+	assertNoError(t, err)
+
+	results, err := Simulate(el, nil, event, NewWebhookSecretLister("secret"))
+	assertNoError(t, err)
+
+	if !results[0].Fired {
+		t.Fatalf("push trigger didn't fire for a removed file: %s", results[0].Reason)
+	}
+}
+
+// Bitbucket sends the secret in the query of the webhook URL.
+func TestSimulateBitbucketTriggers(t *testing.T) {
+	repo, err := scm.NewRepository("https://bitbucket.org/org/test.git")
+	assertNoError(t, err)
+	pushTrigger, err := repo.CreateFilteredPushTrigger("app-ci-build-from-push", "webhook-secret", "cicd", "app-ci-template", []string{"bitbucket-push-binding"}, scm.PushFilter{Branches: []string{"main"}})
+	assertNoError(t, err)
+	el := eventlisteners.CreateELFromTriggers("cicd", "pipeline", []triggersv1.EventListenerTrigger{pushTrigger})
+	header, body, err := repo.CreatePushEvent(scm.PushEvent{Ref: "refs/heads/main", SHA: "6dcb09b5b57875f334f61aebed695e2e4193db5e"}, "secret")
+	assertNoError(t, err)
+
+	urlTests := []struct {
+		name          string
+		url           string
+		webhookSecret string
+		fired         bool
+		reason        string
+	}{
+		{"secret in the URL", "http://el-cicd-event-listener.cicd.svc:8080?secret=secret", "secret", true, ""},
+		{"no secret in the URL", "http://el-cicd-event-listener.cicd.svc:8080", "secret", false, "expression"},
+		{"other secret in the URL", "http://el-cicd-event-listener.cicd.svc:8080?secret=other", "secret", false, "expression"},
+		{"no webhook secret", "http://el-cicd-event-listener.cicd.svc:8080?secret=secret", "", false, "error evaluating cel expression"},
+	}
+
+	for _, tt := range urlTests {
+		t.Run(tt.name, func(rt *testing.T) {
+			event, err := NewEvent(body, header, tt.url)
+			assertNoError(rt, err)
+
+			results, err := Simulate(el, nil, event, NewWebhookSecretLister(tt.webhookSecret))
+			assertNoError(rt, err)
+
+			r := results[0]
+			if r.Fired != tt.fired {
+				rt.Fatalf("push trigger fired = %v, want %v: %s", r.Fired, tt.fired, r.Reason)
+			}
+			if !matchesPrefix(r.Reason, tt.reason) {
+				rt.Fatalf("reason got %q, want prefix %q", r.Reason, tt.reason)
+			}
+			if len(r.Unverified) > 0 {
+				rt.Fatalf("unverified checks: %v", r.Unverified)
+			}
+			if tt.fired {
+				if diff := cmp.Diff(map[string]interface{}{"ref": "main"}, r.Extensions); diff != "" {
+					rt.Fatalf("extensions:\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+// Bitbucket Server repositories have a list of clone URLs, the HTTP clone URL
+// is added to the extensions by the trigger.
+func TestSimulateBitbucketServerTriggers(t *testing.T) {
+	defer func(id factory.HostDriverIdentifier) {
+		factory.DefaultIdentifier = id
+	}(factory.DefaultIdentifier)
+	factory.DefaultIdentifier = factory.NewDriverIdentifier(factory.Mapping("bitbucket.example.com", "stash"))
+
+	repo, err := scm.NewRepository("https://bitbucket.example.com/scm/proj/test.git")
+	assertNoError(t, err)
+	pushTrigger, err := repo.CreateFilteredPushTrigger("app-ci-build-from-push", "webhook-secret", "cicd", "app-ci-template", []string{"stash-push-binding"}, scm.PushFilter{Branches: []string{"main"}})
+	assertNoError(t, err)
+	el := eventlisteners.CreateELFromTriggers("cicd", "pipeline", []triggersv1.EventListenerTrigger{pushTrigger})
+	header, body, err := repo.CreatePushEvent(scm.PushEvent{Ref: "refs/heads/main", SHA: "6dcb09b5b57875f334f61aebed695e2e4193db5e"}, "secret")
+	assertNoError(t, err)
+	event, err := NewEvent(body, header, "")
+	assertNoError(t, err)
+
+	results, err := Simulate(el, nil, event, NewWebhookSecretLister("secret"))
+	assertNoError(t, err)
+
+	r := results[0]
+	if !r.Fired {
+		t.Fatalf("push trigger didn't fire: %s", r.Reason)
+	}
+	want := map[string]interface{}{"ref": "main", "clone_url": "https://bitbucket.example.com/scm/proj/test.git"}
+	if diff := cmp.Diff(want, r.Extensions); diff != "" {
+		t.Fatalf("extensions:\n%s", diff)
+	}
+}
+
+func TestSimulateGitLabSubgroupTriggers(t *testing.T) {
+	repo, err := scm.NewRepository("https://gitlab.com/group/subgroup/team/test.git")
+	assertNoError(t, err)
+	pushTrigger, err := repo.CreateFilteredPushTrigger("app-ci-build-from-push", "webhook-secret", "cicd", "app-ci-template", []string{"gitlab-push-binding"}, scm.PushFilter{Branches: []string{"main"}})
+	assertNoError(t, err)
+	el := eventlisteners.CreateELFromTriggers("cicd", "pipeline", []triggersv1.EventListenerTrigger{pushTrigger})
+	header, body, err := repo.CreatePushEvent(scm.PushEvent{Ref: "refs/heads/main", SHA: "6dcb09b5b57875f334f61aebed695e2e4193db5e"}, "secret")
+	assertNoError(t, err)
+	event, err := NewEvent(body, header, "")
+	assertNoError(t, err)
+
+	results, err := Simulate(el, nil, event, NewWebhookSecretLister("secret"))
+	assertNoError(t, err)
+
+	if r := results[0]; !r.Fired {
+		t.Fatalf("push trigger didn't fire: %s", r.Reason)
+	}
+}
+
+func TestSimulateResolvesBindings(t *testing.T) {
+	repo, err := scm.NewRepository("https://github.com/org/test.git")
+	assertNoError(t, err)
+	pushTrigger, err := repo.CreatePushTrigger("app-ci-build-from-push", "webhook-secret", "cicd", "app-ci-template", []string{"github-push-binding", "taxi-binding"})
+	assertNoError(t, err)
+	pushTrigger.Bindings = append(pushTrigger.Bindings, &triggersv1.EventListenerBinding{Name: "eventType", Value: stringPtr("$(header.x-github-event)")})
+	el := eventlisteners.CreateELFromTriggers("cicd", "pipeline", []triggersv1.EventListenerTrigger{pushTrigger})
+	pushBinding, _ := repo.CreatePushBinding("cicd")
+	bindings := map[string]*triggersv1.TriggerBinding{"github-push-binding": &pushBinding}
+	header, body, err := repo.CreatePushEvent(scm.PushEvent{Ref: "refs/tags/v1.0.0", SHA: "6dcb09b5b57875f334f61aebed695e2e4193db5e", Message: "Release", Author: "Ann", Date: "2021-04-01T10:00:00Z"}, "secret")
+	assertNoError(t, err)
+	event, err := NewEvent(body, header, "")
+	assertNoError(t, err)
+
+	results, err := Simulate(el, bindings, event, NewWebhookSecretLister("secret"))
+	assertNoError(t, err)
+
+	want := &Result{
+		Trigger:    "app-ci-build-from-push",
+		Fired:      true,
+		Unverified: []string{"github: the webhook secret is not verified"},
+		Extensions: map[string]interface{}{"ref": "v1.0.0"},
+		Bindings: []*Binding{
+			{
+				Name: "github-push-binding",
+				Params: []*Param{
+					{Name: "gitrepositoryurl", Value: "https://github.com/org/test.git"},
+					{Name: "fullname", Value: "org/test"},
+					{Name: "io.openshift.build.commit.ref", Value: "v1.0.0"},
+					{Name: "io.openshift.build.commit.id", Value: "6dcb09b5b57875f334f61aebed695e2e4193db5e"},
+					{Name: "io.openshift.build.commit.date", Value: "2021-04-01T10:00:00Z"},
+					{Name: "io.openshift.build.commit.message", Value: "Release"},
+					{Name: "io.openshift.build.commit.author", Value: "Ann"},
+				},
+			},
+			{Name: "taxi-binding", Error: "failed to find the TriggerBinding taxi-binding"},
+			{Params: []*Param{{Name: "eventType", Value: "push"}}},
+		},
+	}
+	if diff := cmp.Diff([]*Result{want}, results); diff != "" {
+		t.Fatalf("Simulate() failed:\n%s", diff)
+	}
+}
+
+func TestSimulateInterceptors(t *testing.T) {
+	celInterceptor := func(filter string) *triggersv1.EventInterceptor {
+		return &triggersv1.EventInterceptor{
+			Ref:    triggersv1.InterceptorRef{Name: "cel"},
+			Params: []triggersv1.InterceptorParams{{Name: "filter", Value: v1.JSON{Raw: []byte(`"` + filter + `"`)}}},
+		}
+	}
+	tests := []struct {
+		name         string
+		interceptors []*triggersv1.EventInterceptor
+		fired        bool
+		reason       string
+		unverified   []string
+	}{
+		{
+			"matching event type",
+			[]*triggersv1.EventInterceptor{{Ref: triggersv1.InterceptorRef{Name: "github"}, Params: []triggersv1.InterceptorParams{{Name: "eventTypes", Value: v1.JSON{Raw: []byte(`["push"]`)}}}}},
+			true, "", nil,
+		},
+		{
+			"other event type",
+			[]*triggersv1.EventInterceptor{{Ref: triggersv1.InterceptorRef{Name: "github"}, Params: []triggersv1.InterceptorParams{{Name: "eventTypes", Value: v1.JSON{Raw: []byte(`["pull_request"]`)}}}}},
+			false, `github: event type "push" in header X-GitHub-Event is not allowed`, nil,
+		},
+		{
+			"deprecated cel interceptor",
+			[]*triggersv1.EventInterceptor{{DeprecatedCEL: &triggersv1.CELInterceptor{Filter: "body.ref == 'refs/heads/dev'"}}},
+			false, "expression body.ref == 'refs/heads/dev' did not return true", nil,
+		},
+		{
+			"triggers functions",
+			[]*triggersv1.EventInterceptor{celInterceptor("header.canonical('x-github-event') == 'push' && body.ref.split('/')[2].truncate(2) == 'ma'")},
+			true, "", nil,
+		},
+		{
+			"missing field",
+			[]*triggersv1.EventInterceptor{celInterceptor("body.pull_request.number > 0")},
+			false, "error evaluating cel expression", nil,
+		},
+		{
+			"custom interceptor",
+			[]*triggersv1.EventInterceptor{{Ref: triggersv1.InterceptorRef{Name: "my-interceptor"}}},
+			true, "", []string{"interceptor my-interceptor is not evaluated"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(rt *testing.T) {
+			el := eventlisteners.CreateELFromTriggers("cicd", "pipeline", []triggersv1.EventListenerTrigger{{Name: "test", Interceptors: tt.interceptors}})
+			event, err := NewEvent([]byte(`{"ref":"refs/heads/main"}`), http.Header{"X-Github-Event": []string{"push"}}, "")
+			assertNoError(rt, err)
+
+			results, err := Simulate(el, nil, event, NewWebhookSecretLister("secret"))
+			assertNoError(rt, err)
+
+			r := results[0]
+			if r.Fired != tt.fired {
+				rt.Fatalf("fired = %v, want %v: %s", r.Fired, tt.fired, r.Reason)
+			}
+			if !matchesPrefix(r.Reason, tt.reason) {
+				rt.Fatalf("reason got %q, want prefix %q", r.Reason, tt.reason)
+			}
+			if diff := cmp.Diff(tt.unverified, r.Unverified); diff != "" {
+				rt.Fatalf("unverified checks:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSimulateOverlays(t *testing.T) {
+	el := eventlisteners.CreateELFromTriggers("cicd", "pipeline", []triggersv1.EventListenerTrigger{
+		{
+			Name: "test",
+			Interceptors: []*triggersv1.EventInterceptor{
+				{DeprecatedCEL: &triggersv1.CELInterceptor{Overlays: []triggersv1.CELOverlay{
+					{Key: "branch", Expression: "body.ref.substring(11)"},
+					{Key: "short.sha", Expression: "body.after.truncate(7)"},
+				}}},
+				{DeprecatedCEL: &triggersv1.CELInterceptor{Overlays: []triggersv1.CELOverlay{
+					{Key: "image", Expression: "'quay.io/org/app:' + extensions.branch + '-' + extensions.short.sha"},
+				}}},
+			},
+			Bindings: []*triggersv1.EventListenerBinding{{Name: "image", Value: stringPtr("$(extensions.image)")}},
+		},
+	})
+	event, err := NewEvent([]byte(`{"ref":"refs/heads/main","after":"6dcb09b5b57875f334f61aebed695e2e4193db5e"}`), http.Header{}, "")
+	assertNoError(t, err)
+
+	results, err := Simulate(el, nil, event, NewWebhookSecretLister("secret"))
+	assertNoError(t, err)
+
+	want := &Result{
+		Trigger: "test",
+		Fired:   true,
+		Extensions: map[string]interface{}{
+			"branch": "main",
+			"short":  map[string]interface{}{"sha": "6dcb09b"},
+			"image":  "quay.io/org/app:main-6dcb09b",
+		},
+		Bindings: []*Binding{{Params: []*Param{{Name: "image", Value: "quay.io/org/app:main-6dcb09b"}}}},
+	}
+	if diff := cmp.Diff([]*Result{want}, results); diff != "" {
+		t.Fatalf("Simulate() failed:\n%s", diff)
+	}
+}
+
+func TestNewEventWithInvalidBody(t *testing.T) {
+	_, err := NewEvent([]byte(`{"ref":`), http.Header{}, "")
+	if err == nil {
+		t.Fatal("expected an error for an invalid body")
+	}
+}
+
+func matchesPrefix(s, prefix string) bool {
+	return len(s) >= len(prefix) && s[:len(prefix)] == prefix
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func assertNoError(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,14 +1,18 @@
 package pipelines
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"net/url"
 	"os/exec"
 	"path/filepath"
 	"strings"
 
 	"github.com/jenkins-x/go-scm/scm"
+	"github.com/redhat-developer/kam/pkg/pipelines/giturl"
 	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
 	"github.com/spf13/afero"
 )
@@ -28,29 +32,46 @@ func BootstrapRepository(o *BootstrapOptions, f clientFactory, e executor, appFs
 		return nil
 	}
 
-	u, err := url.Parse(o.GitOpsRepoURL)
+	repo, err := giturl.Parse(o.GitOpsRepoURL)
+	if err != nil {
+		return fmt.Errorf("failed to parse GitOps repo URL %q: %w", o.GitOpsRepoURL, err)
+	}
+	// The namespace is everything before the repository name, GitLab projects
+	// can be in nested groups, e.g. group/subgroup/team/repo.
+	org, repoName := splitNamespace(repo.RepoPath())
+	// The API of the Git host is accessed with HTTPS for SSH URLs.
+	u, err := url.Parse(repo.HTTPURL())
 	if err != nil {
 		return fmt.Errorf("failed to parse GitOps repo URL %q: %w", o.GitOpsRepoURL, err)
 	}
-	parts := strings.Split(u.Path, "/")
-	org := parts[1]
-	repoName := strings.TrimSuffix(strings.Join(parts[2:], "/"), ".git")
 	u.User = url.UserPassword("", o.GitHostAccessToken)
 
 	client, err := f(u.String())
 	if err != nil {
 		return fmt.Errorf("failed to create a client to access %q: %w", o.GitOpsRepoURL, err)
 	}
+	// Bitbucket Server repositories are created in a project, and the clone
+	// URLs are of the form https://bitbucket.example.com/scm/<project>/<repo>.git
+	if client.Driver == scm.DriverStash {
+		org = strings.TrimPrefix(org, "scm/")
+	}
 	ctx := context.Background()
 	// If we're creating the repository in a personal user's account, it's a
 	// different API call that's made, clearing the org triggers go-scm to use
 	// the "create repo in personal account" endpoint.
-	currentUser, _, err := client.Users.Find(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to get the user with their auth token: %w", err)
-	}
-	if currentUser.Login == org {
-		org = ""
+	//
+	// Bitbucket Cloud repositories are always created in a workspace, which
+	// includes personal workspaces, and workspace access tokens can't look up
+	// the user.
+	currentUser := &scm.User{}
+	if client.Driver != scm.DriverBitbucket {
+		currentUser, _, err = client.Users.Find(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get the user with their auth token: %w", err)
+		}
+		if currentUser.Login == org {
+			org = ""
+		}
 	}
 
 	ri := &scm.RepositoryInput{
@@ -59,7 +80,14 @@ func BootstrapRepository(o *BootstrapOptions, f clientFactory, e executor, appFs
 		Namespace:   org,
 		Name:        repoName,
 	}
-	created, _, err := client.Repositories.Create(context.Background(), ri)
+	var created *scm.Repository
+	// go-scm searches for GitLab namespaces by name, which doesn't find
+	// subgroups by their full path.
+	if client.Driver == scm.DriverGitlab && strings.Contains(org, "/") {
+		created, err = createGitLabProject(ctx, client, ri)
+	} else {
+		created, _, err = client.Repositories.Create(ctx, ri)
+	}
 	if err != nil {
 		repo := fmt.Sprintf("%s/%s", org, repoName)
 		if org == "" {
@@ -76,6 +104,77 @@ func BootstrapRepository(o *BootstrapOptions, f clientFactory, e executor, appFs
 	return err
 }
 
+// splitNamespace splits a repository path into the namespace and the name of
+// the repository, the namespace is empty if the path has a single element.
+func splitNamespace(path string) (string, string) {
+	i := strings.LastIndex(path, "/")
+	if i < 0 {
+		return "", path
+	}
+	return path[:i], path[i+1:]
+}
+
+// createGitLabProject creates a project in the GitLab group with the full path
+// in the namespace of the input.
+func createGitLabProject(ctx context.Context, client *scm.Client, in *scm.RepositoryInput) (*scm.Repository, error) {
+	namespace := struct {
+		ID int `json:"id"`
+	}{}
+	if err := gitlabRequest(ctx, client, "GET", "api/v4/namespaces/"+url.PathEscape(in.Namespace), nil, &namespace); err != nil {
+		return nil, fmt.Errorf("failed to find the namespace %q: %w", in.Namespace, err)
+	}
+	visibility := "public"
+	if in.Private {
+		visibility = "private"
+	}
+	body := map[string]interface{}{
+		"name":         in.Name,
+		"description":  in.Description,
+		"namespace_id": namespace.ID,
+		"visibility":   visibility,
+	}
+	project := struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+		HTTPURLToRepo     string `json:"http_url_to_repo"`
+		SSHURLToRepo      string `json:"ssh_url_to_repo"`
+		WebURL            string `json:"web_url"`
+	}{}
+	if err := gitlabRequest(ctx, client, "POST", "api/v4/projects", body, &project); err != nil {
+		return nil, err
+	}
+	namespacePath, name := splitNamespace(project.PathWithNamespace)
+	return &scm.Repository{
+		Namespace: namespacePath,
+		Name:      name,
+		FullName:  project.PathWithNamespace,
+		Private:   in.Private,
+		Clone:     project.HTTPURLToRepo,
+		CloneSSH:  project.SSHURLToRepo,
+		Link:      project.WebURL,
+	}, nil
+}
+
+func gitlabRequest(ctx context.Context, client *scm.Client, method, path string, in, out interface{}) error {
+	req := &scm.Request{Method: method, Path: path, Header: http.Header{}}
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Body = bytes.NewReader(b)
+	}
+	res, err := client.Do(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.Status > 299 {
+		return fmt.Errorf("%s %s returned status %d", method, path, res.Status)
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
+
 func pushRepository(o *BootstrapOptions, remote string, e executor, appFs afero.Fs) error {
 	if exists, _ := ioutils.IsExisting(appFs, filepath.Join(o.OutputPath, ".git")); exists {
 		if err := appFs.RemoveAll(filepath.Join(o.OutputPath, ".git")); err != nil {
@@ -103,8 +202,14 @@ func pushRepository(o *BootstrapOptions, remote string, e executor, appFs afero.
 	return nil
 }
 
+// repoURL returns the base URL of the host of the repository, the pipelines
+// clone SSH repositories with HTTPS.
 func repoURL(u string) (string, error) {
-	parsed, err := url.Parse(u)
+	repo, err := giturl.Parse(u)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %q: %w", u, err)
+	}
+	parsed, err := url.Parse(repo.HTTPURL())
 	if err != nil {
 		return "", fmt.Errorf("failed to parse %q: %w", u, err)
 	}
@@ -10,8 +10,10 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/h2non/gock"
 	"github.com/jenkins-x/go-scm/scm"
 	"github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/jenkins-x/go-scm/scm/factory"
 	"github.com/redhat-developer/kam/pkg/pipelines/ioutils"
 	"github.com/redhat-developer/kam/test"
 )
@@ -53,6 +55,105 @@ func TestBootstrapRepository_with_org(t *testing.T) {
 	assertRepositoryCreated(t, fakeData, "testing", "test-repo")
 }
 
+func TestBootstrapRepository_with_bitbucket_workspace(t *testing.T) {
+	token := "this-is-a-test-token"
+	f, fakeData := newMockClientFactory(t, token)
+	fakeData.CurrentUser = scm.User{Login: "testing"}
+	bitbucketFactory := func(repoURL string) (*scm.Client, error) {
+		client, err := f(repoURL)
+		if err != nil {
+			return nil, err
+		}
+		client.Driver = scm.DriverBitbucket
+		return client, nil
+	}
+
+	err := BootstrapRepository(
+		&BootstrapOptions{
+			GitOpsRepoURL:      "https://bitbucket.org/testing/test-repo.git",
+			GitHostAccessToken: token,
+		},
+		bitbucketFactory,
+		newMockExecutor(),
+		ioutils.NewMemoryFilesystem(),
+	)
+	assertNoError(t, err)
+	assertRepositoryCreated(t, fakeData, "testing", "test-repo")
+}
+
+func TestBootstrapRepository_with_bitbucket_server_project(t *testing.T) {
+	token := "this-is-a-test-token"
+	f, fakeData := newMockClientFactory(t, token)
+	fakeData.CurrentUser = scm.User{Login: "testing"}
+	stashFactory := func(repoURL string) (*scm.Client, error) {
+		client, err := f(repoURL)
+		if err != nil {
+			return nil, err
+		}
+		client.Driver = scm.DriverStash
+		return client, nil
+	}
+
+	err := BootstrapRepository(
+		&BootstrapOptions{
+			GitOpsRepoURL:      "https://bitbucket.example.com/scm/proj/test-repo.git",
+			GitHostAccessToken: token,
+		},
+		stashFactory,
+		newMockExecutor(),
+		ioutils.NewMemoryFilesystem(),
+	)
+	assertNoError(t, err)
+	assertRepositoryCreated(t, fakeData, "proj", "test-repo")
+}
+
+// GitLab projects in subgroups are created in the namespace with the full
+// path of the subgroup.
+func TestBootstrapRepository_with_gitlab_subgroup(t *testing.T) {
+	defer gock.Off()
+	token := "this-is-a-test-token"
+	gock.New("https://gitlab.example.com").
+		Get("/api/v4/user").
+		Reply(200).
+		JSON(map[string]interface{}{"id": 1, "username": "testing"})
+	gock.New("https://gitlab.example.com").
+		Get("/api/v4/namespaces/group/subgroup/team").
+		Reply(200).
+		JSON(map[string]interface{}{"id": 42, "full_path": "group/subgroup/team"})
+	gock.New("https://gitlab.example.com").
+		Post("/api/v4/projects").
+		MatchHeader("Private-Token", token).
+		JSON(map[string]interface{}{"name": "test-repo", "description": defaultRepoDescription, "namespace_id": 42, "visibility": "private"}).
+		Reply(201).
+		JSON(map[string]interface{}{
+			"path_with_namespace": "group/subgroup/team/test-repo",
+			"ssh_url_to_repo":     "git@gitlab.example.com:group/subgroup/team/test-repo.git",
+		})
+	gitlabFactory := func(repoURL string) (*scm.Client, error) {
+		return factory.NewClient("gitlab", "https://gitlab.example.com", token)
+	}
+	e := newMockExecutor()
+
+	err := BootstrapRepository(
+		&BootstrapOptions{
+			GitOpsRepoURL:      "https://gitlab.example.com/group/subgroup/team/test-repo.git",
+			GitHostAccessToken: token,
+		},
+		gitlabFactory,
+		e,
+		ioutils.NewMemoryFilesystem(),
+	)
+	assertNoError(t, err)
+
+	if !gock.IsDone() {
+		t.Fatalf("pending requests: %v", gock.Pending())
+	}
+	want := execution{Command: "git", Args: []string{"remote", "add", "origin", "git@gitlab.example.com:group/subgroup/team/test-repo.git"}}
+	if diff := cmp.Diff(want, e.executed[4]); diff != "" {
+		t.Fatalf("BootstrapRepository failed to add the remote:\n%s", diff)
+	}
+}
+
 func TestBootstrapRepository_with_no_access_token(t *testing.T) {
 	token := "this-is-a-test-token"
 	factory, fakeData := newMockClientFactory(t, token)
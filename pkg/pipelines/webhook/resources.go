@@ -2,18 +2,27 @@ package webhook
 
 import (
 	"context"
+	"time"
 
 	routeclientset "github.com/openshift/client-go/route/clientset/versioned/typed/route/v1"
 	"github.com/pkg/errors"
 	"github.com/redhat-developer/kam/pkg/pipelines/clientconfig"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 )
 
+// eventIDLabel is added by Tekton Triggers to the resources that are created
+// for an event.
+const eventIDLabel = "triggers.tekton.dev/triggers-eventid"
+
 // resources represents cluster resources that are needed by webhook management
 type resources struct {
-	routeClient routeclientset.RouteV1Interface
-	kubeClient  kubernetes.Interface
+	routeClient   routeclientset.RouteV1Interface
+	kubeClient    kubernetes.Interface
+	dynamicClient dynamic.Interface
 }
 
 // NewResources create new webhook resources
@@ -32,8 +41,13 @@ func newResources() (*resources, error) {
 		return nil, err
 	}
 
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
 	return &resources{routeClient: routeClient,
-		kubeClient: kubeClient}, nil
+		kubeClient: kubeClient, dynamicClient: dynamicClient}, nil
 }
 
 func (r *resources) getWebhookSecret(ns, secetName, key string) (string, error) {
@@ -55,3 +69,26 @@ func (r *resources) getListenerAddress(ns, routeName string) (bool, string, erro
 
 	return route.Spec.TLS != nil, route.Spec.Host, nil
 }
+
+// waitForPipelineRun waits for the PipelineRun created for an EventListener
+// event, and returns its name.
+func (r *resources) waitForPipelineRun(ns string, gvr schema.GroupVersionResource, eventID string, interval, timeout time.Duration) (string, error) {
+	var name string
+	err := wait.PollImmediate(interval, timeout, func() (bool, error) {
+		runs, err := r.dynamicClient.Resource(gvr).Namespace(ns).List(context.Background(), metav1.ListOptions{
+			LabelSelector: eventIDLabel + "=" + eventID,
+		})
+		if err != nil {
+			return false, err
+		}
+		if len(runs.Items) == 0 {
+			return false, nil
+		}
+		name = runs.Items[0].GetName()
+		return true, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return "", errors.Errorf("no PipelineRun was created for event %s, check the logs of the EventListener", eventID)
+	}
+	return name, err
+}
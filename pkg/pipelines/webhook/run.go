@@ -0,0 +1,154 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/scm"
+	"github.com/redhat-developer/kam/pkg/pipelines/tektonapi"
+)
+
+var (
+	// The interval and timeout for waiting for the PipelineRun created by the
+	// EventListener.
+	pipelineRunInterval = time.Second
+	pipelineRunTimeout  = 30 * time.Second
+
+	httpClient = &http.Client{Timeout: 30 * time.Second}
+)
+
+// PipelineRun is the PipelineRun that was started for a push event.
+type PipelineRun struct {
+	Name    string `json:"name"`
+	EventID string `json:"eventID"`
+	Ref     string `json:"ref"`
+	SHA     string `json:"sha"`
+}
+
+// Run starts the integration pipeline of a service without a push to its
+// source repository.
+//
+// A push event for the ref is sent to the EventListener, signed with the
+// webhook secret of the service, and the PipelineRun that is created for the
+// event is returned. If ref is empty, the default branch of the repository is
+// used, tags must be the full ref, e.g. refs/tags/v1.0.0.
+func Run(accessToken, pipelinesFile string, serviceName *QualifiedServiceName, ref string) (*PipelineRun, error) {
+	webhook, err := newWebhookInfo(accessToken, pipelinesFile, serviceName, false)
+	if err != nil {
+		return nil, err
+	}
+	return webhook.run(ref)
+}
+
+func (w *webhookInfo) run(ref string) (*PipelineRun, error) {
+	secret, err := getWebhookSecret(w.clusterResource, w.cicdNamepace, w.isCICD, w.serviceName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook secret: %v", err)
+	}
+	event, err := w.pushEvent(ref)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := scm.NewRepository(w.gitRepoURL)
+	if err != nil {
+		return nil, err
+	}
+	headers, body, err := repo.CreatePushEvent(*event, secret)
+	if err != nil {
+		return nil, err
+	}
+	eventURL, err := repo.WebhookURL(w.listenerURL, secret)
+	if err != nil {
+		return nil, err
+	}
+	eventID, err := sendEvent(eventURL, headers, body)
+	if err != nil {
+		return nil, err
+	}
+	gvr := tektonapi.PipelineRunsResource(w.manifest.GetPipelinesConfig().GetTektonAPIVersion())
+	name, err := w.clusterResource.waitForPipelineRun(w.cicdNamepace, gvr, eventID, pipelineRunInterval, pipelineRunTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return &PipelineRun{Name: name, EventID: eventID, Ref: event.Ref, SHA: event.SHA}, nil
+}
+
+// pushEvent returns the push event for the commit that ref refers to, if the
+// service is in a directory of the repository, the directory is reported as
+// modified, so that the event matches the trigger's changed files filter.
+func (w *webhookInfo) pushEvent(ref string) (*scm.PushEvent, error) {
+	if ref == "" {
+		branch, err := w.repository.DefaultBranch()
+		if err != nil {
+			return nil, err
+		}
+		ref = branch
+	}
+	if !strings.HasPrefix(ref, "refs/") {
+		ref = "refs/heads/" + ref
+	}
+	commit, err := w.repository.FindCommit(strings.TrimPrefix(strings.TrimPrefix(ref, "refs/heads/"), "refs/tags/"))
+	if err != nil {
+		return nil, err
+	}
+	event := &scm.PushEvent{
+		Ref:     ref,
+		SHA:     commit.Sha,
+		Message: commit.Message,
+		Author:  commit.Author.Name,
+		Date:    commit.Author.Date.Format(time.RFC3339),
+	}
+	if svc := getService(w.manifest, w.serviceName); svc != nil && svc.ContextDir != "" {
+		event.Modified = []string{path.Clean(strings.Trim(svc.ContextDir, "/")) + "/"}
+	}
+	return event, nil
+}
+
+// sendEvent posts the event to the EventListener, and returns the ID that the
+// EventListener assigned to it.
+//
+// The URL can have the webhook secret in its query, so the query is not
+// included in the errors.
+func sendEvent(eventURL string, headers http.Header, body []byte) (string, error) {
+	req, err := http.NewRequest(http.MethodPost, eventURL, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	listenerURL := *req.URL
+	listenerURL.RawQuery = ""
+	req.Header = headers
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return "", fmt.Errorf("failed to send the event to %s: %w", listenerURL.String(), err)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("the EventListener at %s rejected the event: %s: %s", listenerURL.String(), resp.Status, strings.TrimSpace(string(data)))
+	}
+	var response struct {
+		EventID string `json:"eventID"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return "", fmt.Errorf("failed to parse the response from %s: %w", listenerURL.String(), err)
+	}
+	if response.EventID == "" {
+		return "", fmt.Errorf("the response from %s has no event ID", listenerURL.String())
+	}
+	return response.EventID, nil
+}
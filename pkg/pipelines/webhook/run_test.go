@@ -0,0 +1,170 @@
+package webhook
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/h2non/gock"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+
+	"github.com/redhat-developer/kam/pkg/pipelines/config"
+	"github.com/redhat-developer/kam/pkg/pipelines/git"
+	"github.com/redhat-developer/kam/pkg/pipelines/scm"
+	"github.com/redhat-developer/kam/pkg/pipelines/tektonapi"
+	"github.com/redhat-developer/kam/test"
+)
+
+func TestSendEvent(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Method != http.MethodPost || r.Header.Get("X-GitHub-Event") != "push" || string(body) != `{"ref":"refs/heads/main"}` {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"eventListener":"cicd-event-listener","namespace":"cicd","eventID":"abc123"}`))
+	}))
+	defer ts.Close()
+
+	headers := http.Header{"X-Github-Event": []string{"push"}}
+	eventID, err := sendEvent(ts.URL, headers, []byte(`{"ref":"refs/heads/main"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eventID != "abc123" {
+		t.Fatalf("sendEvent() got %q, want %q", eventID, "abc123")
+	}
+}
+
+func TestSendEventRejected(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad event", http.StatusBadRequest)
+	}))
+	defer ts.Close()
+
+	_, err := sendEvent(ts.URL, http.Header{}, []byte(`{}`))
+	test.AssertErrorMatch(t, "rejected the event: 400 Bad Request: bad event", err)
+}
+
+func TestSendEventWithSecretInURL(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("secret") != "shh" {
+			t.Errorf("got query %q, want the secret", r.URL.RawQuery)
+		}
+		http.Error(w, "bad event", http.StatusBadRequest)
+	}))
+	defer ts.Close()
+
+	_, err := sendEvent(ts.URL+"?secret=shh", http.Header{}, []byte(`{}`))
+	test.AssertErrorMatch(t, "the EventListener at "+ts.URL+" rejected the event", err)
+	if strings.Contains(err.Error(), "shh") {
+		t.Fatalf("sendEvent() error contains the secret: %s", err)
+	}
+}
+
+func TestWaitForPipelineRun(t *testing.T) {
+	gvr := tektonapi.PipelineRunsResource(config.TektonV1Beta1)
+	r := &resources{dynamicClient: newFakeDynamicClient(gvr,
+		makePipelineRun("app-ci-pipeline-run-aaaaa", "def456"),
+		makePipelineRun("app-ci-pipeline-run-abcde", "abc123"),
+	)}
+
+	name, err := r.waitForPipelineRun(testNamespace, gvr, "abc123", time.Millisecond, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "app-ci-pipeline-run-abcde" {
+		t.Fatalf("waitForPipelineRun() got %q", name)
+	}
+}
+
+func TestWaitForPipelineRunTimeout(t *testing.T) {
+	gvr := tektonapi.PipelineRunsResource(config.TektonV1)
+	r := &resources{dynamicClient: newFakeDynamicClient(gvr)}
+
+	_, err := r.waitForPipelineRun(testNamespace, gvr, "abc123", time.Millisecond, 10*time.Millisecond)
+	test.AssertErrorMatch(t, "no PipelineRun was created for event abc123", err)
+}
+
+func TestPushEvent(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://api.github.com").
+		Get("/repos/foo/bar").
+		Reply(200).
+		Type("application/json").
+		BodyString(`{"full_name":"foo/bar","default_branch":"main"}`)
+	gock.New("https://api.github.com").
+		Get("/repos/foo/bar/commits/main").
+		Reply(200).
+		Type("application/json").
+		BodyString(`{"sha":"abc123","commit":{"message":"Fix it","author":{"name":"Test User","date":"2021-01-01T10:00:00Z"}}}`)
+
+	repository, err := git.NewRepository("https://github.com/foo/bar.git", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	serviceName := &QualifiedServiceName{EnvironmentName: "dev", ServiceName: "api"}
+	w := &webhookInfo{
+		manifest: &config.Manifest{
+			Environments: []*config.Environment{
+				{
+					Name: "dev",
+					Apps: []*config.Application{
+						{
+							Name: "app",
+							Services: []*config.Service{
+								{Name: "api", SourceURL: "https://github.com/foo/bar.git", ContextDir: "services/api/"},
+							},
+						},
+					},
+				},
+			},
+		},
+		repository:  repository,
+		serviceName: serviceName,
+	}
+
+	got, err := w.pushEvent("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &scm.PushEvent{
+		Ref:      "refs/heads/main",
+		SHA:      "abc123",
+		Message:  "Fix it",
+		Author:   "Test User",
+		Date:     "2021-01-01T10:00:00Z",
+		Modified: []string{"services/api/"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("pushEvent() failed:\n%s", diff)
+	}
+}
+
+func newFakeDynamicClient(gvr schema.GroupVersionResource, objs ...runtime.Object) *fake.FakeDynamicClient {
+	return fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "PipelineRunList"}, objs...)
+}
+
+func makePipelineRun(name, eventID string) *unstructured.Unstructured {
+	pr := &unstructured.Unstructured{}
+	pr.SetAPIVersion("tekton.dev/v1beta1")
+	pr.SetKind("PipelineRun")
+	pr.SetNamespace(testNamespace)
+	pr.SetName(name)
+	pr.SetLabels(map[string]string{eventIDLabel: eventID})
+	return pr
+}
@@ -13,6 +13,7 @@ import (
 )
 
 type webhookInfo struct {
+	manifest        *config.Manifest
 	clusterResource *resources
 	repository      *git.Repository
 	gitRepoURL      string
@@ -111,7 +112,7 @@ func newWebhookInfo(accessToken, pipelinesFile string, serviceName *QualifiedSer
 	if err != nil {
 		return nil, err
 	}
-	return &webhookInfo{clusterResources, repository, gitRepoURL, cicdNamepace, listenerURL, accessToken, serviceName, isCICD}, nil
+	return &webhookInfo{manifest, clusterResources, repository, gitRepoURL, cicdNamepace, listenerURL, accessToken, serviceName, isCICD}, nil
 }
 
 func (w *webhookInfo) exists() (bool, error) {
@@ -152,18 +153,26 @@ func getRepoURL(manifest *config.Manifest, isCICD bool, serviceName *QualifiedSe
 
 // Get service source repository URL.  Return "" if not found
 func getSourceRepoURL(manifest *config.Manifest, service *QualifiedServiceName) string {
+	if svc := getService(manifest, service); svc != nil {
+		return svc.SourceURL
+	}
+	return ""
+}
+
+// Get service from the manifest.  Return nil if not found
+func getService(manifest *config.Manifest, service *QualifiedServiceName) *config.Service {
 	for _, env := range manifest.Environments {
 		if env.Name == service.EnvironmentName {
 			for _, app := range env.Apps {
 				for _, svc := range app.Services {
 					if svc.Name == service.ServiceName {
-						return svc.SourceURL
+						return svc
 					}
 				}
 			}
 		}
 	}
-	return ""
+	return nil
 }
 
 func getListenerURL(r *resources, cicdNamespace string) (string, error) {